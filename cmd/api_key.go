@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/app"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	apiKeyCreateCommand = "create"
+	apiKeyListCommand   = "list"
+	apiKeyRevokeCommand = "revoke"
+)
+
+func apiKeyCommands() map[string]command {
+	return map[string]command{
+		apiKeyCreateCommand: createAPIKey,
+		apiKeyListCommand:   listAPIKeys,
+		apiKeyRevokeCommand: revokeAPIKey,
+	}
+}
+
+func runAPIKey(configFile string, args []string) error {
+	if len(args) == 0 {
+		return usageError{msg: fmt.Sprintf("missing api-key command, expected one of: %s", strings.Join(apiKeyCommandNames(), ", "))}
+	}
+
+	run, ok := apiKeyCommands()[args[0]]
+	if !ok {
+		return usageError{msg: fmt.Sprintf("invalid api-key command %s, expected one of: %s", args[0], strings.Join(apiKeyCommandNames(), ", "))}
+	}
+
+	return run(configFile, args[1:])
+}
+
+// createAPIKey PRINTS THE KEY ONLY ONCE, ONLY ITS HASH IS STORED
+func createAPIKey(configFile string, args []string) error {
+	fs := flag.NewFlagSet(apiKeyCreateCommand, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	var name string
+	fs.StringVar(&name, "name", "", "")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError{msg: err.Error()}
+	}
+
+	if fs.NArg() != 0 {
+		return usageError{msg: fmt.Sprintf("unexpected argument(s) %v", fs.Args())}
+	}
+
+	if name == "" {
+		return usageError{msg: "missing -name of the api key"}
+	}
+
+	key, ak, err := app.CreateAPIKey(configFile, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id:  %s\nkey: %s\n", ak.ID, key)
+	return nil
+}
+
+func listAPIKeys(configFile string, args []string) error {
+	if len(args) != 0 {
+		return usageError{msg: fmt.Sprintf("expected 0 argument(s), got %d", len(args))}
+	}
+
+	keys, err := app.ListAPIKeys(configFile)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tCREATED\tSTATE")
+
+	for _, k := range keys {
+		state := "active"
+		if k.Revoked() {
+			state = "revoked " + k.RevokedAt.Format(time.RFC3339)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.ID, k.Name, k.CreatedAt.Format(time.RFC3339), state)
+	}
+
+	return w.Flush()
+}
+
+func revokeAPIKey(configFile string, args []string) error {
+	if len(args) != 1 {
+		return usageError{msg: fmt.Sprintf("expected the api key id, got %d argument(s)", len(args))}
+	}
+
+	return app.RevokeAPIKey(configFile, args[0])
+}
+
+func apiKeyCommandNames() []string {
+	return []string{apiKeyCreateCommand, apiKeyListCommand, apiKeyRevokeCommand}
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"github.com/nsnikhil/stories/pkg/app"
-	"github.com/nsnikhil/stories/pkg/store"
-	"log"
+	"strings"
 )
 
 const (
@@ -11,22 +11,58 @@ const (
 	httpServeCommand = "http-serve"
 	migrateCommand   = "migrate"
 	rollbackCommand  = "rollback"
+
+	outboxRelayCommand = "outbox-relay"
+	seedCommand        = "seed"
+
+	apiKeyCommand = "api-key"
 )
 
-func commands() map[string]func(configFile string) {
-	return map[string]func(configFile string){
-		grpcServeCommand: app.StartGRPCServer,
-		httpServeCommand: app.StartHTTPServer,
-		migrateCommand:   store.RunMigrations,
-		rollbackCommand:  store.RollBackMigrations,
+// usageError EXITS WITH A DIFFERENT CODE THAN A COMMAND THAT FAILED SO SCRIPTS CAN TELL THEM APART
+type usageError struct {
+	msg string
+}
+
+func (ue usageError) Error() string {
+	return ue.msg
+}
+
+type command func(configFile string, args []string) error
+
+func commands() map[string]command {
+	return map[string]command{
+		grpcServeCommand: serve(app.StartGRPCServer),
+		httpServeCommand: serve(app.StartHTTPServer),
+		migrateCommand:   runMigrate,
+		rollbackCommand:  runRollback,
+
+		outboxRelayCommand: serve(app.StartOutboxRelay),
+		seedCommand:        runSeed,
+
+		apiKeyCommand: runAPIKey,
 	}
 }
 
-func execute(cmd string, configFile string) {
-	run, ok := commands()[cmd]
+func execute(args []string, configFile string) error {
+	if len(args) == 0 {
+		return usageError{msg: fmt.Sprintf("missing command, expected one of: %s", strings.Join(commandNames(), ", "))}
+	}
+
+	run, ok := commands()[args[0]]
 	if !ok {
-		log.Fatal("invalid command")
+		return usageError{msg: fmt.Sprintf("invalid command %s, expected one of: %s", args[0], strings.Join(commandNames(), ", "))}
+	}
+
+	return run(configFile, args[1:])
+}
+
+func serve(start func(configFile string)) command {
+	return func(configFile string, _ []string) error {
+		start(configFile)
+		return nil
 	}
+}
 
-	run(configFile)
+func commandNames() []string {
+	return []string{grpcServeCommand, httpServeCommand, migrateCommand, rollbackCommand, outboxRelayCommand, seedCommand, apiKeyCommand}
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"log"
+	"os"
 )
 
 const (
@@ -10,10 +13,24 @@ const (
 	configFileUsage   = ""
 )
 
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
 func main() {
 	var configFile string
 	flag.StringVar(&configFile, configFileKey, defaultConfigFile, configFileUsage)
 	flag.Parse()
 
-	execute(flag.Args()[0], configFile)
+	if err := execute(flag.Args(), configFile); err != nil {
+		log.Println(err)
+
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			os.Exit(exitUsage)
+		}
+
+		os.Exit(exitFailure)
+	}
 }
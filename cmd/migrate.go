@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/store"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	migrateUpCommand      = "up"
+	migrateDownCommand    = "down"
+	migrateStatusCommand  = "status"
+	migrateVersionCommand = "version"
+	migrateGotoCommand    = "goto"
+	migrateForceCommand   = "force"
+	migrateCreateCommand  = "create"
+)
+
+func migrateCommands() map[string]command {
+	return map[string]command{
+		migrateUpCommand:      withMigrator(0, func(m store.Migrator, _ []string) error { return m.Up() }),
+		migrateDownCommand:    withMigrator(0, func(m store.Migrator, _ []string) error { return m.Down() }),
+		migrateStatusCommand:  withMigrator(0, printStatus),
+		migrateVersionCommand: withMigrator(0, printVersion),
+		migrateGotoCommand:    withMigrator(1, gotoVersion),
+		migrateForceCommand:   withMigrator(1, forceVersion),
+		migrateCreateCommand:  createMigration,
+	}
+}
+
+// WITHOUT A SUB COMMAND migrate APPLIES EVERY PENDING MIGRATION
+func runMigrate(configFile string, args []string) error {
+	if len(args) == 0 {
+		return migrateCommands()[migrateUpCommand](configFile, args)
+	}
+
+	run, ok := migrateCommands()[args[0]]
+	if !ok {
+		return usageError{msg: fmt.Sprintf("invalid migrate command %s, expected one of: %s", args[0], strings.Join(migrateCommandNames(), ", "))}
+	}
+
+	return run(configFile, args[1:])
+}
+
+// rollback IS KEPT FOR THE EXISTING SCRIPTS, IT IS THE SAME AS migrate down
+func runRollback(configFile string, args []string) error {
+	return migrateCommands()[migrateDownCommand](configFile, args)
+}
+
+func withMigrator(argCount int, run func(m store.Migrator, args []string) error) command {
+	return func(configFile string, args []string) error {
+		if len(args) != argCount {
+			return usageError{msg: fmt.Sprintf("expected %d argument(s), got %d", argCount, len(args))}
+		}
+
+		m, err := store.NewMigrator(config.NewConfig(configFile))
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = m.Close() }()
+
+		return run(m, args)
+	}
+}
+
+func printStatus(m store.Migrator, _ []string) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATE")
+
+	for _, s := range status {
+		state := "pending"
+		if s.Dirty {
+			state = "dirty"
+		} else if s.Applied {
+			state = "applied"
+		}
+
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+
+	return w.Flush()
+}
+
+func printVersion(m store.Migrator, _ []string) error {
+	version, applied, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	if !applied {
+		fmt.Println("no migration applied")
+		return nil
+	}
+
+	if dirty {
+		fmt.Printf("%d (dirty)\n", version)
+		return nil
+	}
+
+	fmt.Println(version)
+	return nil
+}
+
+func gotoVersion(m store.Migrator, args []string) error {
+	version, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return usageError{msg: fmt.Sprintf("invalid version %s", args[0])}
+	}
+
+	return m.Goto(uint(version))
+}
+
+func forceVersion(m store.Migrator, args []string) error {
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < -1 {
+		return usageError{msg: fmt.Sprintf("invalid version %s", args[0])}
+	}
+
+	return m.Force(version)
+}
+
+func createMigration(configFile string, args []string) error {
+	if len(args) != 1 {
+		return usageError{msg: fmt.Sprintf("expected the migration name, got %d argument(s)", len(args))}
+	}
+
+	files, err := store.CreateMigration(config.NewConfig(configFile).MigrationPath(), args[0], time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Println(file)
+	}
+
+	return nil
+}
+
+func migrateCommandNames() []string {
+	return []string{
+		migrateUpCommand,
+		migrateDownCommand,
+		migrateStatusCommand,
+		migrateVersionCommand,
+		migrateGotoCommand,
+		migrateForceCommand,
+		migrateCreateCommand,
+	}
+}
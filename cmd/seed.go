@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/app"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	seedDateLayout = "2006-01-02"
+
+	defaultSeedCount     = 1000
+	defaultSeedBatchSize = 1000
+	defaultSeedDays      = 90
+)
+
+type seedOptions struct {
+	count      int
+	batchSize  int
+	randomSeed int64
+	from       time.Time
+	to         time.Time
+}
+
+// runSeed INSERTS GENERATED STORIES, BY DEFAULT THEY ARE CREATED OVER THE LAST 90 DAYS. PASS -to AS WELL
+// AS -seed TO GET THE SAME STORIES ON EVERY RUN.
+func runSeed(configFile string, args []string) error {
+	opts, err := parseSeedOptions(args, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return app.SeedStories(configFile, opts.count, opts.batchSize, opts.randomSeed, opts.from, opts.to)
+}
+
+func parseSeedOptions(args []string, now time.Time) (seedOptions, error) {
+	fs := flag.NewFlagSet(seedCommand, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	opts := seedOptions{}
+	var from, to string
+
+	fs.IntVar(&opts.count, "count", defaultSeedCount, "")
+	fs.IntVar(&opts.batchSize, "batch-size", defaultSeedBatchSize, "")
+	fs.Int64Var(&opts.randomSeed, "seed", 1, "")
+	fs.StringVar(&from, "from", "", "")
+	fs.StringVar(&to, "to", "", "")
+
+	if err := fs.Parse(args); err != nil {
+		return seedOptions{}, usageError{msg: err.Error()}
+	}
+
+	if fs.NArg() != 0 {
+		return seedOptions{}, usageError{msg: fmt.Sprintf("unexpected argument(s) %v", fs.Args())}
+	}
+
+	if opts.count < 1 {
+		return seedOptions{}, usageError{msg: fmt.Sprintf("invalid count %d", opts.count)}
+	}
+
+	if opts.batchSize < 1 {
+		return seedOptions{}, usageError{msg: fmt.Sprintf("invalid batch size %d", opts.batchSize)}
+	}
+
+	var err error
+
+	opts.to = now.UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		if opts.to, err = time.Parse(seedDateLayout, to); err != nil {
+			return seedOptions{}, usageError{msg: fmt.Sprintf("invalid date %s, expected %s", to, seedDateLayout)}
+		}
+	}
+
+	opts.from = opts.to.AddDate(0, 0, -defaultSeedDays)
+	if from != "" {
+		if opts.from, err = time.Parse(seedDateLayout, from); err != nil {
+			return seedOptions{}, usageError{msg: fmt.Sprintf("invalid date %s, expected %s", from, seedDateLayout)}
+		}
+	}
+
+	if !opts.from.Before(opts.to) {
+		return seedOptions{}, usageError{msg: fmt.Sprintf("from %s must be before to %s", opts.from.Format(seedDateLayout), opts.to.Format(seedDateLayout))}
+	}
+
+	return opts, nil
+}
@@ -1,14 +1,18 @@
 module github.com/nsnikhil/stories
 
-go 1.15
+go 1.16
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869
 	github.com/go-chi/chi v4.1.2+incompatible
+	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/golang-migrate/migrate/v4 v4.12.0
+	github.com/golang/protobuf v1.4.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.0.0
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
 	github.com/lib/pq v1.3.0
+	github.com/mattn/go-sqlite3 v1.14.5
 	github.com/newrelic/go-agent/v3 v3.9.0
 	github.com/newrelic/go-agent/v3/integrations/nrgorilla v1.1.0
 	github.com/newrelic/go-agent/v3/integrations/nrgrpc v1.1.0
@@ -16,8 +20,10 @@ require (
 	github.com/prometheus/client_golang v0.9.3
 	github.com/spf13/viper v1.7.0
 	github.com/stretchr/testify v1.5.1
+	github.com/vmihailenco/msgpack/v4 v4.3.12
 	go.uber.org/multierr v1.5.0 // indirect
 	go.uber.org/zap v1.10.0
+	google.golang.org/genproto v0.0.0-20200726014623-da3ae01ef02d
 	google.golang.org/grpc v1.30.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 )
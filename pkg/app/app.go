@@ -1,9 +1,96 @@
 package app
 
+import (
+	"context"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type server interface {
+	Start()
+}
+
 func StartGRPCServer(configFile string) {
-	initGRPCServer(configFile).Start()
+	start(initGRPCServer(configFile))
 }
 
 func StartHTTPServer(configFile string) {
-	initHTTPServer(configFile).Start()
+	start(initHTTPServer(configFile))
+}
+
+func StartOutboxRelay(configFile string) {
+	rl := initOutboxRelay(configFile)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	rl.Run(ctx)
+}
+
+// SeedStories INSERTS count GENERATED STORIES CREATED BETWEEN from AND to, THE SAME randomSeed AND WINDOW
+// ALWAYS GENERATE THE SAME STORIES
+func SeedStories(configFile string, count, batchSize int, randomSeed int64, from, to time.Time) error {
+	sd, err := initSeeder(configFile, batchSize, randomSeed, from, to)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	_, err = sd.Seed(ctx, count)
+	return err
+}
+
+// CreateAPIKey RETURNS THE NEW KEY ALONG WITH WHAT IS STORED OF IT, THE KEY CANNOT BE SHOWN AGAIN LATER
+func CreateAPIKey(configFile, name string) (string, auth.APIKey, error) {
+	str, err := initAPIKeyAdmin(configFile)
+	if err != nil {
+		return "", auth.APIKey{}, err
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return "", auth.APIKey{}, liberr.WithArgs(liberr.Operation("CreateAPIKey.NewAPIKey"), liberr.InternalError, liberr.SeverityError, err)
+	}
+
+	ak, err := str.AddAPIKey(context.Background(), name, auth.HashAPIKey(key))
+	if err != nil {
+		return "", auth.APIKey{}, err
+	}
+
+	return key, ak, nil
+}
+
+func ListAPIKeys(configFile string) ([]auth.APIKey, error) {
+	str, err := initAPIKeyAdmin(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return str.ListAPIKeys(context.Background())
+}
+
+// RevokeAPIKey TAKES EFFECT ONCE THE SERVERS HAVE DROPPED THE KEY FROM THEIR CACHE, SEE AUTH_API_KEY_CACHE_TTL_IN_SEC
+func RevokeAPIKey(configFile, id string) error {
+	str, err := initAPIKeyAdmin(configFile)
+	if err != nil {
+		return err
+	}
+
+	_, err = str.RevokeAPIKey(context.Background(), id)
+	return err
+}
+
+// Start ONLY RETURNS ONCE THE SERVER HAS DRAINED THE REQUESTS IN FLIGHT, NOTHING CAN INCREMENT A COUNTER
+// PAST THAT POINT SO WHAT IS LEFT IN THE AGGREGATOR IS FLUSHED BEFORE EXITING. A FAILED FLUSH IS
+// ALREADY LOGGED BY THE AGGREGATOR.
+func start(srv server, agg store.CounterAggregator) {
+	agg.Start()
+	srv.Start()
+	_ = agg.Stop()
 }
@@ -1,12 +1,20 @@
 package app
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/nsnikhil/stories/pkg/auth"
 	"github.com/nsnikhil/stories/pkg/config"
 	grpcserver "github.com/nsnikhil/stories/pkg/grpc/server"
 	"github.com/nsnikhil/stories/pkg/http/router"
 	httpserver "github.com/nsnikhil/stories/pkg/http/server"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/outbox"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/seed"
 	"github.com/nsnikhil/stories/pkg/store"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"go.uber.org/zap"
@@ -14,20 +22,51 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 )
 
-func initGRPCServer(configFile string) grpcserver.Server {
-	cfg, lgr, pr, nr, svc := initCommons(configFile)
-	return grpcserver.NewServer(cfg, lgr, nr, pr, svc)
+func initGRPCServer(configFile string) (grpcserver.Server, store.CounterAggregator) {
+	cfg, lgr, pr, nr, svc, authn, agg := initCommons(configFile)
+	return grpcserver.NewServer(cfg, lgr, nr, pr, svc, authn, initRateLimiters(cfg.RateLimitConfig().GRPCLimits())), agg
 }
 
-func initHTTPServer(configFile string) httpserver.Server {
-	cfg, lgr, pr, nr, svc := initCommons(configFile)
-	rt := initRouter(cfg.StoryConfig(), lgr, nr, pr, svc)
-	return httpserver.NewServer(cfg, lgr, rt)
+func initHTTPServer(configFile string) (httpserver.Server, store.CounterAggregator) {
+	cfg, lgr, pr, nr, svc, authn, agg := initCommons(configFile)
+	rt := initRouter(cfg, lgr, nr, pr, svc, authn)
+	return httpserver.NewServer(cfg, lgr, rt), agg
 }
 
-func initCommons(configFile string) (config.Config, *zap.Logger, reporters.Prometheus, *newrelic.Application, service.StoryService) {
+func initOutboxRelay(configFile string) outbox.Relay {
+	cfg := config.NewConfig(configFile)
+
+	lgr := initLogger(cfg)
+	pr := reporters.NewPrometheus()
+
+	db := initPrimaryDB(cfg.DatabaseConfig(), pr)
+	str := store.NewResilientStoriesStore(initStore(cfg.DatabaseConfig(), db, lgr, pr), cfg.ResilienceConfig(), pr)
+
+	return outbox.NewRelay(str, initSinks(cfg.OutboxConfig(), lgr), cfg.OutboxConfig(), lgr)
+}
+
+// initSeeder REFUSES THE MEMORY DRIVER, WHATEVER IT HOLDS IS GONE ONCE THE COMMAND EXITS
+func initSeeder(configFile string, batchSize int, randomSeed int64, from, to time.Time) (seed.Seeder, error) {
+	cfg := config.NewConfig(configFile)
+
+	if cfg.DatabaseConfig().DriverName() == store.MemoryDriverName {
+		return nil, liberr.WithArgs(liberr.Operation("initSeeder"), liberr.ValidationError, liberr.SeverityError, errors.New("cannot seed the memory driver, it keeps nothing once the command exits"))
+	}
+
+	lgr := initLogger(cfg)
+	pr := reporters.NewPrometheus()
+
+	db := initPrimaryDB(cfg.DatabaseConfig(), pr)
+	str := store.NewResilientStoriesStore(initStore(cfg.DatabaseConfig(), db, lgr, pr), cfg.ResilienceConfig(), pr)
+	gen := seed.NewGenerator(randomSeed, from, to, cfg.StoryConfig())
+
+	return seed.NewSeeder(str, gen, batchSize, lgr), nil
+}
+
+func initCommons(configFile string) (config.Config, *zap.Logger, reporters.Prometheus, *newrelic.Application, service.StoryService, auth.Authenticator, store.CounterAggregator) {
 	cfg := config.NewConfig(configFile)
 
 	lgr := initLogger(cfg)
@@ -37,29 +76,159 @@ func initCommons(configFile string) (config.Config, *zap.Logger, reporters.Prome
 		log.Fatal(err)
 	}
 
-	svc := initService(cfg)
+	db := initPrimaryDB(cfg.DatabaseConfig(), pr)
+
+	agg := store.NewCounterAggregator(
+		store.NewResilientStoriesStore(initStore(cfg.DatabaseConfig(), db, lgr, pr), cfg.ResilienceConfig(), pr),
+		cfg.CounterConfig(),
+		pr,
+		lgr,
+	)
 
-	return cfg, lgr, pr, nr, svc
+	svc := initService(cfg, agg, pr)
+
+	return cfg, lgr, pr, nr, svc, initAuthenticator(cfg, db), agg
 }
 
-func initRouter(cfg config.StoryConfig, lgr *zap.Logger, newRelic *newrelic.Application, prometheus reporters.Prometheus, svc service.StoryService) http.Handler {
-	return router.NewRouter(cfg, lgr, newRelic, prometheus, svc)
+func initRouter(cfg config.Config, lgr *zap.Logger, newRelic *newrelic.Application, prometheus reporters.Prometheus, svc service.StoryService, authn auth.Authenticator) http.Handler {
+	return router.NewRouter(cfg, lgr, newRelic, prometheus, svc, authn, initRateLimiters(cfg.RateLimitConfig().HTTPLimits()))
 }
 
-func initService(cfg config.Config) service.StoryService {
-	str := initStore(cfg.DatabaseConfig())
-	return service.NewStoriesService(str)
+func initAuthenticator(cfg config.Config, db *sql.DB) auth.Authenticator {
+	authn, err := auth.NewAuthenticator(cfg.AuthConfig(), initAPIKeyStore(cfg.DatabaseConfig(), db))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return authn
 }
 
-func initStore(cfg config.DatabaseConfig) store.StoriesStore {
-	dbh := store.NewDBHandler(cfg)
+// initAPIKeyStore SHARES THE PRIMARY POOL OF THE STORIES, THE KEYS ARE CACHED BY THE AUTHENTICATOR SO IT SEES LITTLE
+// TRAFFIC. THE MEMORY DRIVER STARTS WITHOUT ANY KEY, ONLY TOKENS CAN AUTHENTICATE AGAINST IT.
+func initAPIKeyStore(cfg config.DatabaseConfig, db *sql.DB) store.APIKeyStore {
+	if cfg.DriverName() == store.MemoryDriverName {
+		return store.NewInMemoryAPIKeyStore()
+	}
+
+	if cfg.DriverName() == store.SQLiteDriverName {
+		return store.NewSQLiteAPIKeyStore(db)
+	}
 
-	db, err := dbh.GetDB()
+	return store.NewAPIKeyStore(db)
+}
+
+// initAPIKeyAdmin REFUSES THE MEMORY DRIVER, A KEY CREATED BY A COMMAND WOULD BE GONE ONCE IT EXITS
+func initAPIKeyAdmin(configFile string) (store.APIKeyStore, error) {
+	cfg := config.NewConfig(configFile)
+
+	if cfg.DatabaseConfig().DriverName() == store.MemoryDriverName {
+		return nil, liberr.WithArgs(liberr.Operation("initAPIKeyAdmin"), liberr.ValidationError, liberr.SeverityError, errors.New("cannot manage api keys of the memory driver, it keeps nothing once the command exits"))
+	}
+
+	db, err := store.NewDBHandler(cfg.DatabaseConfig()).GetDB()
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("initAPIKeyAdmin"), err)
+	}
+
+	return initAPIKeyStore(cfg.DatabaseConfig(), db), nil
+}
+
+func initRateLimiters(limits map[string]config.RateLimit, err error) map[string]resilience.RateLimiter {
 	if err != nil {
-		log.Fatal(dbh)
+		log.Fatal(err)
+	}
+
+	limiters := make(map[string]resilience.RateLimiter, len(limits))
+	for api, limit := range limits {
+		limiters[api] = resilience.NewRateLimiter(limit.RequestsPerMinute(), time.Minute, limit.Burst())
+	}
+
+	return limiters
+}
+
+func initService(cfg config.Config, str store.StoriesStore, pr reporters.Prometheus) service.StoryService {
+	svc := service.NewTimeoutStoriesService(service.NewStoriesService(str), cfg.TimeoutConfig())
+
+	if cfg.CacheConfig().Enabled() {
+		return service.NewCachedStoriesService(svc, cfg.CacheConfig(), pr)
+	}
+
+	return svc
+}
+
+// initStore EXPORTS THE POOL STATS OF THE PRIMARY AND OF EVERY REPLICA AND INSTRUMENTS THE STORE CALLS
+func initStore(cfg config.DatabaseConfig, db *sql.DB, lgr *zap.Logger, pr reporters.Prometheus) store.StoriesStore {
+	return store.NewInstrumentedStoriesStore(initBaseStore(cfg, db, pr), cfg, pr, lgr)
+}
+
+// initPrimaryDB RETURNS NIL FOR THE MEMORY DRIVER, EVERY STORE OF A PROCESS SHARES THE POOL IT OPENS
+func initPrimaryDB(cfg config.DatabaseConfig, pr reporters.Prometheus) *sql.DB {
+	if cfg.DriverName() == store.MemoryDriverName {
+		return nil
+	}
+
+	db, err := store.NewDBHandler(cfg).GetDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pr.RegisterDBStats("primary", db.Stats)
+
+	return db
+}
+
+func initBaseStore(cfg config.DatabaseConfig, db *sql.DB, pr reporters.Prometheus) store.StoriesStore {
+	if cfg.DriverName() == store.MemoryDriverName {
+		return store.NewInMemoryStoriesStore()
+	}
+
+	if cfg.DriverName() == store.SQLiteDriverName {
+		return store.NewSQLiteStoriesStore(db)
+	}
+
+	replicas, err := store.NewDBHandler(cfg).GetReplicaDBs()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for i, replica := range replicas {
+		pr.RegisterDBStats(fmt.Sprintf("replica-%d", i), replica.Stats)
+	}
+
+	str, err := store.NewReplicatedStoriesStore(
+		store.NewDBCluster(db, replicas, cfg.ReplicaHealthCheckInterval(), cfg.ReadYourWritesWindow()),
+		cfg,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return str
+}
+
+func initSinks(cfg config.OutboxConfig, lgr *zap.Logger) []outbox.Sink {
+	sinkMap := map[string]func() (outbox.Sink, error){
+		outbox.LogSinkName:     func() (outbox.Sink, error) { return outbox.NewLogSink(lgr), nil },
+		outbox.WebhookSinkName: func() (outbox.Sink, error) { return outbox.NewWebhookSink(cfg.WebhookURL(), cfg.WebhookTimeout()) },
+		outbox.FileSinkName:    func() (outbox.Sink, error) { return outbox.NewFileSink(cfg.FilePath()) },
+	}
+
+	var sinks []outbox.Sink
+	for _, name := range cfg.Sinks() {
+		newSink, ok := sinkMap[name]
+		if !ok {
+			log.Fatalf("invalid outbox sink %s", name)
+		}
+
+		sink, err := newSink()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sinks = append(sinks, sink)
 	}
 
-	return store.NewStoriesStore(db)
+	return sinks
 }
 
 func initLogger(cfg config.Config) *zap.Logger {
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	apiKeyPrefix = "sk_"
+	apiKeyBytes  = 32
+)
+
+// APIKey IS WHAT IS STORED OF A KEY, THE KEY ITSELF IS ONLY SHOWN ONCE WHEN IT IS CREATED
+type APIKey struct {
+	ID        string
+	Name      string
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (ak APIKey) Revoked() bool {
+	return ak.RevokedAt != nil
+}
+
+// NewAPIKey RETURNS A RANDOM KEY, THE PREFIX MAKES A LEAKED KEY EASY TO SPOT IN LOGS AND SOURCE CODE
+func NewAPIKey() (string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey NEEDS NO SALT OR STRETCHING, UNLIKE A PASSWORD A RANDOM KEY CANNOT BE GUESSED FROM A DICTIONARY
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,27 @@
+package auth_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	one, err := auth.NewAPIKey()
+	require.NoError(t, err)
+
+	two, err := auth.NewAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(one, "sk_"))
+	assert.Len(t, one, 46)
+	assert.NotEqual(t, one, two)
+}
+
+func TestHashAPIKey(t *testing.T) {
+	assert.Equal(t, "12b2820cf1639904311da5771de1e5bb65c77073fdc7c555df395942df42896b", auth.HashAPIKey("sk_test"))
+	assert.Len(t, auth.HashAPIKey("sk_other"), 64)
+	assert.NotEqual(t, auth.HashAPIKey("sk_test"), auth.HashAPIKey("sk_other"))
+}
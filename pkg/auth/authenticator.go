@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/cache"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
+)
+
+// Credentials ARE WHAT A TRANSPORT FOUND ON A REQUEST, BOTH ARE EMPTY FOR AN ANONYMOUS ONE
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+func (c Credentials) empty() bool {
+	return len(c.APIKey) == 0 && len(c.BearerToken) == 0
+}
+
+// APIKeyFinder RETURNS A ResourceNotFound ERROR WHEN NO KEY HAS THE HASH
+type APIKeyFinder interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (APIKey, error)
+}
+
+type Authenticator interface {
+	// Authenticate RETURNS FALSE AND NO ERROR FOR AN ANONYMOUS REQUEST THAT IS LET THROUGH, ERRORS OF
+	// KIND Unauthenticated ARE THE FAULT OF THE CLIENT, ANY OTHER KIND MEANS THE KEYS COULD NOT BE LOOKED UP
+	Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error)
+}
+
+// defaultAuthenticator CACHES THE PRINCIPAL OF A VALID API KEY SO A REQUEST DOES NOT COST A QUERY,
+// INVALID KEYS ARE NOT CACHED
+type defaultAuthenticator struct {
+	keys     APIKeyFinder
+	verifier *tokenVerifier
+	cache    cache.Cache
+	cacheTTL time.Duration
+	required bool
+}
+
+func (da *defaultAuthenticator) Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error) {
+	switch {
+	case creds.empty() && da.required:
+		return Principal{}, false, unauthenticated("Authenticator.Authenticate", "an api key or a bearer token is required")
+	case creds.empty():
+		return Principal{}, false, nil
+	case len(creds.APIKey) != 0 && len(creds.BearerToken) != 0:
+		return Principal{}, false, unauthenticated("Authenticator.Authenticate", "send either an api key or a bearer token, not both")
+	case len(creds.APIKey) != 0:
+		return da.authenticateAPIKey(ctx, creds.APIKey)
+	default:
+		return da.authenticateToken(creds.BearerToken)
+	}
+}
+
+func (da *defaultAuthenticator) authenticateAPIKey(ctx context.Context, key string) (Principal, bool, error) {
+	hash := HashAPIKey(key)
+
+	if p, ok := da.cache.Get(hash); ok {
+		return p.(Principal), true, nil
+	}
+
+	ak, err := da.keys.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		var t *liberr.Error
+		if errors.As(err, &t) && t.Kind() == liberr.ResourceNotFound {
+			return Principal{}, false, unauthenticated("Authenticator.authenticateAPIKey", "invalid api key")
+		}
+
+		return Principal{}, false, liberr.WithArgs(liberr.Operation("Authenticator.authenticateAPIKey"), err)
+	}
+
+	if ak.Revoked() {
+		return Principal{}, false, unauthenticated("Authenticator.authenticateAPIKey", "api key has been revoked")
+	}
+
+	p := Principal{ID: ak.ID, Name: ak.Name, Method: MethodAPIKey}
+	da.cache.Set(hash, p, da.cacheTTL)
+
+	return p, true, nil
+}
+
+func (da *defaultAuthenticator) authenticateToken(token string) (Principal, bool, error) {
+	if da.verifier.keys.empty() {
+		return Principal{}, false, unauthenticated("Authenticator.authenticateToken", "bearer tokens are not accepted")
+	}
+
+	p, err := da.verifier.Verify(token)
+	if err != nil {
+		return Principal{}, false, unauthenticated("Authenticator.authenticateToken", err.Error())
+	}
+
+	return p, true, nil
+}
+
+func unauthenticated(op, msg string) error {
+	return liberr.WithArgs(liberr.Operation(op), liberr.Unauthenticated, liberr.SeverityInfo, errors.New(msg))
+}
+
+// NewAuthenticator FAILS WHEN A KEY FILE IN THE CONFIG CANNOT BE READ, A SERVER SHOULD NOT START WITHOUT ITS KEYS
+func NewAuthenticator(cfg config.AuthConfig, keys APIKeyFinder) (Authenticator, error) {
+	ks, err := loadKeySet(cfg.JWTHS256Secret(), cfg.JWTRS256PublicKeyFile(), cfg.JWTJWKSFile())
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("NewAuthenticator.loadKeySet"), liberr.ValidationError, liberr.SeverityError, err)
+	}
+
+	verifier := newTokenVerifier(ks, cfg.JWTIssuer(), cfg.JWTAudience(), cfg.JWTLeeway(), time.Now)
+
+	return newAuthenticator(keys, verifier, cache.NewLRUCache(cfg.APIKeyCacheSize()), cfg.APIKeyCacheTTL(), cfg.Required()), nil
+}
+
+func newAuthenticator(keys APIKeyFinder, verifier *tokenVerifier, c cache.Cache, cacheTTL time.Duration, required bool) *defaultAuthenticator {
+	return &defaultAuthenticator{
+		keys:     keys,
+		verifier: verifier,
+		cache:    c,
+		cacheTTL: cacheTTL,
+		required: required,
+	}
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt"
+	"github.com/nsnikhil/stories/pkg/cache"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "sk_test"
+
+type mockFinder struct {
+	mock.Mock
+}
+
+func (m *mockFinder) GetAPIKeyByHash(ctx context.Context, hash string) (APIKey, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(APIKey), args.Error(1)
+}
+
+func newTestAuthenticator(finder APIKeyFinder, keys keySet, required bool) *defaultAuthenticator {
+	verifier := newTokenVerifier(keys, "", "", 0, func() time.Time { return testNow })
+	return newAuthenticator(finder, verifier, cache.NewLRUCache(10), time.Minute, required)
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	revokedAt := testNow
+
+	testCases := map[string]struct {
+		creds             Credentials
+		required          bool
+		keys              keySet
+		key               APIKey
+		lookupErr         error
+		expectedPrincipal Principal
+		expectedOK        bool
+		expectedKind      liberr.Kind
+	}{
+		"test anonymous request is let through": {},
+		"test anonymous request is rejected when authentication is required": {
+			required:     true,
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test valid api key": {
+			creds:             Credentials{APIKey: testAPIKey},
+			key:               APIKey{ID: "key-1", Name: "ci", Hash: HashAPIKey(testAPIKey)},
+			expectedPrincipal: Principal{ID: "key-1", Name: "ci", Method: MethodAPIKey},
+			expectedOK:        true,
+		},
+		"test unknown api key": {
+			creds:        Credentials{APIKey: testAPIKey},
+			lookupErr:    liberr.WithArgs(liberr.ResourceNotFound, errors.New("no rows")),
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test revoked api key": {
+			creds:        Credentials{APIKey: testAPIKey},
+			key:          APIKey{ID: "key-1", Name: "ci", Hash: HashAPIKey(testAPIKey), RevokedAt: &revokedAt},
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test failed api key lookup is not the fault of the client": {
+			creds:        Credentials{APIKey: testAPIKey},
+			lookupErr:    liberr.WithArgs(liberr.Unavailable, errors.New("database is down")),
+			expectedKind: liberr.Unavailable,
+		},
+		"test valid bearer token": {
+			creds:             Credentials{BearerToken: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims())},
+			keys:              keySet{hmacSecret: []byte(testSecret)},
+			expectedPrincipal: Principal{ID: "user-1", Name: "reader", Method: MethodJWT},
+			expectedOK:        true,
+		},
+		"test invalid bearer token": {
+			creds:        Credentials{BearerToken: "not-a-token"},
+			keys:         keySet{hmacSecret: []byte(testSecret)},
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test bearer token without configured keys": {
+			creds:        Credentials{BearerToken: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims())},
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test both credentials are rejected": {
+			creds:        Credentials{APIKey: testAPIKey, BearerToken: "token"},
+			keys:         keySet{hmacSecret: []byte(testSecret)},
+			expectedKind: liberr.Unauthenticated,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			finder := &mockFinder{}
+			finder.On("GetAPIKeyByHash", mock.Anything, HashAPIKey(testAPIKey)).Return(testCase.key, testCase.lookupErr)
+
+			p, ok, err := newTestAuthenticator(finder, testCase.keys, testCase.required).Authenticate(context.Background(), testCase.creds)
+
+			if len(testCase.expectedKind) != 0 {
+				require.Error(t, err)
+				assert.Equal(t, testCase.expectedKind, err.(*liberr.Error).Kind())
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, testCase.expectedOK, ok)
+			assert.Equal(t, testCase.expectedPrincipal, p)
+		})
+	}
+}
+
+func TestAuthenticatorCachesValidAPIKeys(t *testing.T) {
+	finder := &mockFinder{}
+	finder.On("GetAPIKeyByHash", mock.Anything, HashAPIKey(testAPIKey)).Return(APIKey{ID: "key-1", Name: "ci"}, nil).Once()
+
+	authn := newTestAuthenticator(finder, keySet{}, false)
+
+	for i := 0; i < 3; i++ {
+		p, ok, err := authn.Authenticate(context.Background(), Credentials{APIKey: testAPIKey})
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "key-1", p.ID)
+	}
+
+	finder.AssertNumberOfCalls(t, "GetAPIKeyByHash", 1)
+}
+
+func TestPrincipalFrom(t *testing.T) {
+	_, ok := PrincipalFrom(context.Background())
+	assert.False(t, ok)
+
+	p := Principal{ID: "key-1", Name: "ci", Method: MethodAPIKey}
+
+	res, ok := PrincipalFrom(WithPrincipal(context.Background(), p))
+	assert.True(t, ok)
+	assert.Equal(t, p, res)
+	assert.Equal(t, "apiKey:key-1", res.String())
+}
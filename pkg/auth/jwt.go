@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+const (
+	hs256 = "HS256"
+	rs256 = "RS256"
+
+	rsaKeyType   = "RSA"
+	octKeyType   = "oct"
+	signatureUse = "sig"
+)
+
+// keySet HOLDS THE KEYS A TOKEN CAN BE SIGNED WITH, A TOKEN WITH A kid HEADER IS ONLY CHECKED AGAINST
+// THE JWKS KEY OF THAT ID, ONE WITHOUT IT AGAINST THE KEYS FROM THE CONFIG
+type keySet struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	jwks       map[string]interface{}
+}
+
+func (ks keySet) empty() bool {
+	return len(ks.hmacSecret) == 0 && ks.rsaKey == nil && len(ks.jwks) == 0
+}
+
+func (ks keySet) keyFor(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+
+	if kid, ok := token.Header["kid"].(string); ok && len(kid) != 0 {
+		key, ok := ks.jwks[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %s", kid)
+		}
+
+		if !keyMatches(alg, key) {
+			return nil, fmt.Errorf("key %s cannot verify %s", kid, alg)
+		}
+
+		return key, nil
+	}
+
+	switch {
+	case alg == hs256 && len(ks.hmacSecret) != 0:
+		return ks.hmacSecret, nil
+	case alg == rs256 && ks.rsaKey != nil:
+		return ks.rsaKey, nil
+	default:
+		return nil, fmt.Errorf("no key to verify %s", alg)
+	}
+}
+
+func keyMatches(alg string, key interface{}) bool {
+	switch key.(type) {
+	case []byte:
+		return alg == hs256
+	case *rsa.PublicKey:
+		return alg == rs256
+	default:
+		return false
+	}
+}
+
+func loadKeySet(hmacSecret, rsaKeyFile, jwksFile string) (keySet, error) {
+	ks := keySet{}
+
+	if len(hmacSecret) != 0 {
+		ks.hmacSecret = []byte(hmacSecret)
+	}
+
+	if len(rsaKeyFile) != 0 {
+		b, err := ioutil.ReadFile(rsaKeyFile)
+		if err != nil {
+			return keySet{}, err
+		}
+
+		if ks.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(b); err != nil {
+			return keySet{}, fmt.Errorf("invalid rsa public key in %s: %w", rsaKeyFile, err)
+		}
+	}
+
+	if len(jwksFile) != 0 {
+		b, err := ioutil.ReadFile(jwksFile)
+		if err != nil {
+			return keySet{}, err
+		}
+
+		if ks.jwks, err = parseJWKS(b); err != nil {
+			return keySet{}, fmt.Errorf("invalid jwks in %s: %w", jwksFile, err)
+		}
+	}
+
+	return ks, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// parseJWKS KEEPS THE RSA AND oct SIGNING KEYS, KEYS OF OTHER TYPES OR USES ARE SKIPPED
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+
+	for _, jwk := range doc.Keys {
+		if len(jwk.Use) != 0 && jwk.Use != signatureUse {
+			continue
+		}
+
+		if len(jwk.Kid) == 0 {
+			return nil, errors.New("every key needs a kid")
+		}
+
+		switch jwk.Kty {
+		case rsaKeyType:
+			key, err := rsaKeyOf(jwk)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", jwk.Kid, err)
+			}
+
+			keys[jwk.Kid] = key
+		case octKeyType:
+			secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("key %s: invalid k", jwk.Kid)
+			}
+
+			keys[jwk.Kid] = secret
+		}
+	}
+
+	return keys, nil
+}
+
+func rsaKeyOf(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid n")
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 {
+		return nil, errors.New("invalid e")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+// tokenVerifier ACCEPTS HS256 AND RS256 TOKENS THAT CARRY A sub AND AN exp, THE OTHER CLAIMS ARE ONLY
+// CHECKED WHEN THEY ARE PRESENT OR CONFIGURED
+type tokenVerifier struct {
+	keys     keySet
+	parser   *jwt.Parser
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+func (tv *tokenVerifier) Verify(token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := tv.parser.ParseWithClaims(token, claims, tv.keys.keyFor)
+	if err != nil {
+		return Principal{}, tokenError(err)
+	}
+
+	now := tv.now()
+
+	switch {
+	case !claims.VerifyExpiresAt(now.Add(-tv.leeway).Unix(), true):
+		return Principal{}, errors.New("token has expired or has no exp claim")
+	case !claims.VerifyNotBefore(now.Add(tv.leeway).Unix(), false):
+		return Principal{}, errors.New("token is not valid yet")
+	case len(tv.issuer) != 0 && !claims.VerifyIssuer(tv.issuer, true):
+		return Principal{}, errors.New("token issuer is not accepted")
+	case len(tv.audience) != 0 && !claims.VerifyAudience(tv.audience, true):
+		return Principal{}, errors.New("token audience is not accepted")
+	}
+
+	sub, _ := claims["sub"].(string)
+	if len(sub) == 0 {
+		return Principal{}, errors.New("token has no sub claim")
+	}
+
+	name, _ := claims["name"].(string)
+
+	return Principal{ID: sub, Name: name, Method: MethodJWT}, nil
+}
+
+// tokenError KEEPS THE REASON A TOKEN WAS REJECTED BUT NOT THE DETAILS OF THE KEYS IT WAS CHECKED AGAINST
+func tokenError(err error) error {
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return errors.New("invalid token")
+	}
+
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return errors.New("malformed token")
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0:
+		return errors.New("token is signed with an unknown key")
+	default:
+		return errors.New("invalid token signature")
+	}
+}
+
+func newTokenVerifier(keys keySet, issuer, audience string, leeway time.Duration, now func() time.Time) *tokenVerifier {
+	return &tokenVerifier{
+		keys:     keys,
+		parser:   &jwt.Parser{ValidMethods: []string{hs256, rs256}, SkipClaimsValidation: true},
+		issuer:   issuer,
+		audience: audience,
+		leeway:   leeway,
+		now:      now,
+	}
+}
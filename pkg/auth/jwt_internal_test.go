@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSecret = "a-secret-long-enough-for-hs256"
+
+var testNow = time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return key
+}
+
+func writePublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	b, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}), 0600))
+
+	return path
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	b, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+
+	return path
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if len(kid) != 0 {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":  "user-1",
+		"name": "reader",
+		"iss":  "https://issuer.example",
+		"aud":  []string{"stories", "other"},
+		"exp":  testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value interface{}) jwt.MapClaims {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestTokenVerifierVerify(t *testing.T) {
+	configKey := newRSAKey(t)
+	jwksKey := newRSAKey(t)
+	otherKey := newRSAKey(t)
+
+	oct := map[string]string{"kty": "oct", "kid": "oct-1", "k": base64.RawURLEncoding.EncodeToString([]byte("a-jwks-secret"))}
+
+	ks, err := loadKeySet(testSecret, writePublicKey(t, configKey), writeJWKS(t, rsaJWK("rsa-1", jwksKey), oct))
+	require.NoError(t, err)
+
+	verifier := newTokenVerifier(ks, "https://issuer.example", "stories", time.Minute, func() time.Time { return testNow })
+
+	testCases := map[string]struct {
+		token         string
+		expectedError string
+	}{
+		"test hs256 token signed with the configured secret": {
+			token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()),
+		},
+		"test rs256 token signed with the configured key": {
+			token: sign(t, jwt.SigningMethodRS256, configKey, "", validClaims()),
+		},
+		"test rs256 token signed with a jwks key": {
+			token: sign(t, jwt.SigningMethodRS256, jwksKey, "rsa-1", validClaims()),
+		},
+		"test hs256 token signed with a jwks key": {
+			token: sign(t, jwt.SigningMethodHS256, []byte("a-jwks-secret"), "oct-1", validClaims()),
+		},
+		"test token expired within the leeway": {
+			token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("exp", testNow.Add(-time.Second*30).Unix())),
+		},
+		"test token signed with another key": {
+			token:         sign(t, jwt.SigningMethodRS256, otherKey, "", validClaims()),
+			expectedError: "invalid token signature",
+		},
+		"test token with an unknown kid": {
+			token:         sign(t, jwt.SigningMethodRS256, jwksKey, "rsa-2", validClaims()),
+			expectedError: "token is signed with an unknown key",
+		},
+		"test hs256 token cannot use an rsa jwks key": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "rsa-1", validClaims()),
+			expectedError: "token is signed with an unknown key",
+		},
+		"test token of an algorithm that is not accepted": {
+			token:         sign(t, jwt.SigningMethodHS512, []byte(testSecret), "", validClaims()),
+			expectedError: "invalid token signature",
+		},
+		"test malformed token": {
+			token:         "not-a-token",
+			expectedError: "malformed token",
+		},
+		"test expired token": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("exp", testNow.Add(-time.Hour).Unix())),
+			expectedError: "token has expired or has no exp claim",
+		},
+		"test token without exp": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("exp", nil)),
+			expectedError: "token has expired or has no exp claim",
+		},
+		"test token not valid yet": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("nbf", testNow.Add(time.Hour).Unix())),
+			expectedError: "token is not valid yet",
+		},
+		"test token of another issuer": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("iss", "https://other.example")),
+			expectedError: "token issuer is not accepted",
+		},
+		"test token for another audience": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("aud", "other")),
+			expectedError: "token audience is not accepted",
+		},
+		"test token without sub": {
+			token:         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", withClaim("sub", nil)),
+			expectedError: "token has no sub claim",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := verifier.Verify(testCase.token)
+
+			if len(testCase.expectedError) != 0 {
+				require.Error(t, err)
+				assert.Equal(t, testCase.expectedError, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, Principal{ID: "user-1", Name: "reader", Method: MethodJWT}, p)
+		})
+	}
+}
+
+func TestLoadKeySetFailures(t *testing.T) {
+	dir := t.TempDir()
+
+	invalidPEM := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, ioutil.WriteFile(invalidPEM, []byte("not a key"), 0600))
+
+	testCases := map[string]struct {
+		rsaKeyFile string
+		jwksFile   string
+	}{
+		"test missing public key file": {
+			rsaKeyFile: filepath.Join(dir, "missing.pem"),
+		},
+		"test invalid public key": {
+			rsaKeyFile: invalidPEM,
+		},
+		"test missing jwks file": {
+			jwksFile: filepath.Join(dir, "missing.json"),
+		},
+		"test jwks key without kid": {
+			jwksFile: writeJWKS(t, map[string]string{"kty": "oct", "k": "c2VjcmV0"}),
+		},
+		"test jwks rsa key without modulus": {
+			jwksFile: writeJWKS(t, map[string]string{"kty": "RSA", "kid": "rsa-1", "e": "AQAB"}),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadKeySet("", testCase.rsaKeyFile, testCase.jwksFile)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseJWKSSkipsKeysNotMeantForSignatures(t *testing.T) {
+	keys, err := parseJWKS([]byte(`{"keys":[
+		{"kty":"oct","kid":"enc-1","use":"enc","k":"c2VjcmV0"},
+		{"kty":"EC","kid":"ec-1","crv":"P-256","x":"eA","y":"eQ"},
+		{"kty":"oct","kid":"sig-1","use":"sig","k":"c2VjcmV0"}
+	]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"sig-1": []byte("secret")}, keys)
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (mock *MockAuthenticator) Authenticate(ctx context.Context, creds Credentials) (Principal, bool, error) {
+	args := mock.Called(ctx, creds)
+	return args.Get(0).(Principal), args.Bool(1), args.Error(2)
+}
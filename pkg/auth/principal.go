@@ -0,0 +1,34 @@
+package auth
+
+import "context"
+
+type Method string
+
+const (
+	MethodAPIKey Method = "apiKey"
+	MethodJWT    Method = "jwt"
+)
+
+// Principal IS WHO MADE THE REQUEST, ITS ID IS THE ID OF THE API KEY OR THE sub CLAIM OF THE TOKEN
+type Principal struct {
+	ID     string
+	Name   string
+	Method Method
+}
+
+// String KEEPS THE PRINCIPALS OF BOTH METHODS APART EVEN WHEN A TOKEN SUBJECT MATCHES AN API KEY ID
+func (p Principal) String() string {
+	return string(p.Method) + ":" + p.ID
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom RETURNS FALSE FOR A REQUEST THAT WAS SERVED ANONYMOUSLY
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+	Purge()
+	Len() int
+}
+
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// lruCache EVICTS THE LEAST RECENTLY USED ENTRY ONCE IT IS FULL, EXPIRED ENTRIES ARE DROPPED ON READ
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+func (lc *lruCache) Get(key string) (interface{}, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	el, ok := lc.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if !lc.now().Before(e.expiresAt) {
+		lc.remove(el)
+		return nil, false
+	}
+
+	lc.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (lc *lruCache) Set(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 || lc.capacity <= 0 {
+		return
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	expiresAt := lc.now().Add(ttl)
+
+	if el, ok := lc.items[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expiresAt = value, expiresAt
+		lc.ll.MoveToFront(el)
+		return
+	}
+
+	lc.items[key] = lc.ll.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	if lc.ll.Len() > lc.capacity {
+		lc.remove(lc.ll.Back())
+	}
+}
+
+func (lc *lruCache) Delete(key string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if el, ok := lc.items[key]; ok {
+		lc.remove(el)
+	}
+}
+
+func (lc *lruCache) Purge() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.ll.Init()
+	lc.items = make(map[string]*list.Element)
+}
+
+func (lc *lruCache) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.ll.Len()
+}
+
+// MUST BE CALLED WITH THE LOCK HELD
+func (lc *lruCache) remove(el *list.Element) {
+	lc.ll.Remove(el)
+	delete(lc.items, el.Value.(*entry).key)
+}
+
+func NewLRUCache(capacity int) Cache {
+	return newLRUCache(capacity, time.Now)
+}
+
+func newLRUCache(capacity int, now func() time.Time) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      now,
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheGetAndSet(t *testing.T) {
+	lc := newLRUCache(2, time.Now)
+
+	lc.Set("one", 1, time.Minute)
+	lc.Set("two", 2, time.Minute)
+
+	v, ok := lc.Get("one")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	lc.Set("two", 22, time.Minute)
+
+	v, ok = lc.Get("two")
+	assert.True(t, ok)
+	assert.Equal(t, 22, v)
+
+	_, ok = lc.Get("three")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	lc := newLRUCache(2, time.Now)
+
+	lc.Set("one", 1, time.Minute)
+	lc.Set("two", 2, time.Minute)
+
+	_, _ = lc.Get("one")
+
+	lc.Set("three", 3, time.Minute)
+
+	_, ok := lc.Get("two")
+	assert.False(t, ok)
+
+	_, ok = lc.Get("one")
+	assert.True(t, ok)
+
+	_, ok = lc.Get("three")
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, lc.Len())
+}
+
+func TestLRUCacheExpiresEntries(t *testing.T) {
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	lc := newLRUCache(2, func() time.Time { return now })
+
+	lc.Set("one", 1, time.Second)
+	lc.Set("two", 2, 0)
+
+	_, ok := lc.Get("two")
+	assert.False(t, ok)
+
+	_, ok = lc.Get("one")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+
+	_, ok = lc.Get("one")
+	assert.False(t, ok)
+	assert.Equal(t, 0, lc.Len())
+}
+
+func TestLRUCacheDeleteAndPurge(t *testing.T) {
+	lc := newLRUCache(3, time.Now)
+
+	lc.Set("one", 1, time.Minute)
+	lc.Set("two", 2, time.Minute)
+	lc.Set("three", 3, time.Minute)
+
+	lc.Delete("one")
+
+	_, ok := lc.Get("one")
+	assert.False(t, ok)
+	assert.Equal(t, 2, lc.Len())
+
+	lc.Purge()
+
+	_, ok = lc.Get("two")
+	assert.False(t, ok)
+	assert.Equal(t, 0, lc.Len())
+}
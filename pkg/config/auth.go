@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// AuthConfig HOLDS THE KEYS THE JWTS ARE VERIFIED WITH, AN EMPTY KEY DISABLES ITS ALGORITHM
+type AuthConfig struct {
+	required              bool
+	apiKeyCacheTTLInSec   int
+	apiKeyCacheSize       int
+	jwtHS256Secret        string
+	jwtRS256PublicKeyFile string
+	jwtJWKSFile           string
+	jwtIssuer             string
+	jwtAudience           string
+	jwtLeewayInSec        int
+}
+
+func newAuthConfig() AuthConfig {
+	return AuthConfig{
+		required:              getBool("AUTH_REQUIRED", true),
+		apiKeyCacheTTLInSec:   getInt("AUTH_API_KEY_CACHE_TTL_IN_SEC", 30),
+		apiKeyCacheSize:       getInt("AUTH_API_KEY_CACHE_SIZE", 1000),
+		jwtHS256Secret:        getString("AUTH_JWT_HS256_SECRET", ""),
+		jwtRS256PublicKeyFile: strings.TrimSpace(getString("AUTH_JWT_RS256_PUBLIC_KEY_FILE", "")),
+		jwtJWKSFile:           strings.TrimSpace(getString("AUTH_JWT_JWKS_FILE", "")),
+		jwtIssuer:             getString("AUTH_JWT_ISSUER", ""),
+		jwtAudience:           getString("AUTH_JWT_AUDIENCE", ""),
+		jwtLeewayInSec:        getInt("AUTH_JWT_LEEWAY_IN_SEC", 30),
+	}
+}
+
+// WHEN TURNED OFF A REQUEST WITHOUT CREDENTIALS IS SERVED ANONYMOUSLY, INVALID CREDENTIALS ARE ALWAYS REJECTED
+func (ac AuthConfig) Required() bool {
+	return ac.required
+}
+
+// A REVOKED KEY KEEPS WORKING UNTIL ITS CACHED LOOKUP EXPIRES
+func (ac AuthConfig) APIKeyCacheTTL() time.Duration {
+	return time.Duration(ac.apiKeyCacheTTLInSec) * time.Second
+}
+
+func (ac AuthConfig) APIKeyCacheSize() int {
+	return ac.apiKeyCacheSize
+}
+
+func (ac AuthConfig) JWTHS256Secret() string {
+	return ac.jwtHS256Secret
+}
+
+// PATH OF A PEM ENCODED RSA PUBLIC KEY
+func (ac AuthConfig) JWTRS256PublicKeyFile() string {
+	return ac.jwtRS256PublicKeyFile
+}
+
+// PATH OF A JWKS DOCUMENT, ITS KEYS ARE PICKED BY THE kid HEADER OF THE TOKEN
+func (ac AuthConfig) JWTJWKSFile() string {
+	return ac.jwtJWKSFile
+}
+
+// WHEN SET THE iss CLAIM OF A TOKEN MUST MATCH IT
+func (ac AuthConfig) JWTIssuer() string {
+	return ac.jwtIssuer
+}
+
+// WHEN SET THE aud CLAIM OF A TOKEN MUST CONTAIN IT
+func (ac AuthConfig) JWTAudience() string {
+	return ac.jwtAudience
+}
+
+// HOW FAR THE CLOCKS OF THE ISSUER AND OF THE SERVER CAN DRIFT APART
+func (ac AuthConfig) JWTLeeway() time.Duration {
+	return time.Duration(ac.jwtLeewayInSec) * time.Second
+}
@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+type CacheConfig struct {
+	enabled            bool
+	size               int
+	storyTTLInSec      int
+	mostViewedTTLInSec int
+	topRatedTTLInSec   int
+}
+
+func newCacheConfig() CacheConfig {
+	return CacheConfig{
+		enabled:            getBool("CACHE_ENABLED", false),
+		size:               getInt("CACHE_SIZE", 1000),
+		storyTTLInSec:      getInt("CACHE_STORY_TTL_IN_SEC", 60),
+		mostViewedTTLInSec: getInt("CACHE_MOST_VIEWED_TTL_IN_SEC", 30),
+		topRatedTTLInSec:   getInt("CACHE_TOP_RATED_TTL_IN_SEC", 30),
+	}
+}
+
+func (cc CacheConfig) Enabled() bool {
+	return cc.enabled
+}
+
+func (cc CacheConfig) Size() int {
+	return cc.size
+}
+
+func (cc CacheConfig) StoryTTL() time.Duration {
+	return time.Duration(cc.storyTTLInSec) * time.Second
+}
+
+func (cc CacheConfig) MostViewedTTL() time.Duration {
+	return time.Duration(cc.mostViewedTTLInSec) * time.Second
+}
+
+func (cc CacheConfig) TopRatedTTL() time.Duration {
+	return time.Duration(cc.topRatedTTLInSec) * time.Second
+}
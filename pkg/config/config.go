@@ -3,18 +3,30 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 type Config struct {
 	env              string
 	migrationPath    string
+	migrationTimeout int
 	grpcServerConfig GRPCServerConfig
 	httpServerConfig HTTPServerConfig
+	httpCacheConfig  HTTPCacheConfig
 	newRelicConfig   NewRelicConfig
 	databaseConfig   DatabaseConfig
 	storyConfig      StoryConfig
+	cacheConfig      CacheConfig
+	timeoutConfig    TimeoutConfig
+	resilienceConfig ResilienceConfig
+	counterConfig    CounterConfig
+	outboxConfig     OutboxConfig
 	logConfig        LogConfig
 	logFileConfig    LogFileConfig
+
+	httpCompressionConfig HTTPCompressionConfig
+	rateLimitConfig       RateLimitConfig
+	authConfig            AuthConfig
 }
 
 func (c Config) GRPCServerConfig() GRPCServerConfig {
@@ -25,6 +37,22 @@ func (c Config) HTTPServerConfig() HTTPServerConfig {
 	return c.httpServerConfig
 }
 
+func (c Config) HTTPCacheConfig() HTTPCacheConfig {
+	return c.httpCacheConfig
+}
+
+func (c Config) HTTPCompressionConfig() HTTPCompressionConfig {
+	return c.httpCompressionConfig
+}
+
+func (c Config) RateLimitConfig() RateLimitConfig {
+	return c.rateLimitConfig
+}
+
+func (c Config) AuthConfig() AuthConfig {
+	return c.authConfig
+}
+
 func (c Config) NewRelicConfig() NewRelicConfig {
 	return c.newRelicConfig
 }
@@ -33,6 +61,26 @@ func (c Config) DatabaseConfig() DatabaseConfig {
 	return c.databaseConfig
 }
 
+func (c Config) CacheConfig() CacheConfig {
+	return c.cacheConfig
+}
+
+func (c Config) TimeoutConfig() TimeoutConfig {
+	return c.timeoutConfig
+}
+
+func (c Config) ResilienceConfig() ResilienceConfig {
+	return c.resilienceConfig
+}
+
+func (c Config) CounterConfig() CounterConfig {
+	return c.counterConfig
+}
+
+func (c Config) OutboxConfig() OutboxConfig {
+	return c.outboxConfig
+}
+
 func (c Config) LogConfig() LogConfig {
 	return c.logConfig
 }
@@ -49,6 +97,11 @@ func (c Config) MigrationPath() string {
 	return c.migrationPath
 }
 
+// HOW LONG A MIGRATION WAITS FOR ANOTHER ONE RUNNING AGAINST THE SAME DATABASE TO FINISH
+func (c Config) MigrationLockTimeout() time.Duration {
+	return time.Second * time.Duration(c.migrationTimeout)
+}
+
 func (c Config) StoryConfig() StoryConfig {
 	return c.storyConfig
 }
@@ -65,12 +118,23 @@ func NewConfig(configFile string) Config {
 	return Config{
 		env:              getString("ENV"),
 		migrationPath:    getString("MIGRATION_PATH"),
+		migrationTimeout: getInt("MIGRATION_LOCK_TIMEOUT_IN_SEC", 60),
 		grpcServerConfig: newGRPCServerConfig(),
 		httpServerConfig: newHTTPServerConfig(),
+		httpCacheConfig:  newHTTPCacheConfig(),
 		newRelicConfig:   newNewRelicConfig(),
 		databaseConfig:   newDatabaseConfig(),
 		storyConfig:      newStoryConfig(),
+		cacheConfig:      newCacheConfig(),
+		timeoutConfig:    newTimeoutConfig(),
+		resilienceConfig: newResilienceConfig(),
+		counterConfig:    newCounterConfig(),
+		outboxConfig:     newOutboxConfig(),
 		logConfig:        newLogConfig(),
 		logFileConfig:    newLogFileConfig(),
+
+		httpCompressionConfig: newHTTPCompressionConfig(),
+		rateLimitConfig:       newRateLimitConfig(),
+		authConfig:            newAuthConfig(),
 	}
 }
@@ -0,0 +1,44 @@
+package config
+
+import "time"
+
+type CounterConfig struct {
+	flushIntervalInMs    int
+	flushThreshold       int
+	batchSize            int
+	maxFlushAttempts     int
+	shutdownTimeoutInSec int
+}
+
+func newCounterConfig() CounterConfig {
+	return CounterConfig{
+		flushIntervalInMs:    getInt("COUNTER_FLUSH_INTERVAL_IN_MS", 1000),
+		flushThreshold:       getInt("COUNTER_FLUSH_THRESHOLD", 1000),
+		batchSize:            getInt("COUNTER_BATCH_SIZE", 500),
+		maxFlushAttempts:     getInt("COUNTER_MAX_FLUSH_ATTEMPTS", 5),
+		shutdownTimeoutInSec: getInt("COUNTER_SHUTDOWN_TIMEOUT_IN_SEC", 10),
+	}
+}
+
+func (cc CounterConfig) FlushInterval() time.Duration {
+	return toDuration(cc.flushIntervalInMs)
+}
+
+// NUMBER OF STORIES WITH PENDING INCREMENTS THAT TRIGGERS A FLUSH BEFORE THE INTERVAL IS UP
+func (cc CounterConfig) FlushThreshold() int {
+	return cc.flushThreshold
+}
+
+// NUMBER OF STORIES WRITTEN BY A SINGLE STATEMENT
+func (cc CounterConfig) BatchSize() int {
+	return cc.batchSize
+}
+
+// NUMBER OF FAILED FLUSHES AFTER WHICH THE INCREMENTS OF A STORY ARE DROPPED INSTEAD OF RETRIED
+func (cc CounterConfig) MaxFlushAttempts() int {
+	return cc.maxFlushAttempts
+}
+
+func (cc CounterConfig) ShutdownTimeout() time.Duration {
+	return time.Duration(cc.shutdownTimeoutInSec) * time.Second
+}
@@ -1,6 +1,11 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type DatabaseConfig struct {
 	driverName            string
@@ -12,6 +17,15 @@ type DatabaseConfig struct {
 	maxIdleConnections    int
 	maxOpenConnections    int
 	connectionMaxLifetime int
+
+	replicaHosts                    []string
+	replicaHealthCheckIntervalInSec int
+	readYourWritesWindowInSec       int
+
+	txIsolationLevel string
+	txMaxRetries     int
+
+	slowQueryThresholdInMs int
 }
 
 func newDatabaseConfig() DatabaseConfig {
@@ -25,6 +39,15 @@ func newDatabaseConfig() DatabaseConfig {
 		maxIdleConnections:    getInt("DB_MAX_IDLE_CONNECTIONS"),
 		maxOpenConnections:    getInt("DB_MAX_OPEN_CONNECTIONS"),
 		connectionMaxLifetime: getInt("DB_CONNECTION_MAX_LIFETIME_IN_MIN"),
+
+		replicaHosts:                    splitHosts(getString("DB_REPLICA_HOSTS")),
+		replicaHealthCheckIntervalInSec: getInt("DB_REPLICA_HEALTH_CHECK_INTERVAL_IN_SEC", 5),
+		readYourWritesWindowInSec:       getInt("DB_READ_YOUR_WRITES_WINDOW_IN_SEC", 0),
+
+		txIsolationLevel: getString("DB_TX_ISOLATION_LEVEL", "read_committed"),
+		txMaxRetries:     getInt("DB_TX_MAX_RETRIES", 3),
+
+		slowQueryThresholdInMs: getInt("DB_SLOW_QUERY_THRESHOLD_IN_MS", 500),
 	}
 }
 
@@ -32,8 +55,58 @@ func (dc DatabaseConfig) DriverName() string {
 	return dc.driverName
 }
 
+func (dc DatabaseConfig) Name() string {
+	return dc.name
+}
+
+// Source IS THE POSTGRES DSN OF THE PRIMARY, THE SQLITE STORE BUILDS ITS OWN FROM Name
 func (dc DatabaseConfig) Source() string {
-	return fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable", dc.username, dc.password, dc.host, dc.port, dc.name)
+	return dc.postgresSource(dc.host, dc.port)
+}
+
+// REPLICAS ARE GIVEN AS host OR host:port, THE PRIMARY PORT IS USED WHEN NONE IS SET
+func (dc DatabaseConfig) ReplicaSources() []string {
+	var sources []string
+
+	for _, replica := range dc.replicaHosts {
+		host, port := replica, dc.port
+
+		if i := strings.LastIndex(replica, ":"); i != -1 {
+			if p, err := strconv.Atoi(replica[i+1:]); err == nil {
+				host, port = replica[:i], p
+			}
+		}
+
+		sources = append(sources, dc.postgresSource(host, port))
+	}
+
+	return sources
+}
+
+func (dc DatabaseConfig) ReplicaHealthCheckInterval() time.Duration {
+	return time.Duration(dc.replicaHealthCheckIntervalInSec) * time.Second
+}
+
+func (dc DatabaseConfig) ReadYourWritesWindow() time.Duration {
+	return time.Duration(dc.readYourWritesWindowInSec) * time.Second
+}
+
+// ONE OF read_committed, repeatable_read OR serializable
+func (dc DatabaseConfig) TxIsolationLevel() string {
+	return dc.txIsolationLevel
+}
+
+func (dc DatabaseConfig) TxMaxRetries() int {
+	return dc.txMaxRetries
+}
+
+// A STORE CALL TAKING LONGER IS LOGGED, ZERO TURNS THE LOG OFF
+func (dc DatabaseConfig) SlowQueryThreshold() time.Duration {
+	return time.Duration(dc.slowQueryThresholdInMs) * time.Millisecond
+}
+
+func (dc DatabaseConfig) postgresSource(host string, port int) string {
+	return fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable", dc.username, dc.password, host, port, dc.name)
 }
 
 func (dc DatabaseConfig) IdleConnections() int {
@@ -47,3 +120,15 @@ func (dc DatabaseConfig) MaxOpenConnections() int {
 func (dc DatabaseConfig) ConnectionMaxLifetime() int {
 	return dc.connectionMaxLifetime
 }
+
+func splitHosts(hosts string) []string {
+	var res []string
+
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			res = append(res, host)
+		}
+	}
+
+	return res
+}
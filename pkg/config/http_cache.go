@@ -0,0 +1,36 @@
+package config
+
+// HTTPCacheConfig HOLDS THE Cache-Control OF THE HTTP READ ROUTES, AN EMPTY VALUE SENDS NO HEADER
+type HTTPCacheConfig struct {
+	getStory      string
+	listStories   string
+	searchStories string
+	analytics     string
+}
+
+func newHTTPCacheConfig() HTTPCacheConfig {
+	return HTTPCacheConfig{
+		getStory:      getString("HTTP_CACHE_CONTROL_GET_STORY", "no-cache"),
+		listStories:   getString("HTTP_CACHE_CONTROL_LIST_STORIES", "max-age=30"),
+		searchStories: getString("HTTP_CACHE_CONTROL_SEARCH_STORIES", "no-cache"),
+		analytics:     getString("HTTP_CACHE_CONTROL_ANALYTICS", "max-age=60"),
+	}
+}
+
+func (hc HTTPCacheConfig) GetStory() string {
+	return hc.getStory
+}
+
+// USED BY THE PAGINATED, MOST VIEWED AND TOP RATED LISTINGS
+func (hc HTTPCacheConfig) ListStories() string {
+	return hc.listStories
+}
+
+func (hc HTTPCacheConfig) SearchStories() string {
+	return hc.searchStories
+}
+
+// USED BY THE TIME SERIES AND TOP MOVERS QUERIES
+func (hc HTTPCacheConfig) Analytics() string {
+	return hc.analytics
+}
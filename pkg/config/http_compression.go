@@ -0,0 +1,26 @@
+package config
+
+import "strings"
+
+// HTTPCompressionConfig DECIDES WHICH HTTP RESPONSES ARE WORTH COMPRESSING
+type HTTPCompressionConfig struct {
+	minSize      int
+	contentTypes []string
+}
+
+func newHTTPCompressionConfig() HTTPCompressionConfig {
+	return HTTPCompressionConfig{
+		minSize:      getInt("HTTP_COMPRESSION_MIN_SIZE_IN_BYTES", 1024),
+		contentTypes: strings.Split(getString("HTTP_COMPRESSION_CONTENT_TYPES", "application/json,application/problem+json,application/x-msgpack"), ","),
+	}
+}
+
+// A BODY SMALLER THAN THIS IS SENT AS IS SINCE THE HEADERS AND THE CPU WOULD COST MORE THAN THE BYTES SAVED
+func (hc HTTPCompressionConfig) MinSize() int {
+	return hc.minSize
+}
+
+// ONLY THE RESPONSES OF THESE MEDIA TYPES ARE COMPRESSED
+func (hc HTTPCompressionConfig) ContentTypes() []string {
+	return hc.contentTypes
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+type OutboxConfig struct {
+	pollIntervalInMs   int
+	batchSize          int
+	sinks              []string
+	webhookURL         string
+	webhookTimeoutInMs int
+	filePath           string
+
+	leaseInSec int
+}
+
+func newOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		pollIntervalInMs:   getInt("OUTBOX_POLL_INTERVAL_IN_MS", 1000),
+		batchSize:          getInt("OUTBOX_BATCH_SIZE", 100),
+		sinks:              strings.Split(getString("OUTBOX_SINKS", "log"), ","),
+		webhookURL:         getString("OUTBOX_WEBHOOK_URL"),
+		webhookTimeoutInMs: getInt("OUTBOX_WEBHOOK_TIMEOUT_IN_MS", 5000),
+		filePath:           getString("OUTBOX_FILE_PATH"),
+
+		leaseInSec: getInt("OUTBOX_LEASE_IN_SEC", 60),
+	}
+}
+
+// HOW LONG THE RELAY WAITS BEFORE LOOKING FOR NEW EVENTS ONCE IT HAS PUBLISHED ALL THE PENDING ONES
+func (oc OutboxConfig) PollInterval() time.Duration {
+	return toDuration(oc.pollIntervalInMs)
+}
+
+func (oc OutboxConfig) BatchSize() int {
+	return oc.batchSize
+}
+
+// EVERY EVENT IS PUBLISHED TO ALL THE SINKS, ONE OF log, webhook OR file
+func (oc OutboxConfig) Sinks() []string {
+	return oc.sinks
+}
+
+func (oc OutboxConfig) WebhookURL() string {
+	return oc.webhookURL
+}
+
+func (oc OutboxConfig) WebhookTimeout() time.Duration {
+	return toDuration(oc.webhookTimeoutInMs)
+}
+
+func (oc OutboxConfig) FilePath() string {
+	return oc.filePath
+}
+
+// Lease IS HOW LONG THE EVENTS A RELAY CLAIMED ARE KEPT FROM THE OTHER RELAYS, IT HAS TO COVER PUBLISHING A WHOLE
+// BATCH. THE STORY OF AN EVENT THAT FAILED IS RETRIED ONCE THE LEASE RUNS OUT.
+func (oc OutboxConfig) Lease() time.Duration {
+	return time.Duration(oc.leaseInSec) * time.Second
+}
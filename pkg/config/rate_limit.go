@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"strconv"
+	"strings"
+)
+
+type RateLimit struct {
+	requestsPerMinute int
+	burst             int
+}
+
+func (rl RateLimit) RequestsPerMinute() int {
+	return rl.requestsPerMinute
+}
+
+// HOW MANY REQUESTS A CLIENT CAN MAKE AT ONCE BEFORE THE PER MINUTE RATE APPLIES
+func (rl RateLimit) Burst() int {
+	return rl.burst
+}
+
+type RateLimitConfig struct {
+	httpLimits        string
+	grpcLimits        string
+	trustProxyHeaders bool
+}
+
+func newRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		httpLimits:        getString("RATE_LIMITS", "add:60:20,createStory:60:20,upVote:120:40,downVote:120:40,view:600:100,viewStory:600:100,voteStory:120:40"),
+		grpcLimits:        getString("GRPC_RATE_LIMITS", "/StoriesApi/AddStory:60:20"),
+		trustProxyHeaders: getBool("RATE_LIMIT_TRUST_PROXY_HEADERS", false),
+	}
+}
+
+// HTTPLimits IS KEYED BY THE ROUTE NAME THE HTTP METRICS USE, createStory FOR INSTANCE, A ROUTE WITHOUT AN ENTRY IS NOT LIMITED
+func (rc RateLimitConfig) HTTPLimits() (map[string]RateLimit, error) {
+	return parseRateLimits("RATE_LIMITS", rc.httpLimits)
+}
+
+// GRPCLimits IS KEYED BY THE FULL GRPC METHOD, /StoriesApi/AddStory FOR INSTANCE, A METHOD WITHOUT AN ENTRY IS NOT LIMITED
+func (rc RateLimitConfig) GRPCLimits() (map[string]RateLimit, error) {
+	limits, err := parseRateLimits("GRPC_RATE_LIMITS", rc.grpcLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	for method := range limits {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return nil, liberr.WithArgs(liberr.Operation("RateLimitConfig.GRPCLimits"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid GRPC_RATE_LIMITS method %q, expected /Service/Method", method))
+		}
+	}
+
+	return limits, nil
+}
+
+// WHEN SET THE HTTP CLIENT ADDRESS IS READ FROM X-Forwarded-For OR X-Real-IP, ONLY ENABLE IT BEHIND A PROXY THAT SETS THEM
+func (rc RateLimitConfig) TrustProxyHeaders() bool {
+	return rc.trustProxyHeaders
+}
+
+// parseRateLimits READS A LIST OF api:requestsPerMinute:burst, A MALFORMED OR NON POSITIVE ENTRY FAILS THE WHOLE LIST
+// SO A TYPO CANNOT QUIETLY TURN A LIMIT OFF
+func parseRateLimits(name, limits string) (map[string]RateLimit, error) {
+	res := make(map[string]RateLimit)
+
+	invalid := func(limit string) error {
+		return liberr.WithArgs(liberr.Operation("parseRateLimits"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid %s entry %q, expected api:requestsPerMinute:burst", name, limit))
+	}
+
+	for _, limit := range strings.Split(limits, ",") {
+		limit = strings.TrimSpace(limit)
+		if len(limit) == 0 {
+			continue
+		}
+
+		parts := strings.Split(limit, ":")
+		if len(parts) != 3 || len(parts[0]) == 0 {
+			return nil, invalid(limit)
+		}
+
+		perMinute, err := strconv.Atoi(parts[1])
+		if err != nil || perMinute <= 0 {
+			return nil, invalid(limit)
+		}
+
+		burst, err := strconv.Atoi(parts[2])
+		if err != nil || burst <= 0 {
+			return nil, invalid(limit)
+		}
+
+		res[parts[0]] = RateLimit{requestsPerMinute: perMinute, burst: burst}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import "time"
+
+type ResilienceConfig struct {
+	retryMaxAttempts        int
+	retryInitialBackoffInMs int
+	retryMaxBackoffInMs     int
+	breakerFailureThreshold int
+	breakerOpenTimeoutInSec int
+}
+
+func newResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		retryMaxAttempts:        getInt("DB_RETRY_MAX_ATTEMPTS", 3),
+		retryInitialBackoffInMs: getInt("DB_RETRY_INITIAL_BACKOFF_IN_MS", 50),
+		retryMaxBackoffInMs:     getInt("DB_RETRY_MAX_BACKOFF_IN_MS", 1000),
+		breakerFailureThreshold: getInt("DB_BREAKER_FAILURE_THRESHOLD", 5),
+		breakerOpenTimeoutInSec: getInt("DB_BREAKER_OPEN_TIMEOUT_IN_SEC", 30),
+	}
+}
+
+func (rc ResilienceConfig) RetryMaxAttempts() int {
+	return rc.retryMaxAttempts
+}
+
+func (rc ResilienceConfig) RetryInitialBackoff() time.Duration {
+	return toDuration(rc.retryInitialBackoffInMs)
+}
+
+func (rc ResilienceConfig) RetryMaxBackoff() time.Duration {
+	return toDuration(rc.retryMaxBackoffInMs)
+}
+
+// ZERO DISABLES THE CIRCUIT BREAKER
+func (rc ResilienceConfig) BreakerFailureThreshold() int {
+	return rc.breakerFailureThreshold
+}
+
+func (rc ResilienceConfig) BreakerOpenTimeout() time.Duration {
+	return time.Duration(rc.breakerOpenTimeoutInSec) * time.Second
+}
@@ -0,0 +1,59 @@
+package config
+
+import "time"
+
+type TimeoutConfig struct {
+	addStoryInMs      int
+	getStoryInMs      int
+	updateStoryInMs   int
+	deleteStoryInMs   int
+	searchStoriesInMs int
+	listStoriesInMs   int
+	analyticsInMs     int
+}
+
+func newTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		addStoryInMs:      getInt("TIMEOUT_ADD_STORY_IN_MS", 2000),
+		getStoryInMs:      getInt("TIMEOUT_GET_STORY_IN_MS", 1000),
+		updateStoryInMs:   getInt("TIMEOUT_UPDATE_STORY_IN_MS", 2000),
+		deleteStoryInMs:   getInt("TIMEOUT_DELETE_STORY_IN_MS", 2000),
+		searchStoriesInMs: getInt("TIMEOUT_SEARCH_STORIES_IN_MS", 2000),
+		listStoriesInMs:   getInt("TIMEOUT_LIST_STORIES_IN_MS", 2000),
+		analyticsInMs:     getInt("TIMEOUT_ANALYTICS_IN_MS", 5000),
+	}
+}
+
+func (tc TimeoutConfig) AddStory() time.Duration {
+	return toDuration(tc.addStoryInMs)
+}
+
+func (tc TimeoutConfig) GetStory() time.Duration {
+	return toDuration(tc.getStoryInMs)
+}
+
+func (tc TimeoutConfig) UpdateStory() time.Duration {
+	return toDuration(tc.updateStoryInMs)
+}
+
+func (tc TimeoutConfig) DeleteStory() time.Duration {
+	return toDuration(tc.deleteStoryInMs)
+}
+
+func (tc TimeoutConfig) SearchStories() time.Duration {
+	return toDuration(tc.searchStoriesInMs)
+}
+
+// USED BY THE MOST VIEWED AND TOP RATED LISTINGS
+func (tc TimeoutConfig) ListStories() time.Duration {
+	return toDuration(tc.listStoriesInMs)
+}
+
+// USED BY THE TIME SERIES AND TOP MOVERS QUERIES
+func (tc TimeoutConfig) Analytics() time.Duration {
+	return toDuration(tc.analyticsInMs)
+}
+
+func toDuration(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
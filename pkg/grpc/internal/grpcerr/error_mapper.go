@@ -0,0 +1,100 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/protobuf/proto"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMessage     = "internal server error"
+	notFoundMessage    = "requested resource was not found"
+	conflictMessage    = "request conflicts with the current state of the resource"
+	constraintMessage  = "request violates a constraint on the stored data"
+	unavailableMessage = "service unavailable"
+	timeoutMessage     = "request timed out"
+	canceledMessage    = "request canceled"
+
+	errorDomain = "stories"
+)
+
+// MapError IS THE GRPC COUNTERPART OF resperr.MapError, ERRORS THAT ALREADY CARRY A STATUS ARE RETURNED AS IS.
+// EVERY STATUS CARRIES THE CODE OF THE ERROR AS AN ErrorInfo DETAIL.
+func MapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return newStatus(codes.DeadlineExceeded, timeoutMessage, liberr.CodeTimeout, nil)
+	case errors.Is(err, context.Canceled):
+		return newStatus(codes.Canceled, canceledMessage, liberr.CodeCanceled, nil)
+	}
+
+	t, ok := err.(*liberr.Error)
+	if !ok {
+		return newStatus(codes.Internal, defaultMessage, liberr.CodeInternal, nil)
+	}
+
+	c := t.Code()
+
+	switch t.Kind() {
+	case liberr.ValidationError:
+		return newStatus(codes.InvalidArgument, t.Error(), c, t.FieldViolations())
+	case liberr.ResourceNotFound:
+		return newStatus(codes.NotFound, notFoundMessage, c, nil)
+	// CONFLICTS COVER BOTH DUPLICATES AND TRANSACTIONS ABORTED BY CONCURRENT WRITES
+	case liberr.Conflict:
+		return newStatus(codes.Aborted, conflictMessage, c, nil)
+	case liberr.ConstraintViolation:
+		return newStatus(codes.InvalidArgument, constraintMessage, c, nil)
+	case liberr.Unavailable:
+		return newStatus(codes.Unavailable, unavailableMessage, c, nil)
+	// A FAILED PRECONDITION IS A STORY THAT CHANGED SINCE THE CLIENT READ IT
+	case liberr.PreconditionFailed:
+		return newStatus(codes.FailedPrecondition, t.Error(), liberr.CodeVersionConflict, nil)
+	case liberr.RateLimited:
+		return newStatus(codes.ResourceExhausted, t.Error(), c, nil)
+	case liberr.Unauthenticated:
+		return newStatus(codes.Unauthenticated, t.Error(), c, nil)
+	default:
+		return newStatus(codes.Internal, defaultMessage, c, nil)
+	}
+}
+
+// newStatus ATTACHES THE FIELD VIOLATIONS AS A BadRequest DETAIL, IT HAS NO ROOM FOR THE CODE
+// OF A VIOLATION SO THE METADATA OF THE ErrorInfo MAPS EVERY FIELD TO ITS CODE
+func newStatus(code codes.Code, msg string, errCode liberr.Code, violations []liberr.FieldViolation) error {
+	st := status.New(code, msg)
+
+	info := &errdetails.ErrorInfo{Reason: string(errCode), Domain: errorDomain}
+	details := []proto.Message{info}
+
+	if len(violations) != 0 {
+		info.Metadata = make(map[string]string, len(violations))
+
+		br := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(violations))}
+		for i, v := range violations {
+			info.Metadata[v.Field] = string(v.Code)
+			br.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Message}
+		}
+
+		details = append(details, br)
+	}
+
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
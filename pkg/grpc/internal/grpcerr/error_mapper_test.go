@@ -0,0 +1,168 @@
+package grpcerr_test
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/grpc/internal/grpcerr"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestMapError(t *testing.T) {
+	wrap := func(kind liberr.Kind, msg string) error {
+		return liberr.WithArgs(liberr.Operation("Server.GetStory"), liberr.WithArgs(kind, errors.New(msg)))
+	}
+
+	testCases := map[string]struct {
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+		expectedReason  liberr.Code
+	}{
+		"test map validation error": {
+			err:             wrap(liberr.ValidationError, "invalid uuid abc"),
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "invalid uuid abc",
+			expectedReason:  liberr.CodeInvalidRequest,
+		},
+		"test map resource not found": {
+			err:             wrap(liberr.ResourceNotFound, "no records found"),
+			expectedCode:    codes.NotFound,
+			expectedMessage: "requested resource was not found",
+			expectedReason:  liberr.CodeResourceNotFound,
+		},
+		"test map version conflict": {
+			err:             liberr.WithArgs(liberr.Operation("Server.UpdateStory"), liberr.WithArgs(liberr.Conflict, liberr.CodeVersionConflict, errors.New("pq: could not serialize access"))),
+			expectedCode:    codes.Aborted,
+			expectedMessage: "request conflicts with the current state of the resource",
+			expectedReason:  liberr.CodeVersionConflict,
+		},
+		"test map conflict": {
+			err:             wrap(liberr.Conflict, "pq: duplicate key value"),
+			expectedCode:    codes.Aborted,
+			expectedMessage: "request conflicts with the current state of the resource",
+			expectedReason:  liberr.CodeConflict,
+		},
+		"test map constraint violation": {
+			err:             wrap(liberr.ConstraintViolation, "pq: violates check constraint"),
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "request violates a constraint on the stored data",
+			expectedReason:  liberr.CodeConstraintViolation,
+		},
+		"test map unavailable": {
+			err:             wrap(liberr.Unavailable, "circuit breaker is open"),
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "service unavailable",
+			expectedReason:  liberr.CodeUnavailable,
+		},
+		"test map precondition failed": {
+			err:             wrap(liberr.PreconditionFailed, "story has changed since it was read"),
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: "story has changed since it was read",
+			expectedReason:  liberr.CodeVersionConflict,
+		},
+		"test map rate limited": {
+			err:             wrap(liberr.RateLimited, "rate limit of addstory exceeded"),
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "rate limit of addstory exceeded",
+			expectedReason:  liberr.CodeRateLimited,
+		},
+		"test map unauthenticated": {
+			err:             wrap(liberr.Unauthenticated, "invalid api key"),
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: "invalid api key",
+			expectedReason:  liberr.CodeUnauthenticated,
+		},
+		"test map internal error": {
+			err:             wrap(liberr.InternalError, "some error"),
+			expectedCode:    codes.Internal,
+			expectedMessage: "internal server error",
+			expectedReason:  liberr.CodeInternal,
+		},
+		"test map unknown error": {
+			err:             errors.New("some error"),
+			expectedCode:    codes.Internal,
+			expectedMessage: "internal server error",
+			expectedReason:  liberr.CodeInternal,
+		},
+		"test map deadline exceeded": {
+			err:             liberr.WithArgs(liberr.Operation("Server.GetStory"), context.DeadlineExceeded),
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: "request timed out",
+			expectedReason:  liberr.CodeTimeout,
+		},
+		"test map canceled": {
+			err:             liberr.WithArgs(liberr.Operation("Server.GetStory"), context.Canceled),
+			expectedCode:    codes.Canceled,
+			expectedMessage: "request canceled",
+			expectedReason:  liberr.CodeCanceled,
+		},
+		"test keep existing status": {
+			err:             status.Error(codes.PermissionDenied, "denied"),
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "denied",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			st := status.Convert(grpcerr.MapError(testCase.err))
+
+			assert.Equal(t, testCase.expectedCode, st.Code())
+			assert.Equal(t, testCase.expectedMessage, st.Message())
+			assert.Equal(t, testCase.expectedReason, reasonOf(st))
+		})
+	}
+
+	assert.Nil(t, grpcerr.MapError(nil))
+}
+
+func TestMapErrorAttachesFieldViolations(t *testing.T) {
+	err := liberr.WithArgs(
+		liberr.Operation("Server.AddStory"),
+		liberr.WithArgs(
+			liberr.ValidationError,
+			liberr.CodeTitleRequired,
+			liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"),
+			liberr.NewFieldViolation("body", liberr.CodeBodyTooLong, "body max length exceeded"),
+			errors.New("title cannot be empty; body max length exceeded"),
+		),
+	)
+
+	st := status.Convert(grpcerr.MapError(err))
+
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "title cannot be empty; body max length exceeded", st.Message())
+
+	require.Len(t, st.Details(), 2)
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+
+	assert.Equal(t, string(liberr.CodeTitleRequired), info.GetReason())
+	assert.Equal(t, map[string]string{"title": "TITLE_REQUIRED", "body": "BODY_TOO_LONG"}, info.GetMetadata())
+
+	br, ok := st.Details()[1].(*errdetails.BadRequest)
+	require.True(t, ok)
+
+	require.Len(t, br.GetFieldViolations(), 2)
+	assert.Equal(t, "title", br.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "title cannot be empty", br.GetFieldViolations()[0].GetDescription())
+	assert.Equal(t, "body", br.GetFieldViolations()[1].GetField())
+	assert.Equal(t, "body max length exceeded", br.GetFieldViolations()[1].GetDescription())
+}
+
+func reasonOf(st *status.Status) liberr.Code {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return liberr.Code(info.GetReason())
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"io"
+	"sync"
+)
+
+// gzipCompressor REPLACES THE gzip COMPRESSOR OF grpc, WHICH ONLY ALLOWS REGISTERING ONE AT INIT
+var gzipCompressor = &meteredCompressor{Compressor: encoding.GetCompressor(gzip.Name)}
+
+func init() {
+	encoding.RegisterCompressor(gzipCompressor)
+}
+
+// ReportGzipCompression HANDS THE gzip COMPRESSOR THE REPORTER OF HOW MUCH EVERY MESSAGE SHRANK, THE SERVER ONLY
+// COMPRESSES THE RESPONSES OF CLIENTS THAT SEND grpc-encoding: gzip
+func ReportGzipCompression(prometheus reporters.Prometheus) {
+	gzipCompressor.setReporter(prometheus)
+}
+
+func NewMeteredCompressor(compressor encoding.Compressor, prometheus reporters.Prometheus) encoding.Compressor {
+	return &meteredCompressor{Compressor: compressor, pr: prometheus}
+}
+
+// meteredCompressor REPORTS NOTHING UNTIL IT HAS A REPORTER
+type meteredCompressor struct {
+	encoding.Compressor
+
+	mu sync.RWMutex
+	pr reporters.Prometheus
+}
+
+func (mc *meteredCompressor) setReporter(prometheus reporters.Prometheus) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.pr = prometheus
+}
+
+func (mc *meteredCompressor) reporter() reporters.Prometheus {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	return mc.pr
+}
+
+// DecompressedSize LETS grpc SIZE THE BUFFER OF A MESSAGE UP FRONT WHEN THE WRAPPED COMPRESSOR KNOWS IT, -1 IF IT DOES NOT
+func (mc *meteredCompressor) DecompressedSize(buf []byte) int {
+	sizer, ok := mc.Compressor.(interface{ DecompressedSize(buf []byte) int })
+	if !ok {
+		return -1
+	}
+
+	return sizer.DecompressedSize(buf)
+}
+
+func (mc *meteredCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	pr := mc.reporter()
+	if pr == nil {
+		return mc.Compressor.Compress(w)
+	}
+
+	out := &countingWriter{Writer: w}
+
+	wc, err := mc.Compressor.Compress(out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &meteredWriteCloser{WriteCloser: wc, out: out, name: mc.Name(), pr: pr}, nil
+}
+
+type meteredWriteCloser struct {
+	io.WriteCloser
+	out          *countingWriter
+	name         string
+	pr           reporters.Prometheus
+	uncompressed int
+}
+
+func (mw *meteredWriteCloser) Write(b []byte) (int, error) {
+	n, err := mw.WriteCloser.Write(b)
+	mw.uncompressed += n
+	return n, err
+}
+
+// Close FLUSHES THE COMPRESSOR SO THE COMPRESSED SIZE IS ONLY KNOWN AFTER IT
+func (mw *meteredWriteCloser) Close() error {
+	if err := mw.WriteCloser.Close(); err != nil {
+		return err
+	}
+
+	mw.pr.ObserveCompression("grpc", mw.name, mw.uncompressed, mw.out.n)
+	return nil
+}
+
+type countingWriter struct {
+	io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.Writer.Write(b)
+	cw.n += n
+	return n, err
+}
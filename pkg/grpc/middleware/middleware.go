@@ -2,10 +2,20 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/grpc/internal/grpcerr"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/store"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -64,3 +74,126 @@ func WithPrometheus(pr reporters.Prometheus) func(ctx context.Context, req inter
 		return h, err
 	}
 }
+
+// WithStatusMapper TURNS THE ERROR RETURNED BY THE HANDLER INTO A GRPC STATUS, IT HAS TO BE THE
+// OUTERMOST INTERCEPTOR SO THE LOGGING AND METRICS INTERCEPTORS STILL SEE THE ORIGINAL ERROR
+func WithStatusMapper() func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		h, err := handler(ctx, req)
+		return h, grpcerr.MapError(err)
+	}
+}
+
+const (
+	// APIKeyMetadata CARRIES THE API KEY OF A CLIENT
+	APIKeyMetadata = "x-api-key"
+
+	authorizationMetadata = "authorization"
+	bearerScheme          = "bearer"
+)
+
+// WithAuthentication PUTS THE PRINCIPAL OF THE API KEY OR OF THE BEARER TOKEN ON THE CONTEXT, THE RATE LIMIT AND THE
+// SESSION KNOW THE CLIENT BY IT. THE PUBLIC METHODS AND A NIL AUTHENTICATOR LEAVE THE CALL ANONYMOUS.
+func WithAuthentication(authn auth.Authenticator, lgr *zap.Logger, public ...string) func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	publicMethods := make(map[string]bool, len(public))
+	for _, method := range public {
+		publicMethods[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if authn == nil || publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		p, ok, err := authenticate(ctx, authn)
+		if err != nil {
+			// THE ERROR LOGGER SITS FURTHER IN, A FAILED KEY LOOKUP WOULD GO UNNOTICED OTHERWISE
+			if t, isLibErr := err.(*liberr.Error); isLibErr && t.Kind() != liberr.Unauthenticated {
+				lgr.Error(t.EncodedStack())
+			}
+
+			return nil, err
+		}
+
+		if ok {
+			ctx = auth.WithPrincipal(ctx, p)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func authenticate(ctx context.Context, authn auth.Authenticator) (auth.Principal, bool, error) {
+	creds := auth.Credentials{}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if keys := md.Get(APIKeyMetadata); len(keys) != 0 {
+		creds.APIKey = keys[0]
+	}
+
+	if values := md.Get(authorizationMetadata); len(values) != 0 && len(values[0]) != 0 {
+		parts := strings.SplitN(values[0], " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], bearerScheme) {
+			return auth.Principal{}, false, liberr.WithArgs(liberr.Operation("WithAuthentication"), liberr.Unauthenticated, liberr.SeverityInfo, errors.New("only the bearer authorization scheme is supported"))
+		}
+
+		creds.BearerToken = strings.TrimSpace(parts[1])
+	}
+
+	return authn.Authenticate(ctx, creds)
+}
+
+// WithRateLimit FAILS THE CALL WITH RESOURCE EXHAUSTED AND A retry-after HEADER ONCE A CLIENT HAS USED UP ITS TOKENS
+// FOR THE METHOD, A CLIENT IS KNOWN BY ITS PRINCIPAL OR ELSE BY ITS ADDRESS. THE LIMITERS ARE KEYED BY THE FULL METHOD,
+// A METHOD WITHOUT A LIMITER IS NOT LIMITED
+func WithRateLimit(limiters map[string]resilience.RateLimiter, pr reporters.Prometheus) func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limiter, ok := limiters[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		methods := strings.Split(info.FullMethod, "/")
+		api := strings.ToLower(methods[len(methods)-1])
+
+		allowed, wait := limiter.Allow(clientIdentity(ctx))
+		if allowed {
+			pr.ReportRateLimitAllowed(api)
+			return handler(ctx, req)
+		}
+
+		pr.ReportRateLimitRejected(api)
+
+		retryAfter := resilience.RetryAfter(wait)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+
+		return nil, liberr.WithArgs(liberr.Operation("WithRateLimit"), liberr.RateLimited, liberr.SeverityInfo, fmt.Errorf("rate limit of %s exceeded, retry in %d seconds", api, retryAfter))
+	}
+}
+
+// WithSession LETS THE STORE SEND THE READS OF A CLIENT THAT JUST WROTE TO THE PRIMARY, A CLIENT IS KNOWN THE SAME
+// WAY AS FOR THE RATE LIMIT
+func WithSession() func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(store.WithSession(ctx, clientIdentity(ctx)), req)
+	}
+}
+
+func clientIdentity(ctx context.Context) string {
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		return p.String()
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "ip:unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "ip:" + p.Addr.String()
+	}
+
+	return "ip:" + host
+}
@@ -4,15 +4,26 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"github.com/nsnikhil/stories/pkg/auth"
 	"github.com/nsnikhil/stories/pkg/grpc/middleware"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWithReqRespLogger(t *testing.T) {
@@ -171,3 +182,239 @@ func TestWithPrometheus(t *testing.T) {
 		})
 	}
 }
+
+func TestWithStatusMapper(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		expectedCode codes.Code
+	}{
+		"test map deadline exceeded": {
+			err:          liberr.WithArgs(liberr.Operation("Server.GetStory"), context.DeadlineExceeded),
+			expectedCode: codes.DeadlineExceeded,
+		},
+		"test map canceled": {
+			err:          liberr.WithArgs(liberr.Operation("Server.GetStory"), context.Canceled),
+			expectedCode: codes.Canceled,
+		},
+		"test map unavailable": {
+			err:          liberr.WithArgs(liberr.Operation("Server.GetStory"), liberr.WithArgs(liberr.Unavailable, errors.New("circuit breaker is open"))),
+			expectedCode: codes.Unavailable,
+		},
+		"test map unknown error to internal": {
+			err:          errors.New("some error"),
+			expectedCode: codes.Internal,
+		},
+		"test no error": {
+			expectedCode: codes.OK,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f := middleware.WithStatusMapper()
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, testCase.err
+			}
+
+			_, err := f(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+
+			assert.Equal(t, testCase.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestMeteredCompressor(t *testing.T) {
+	message := []byte(strings.Repeat("story body ", 100))
+
+	mockPrometheus := &reporters.MockPrometheus{}
+	mockPrometheus.On("ObserveCompression", "grpc", "gzip", len(message), mock.AnythingOfType("int"))
+
+	mc := middleware.NewMeteredCompressor(encoding.GetCompressor(gzip.Name), mockPrometheus)
+	assert.Equal(t, gzip.Name, mc.Name())
+
+	buf := new(bytes.Buffer)
+
+	wc, err := mc.Compress(buf)
+	require.NoError(t, err)
+
+	_, err = wc.Write(message)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	mockPrometheus.AssertCalled(t, "ObserveCompression", "grpc", "gzip", len(message), buf.Len())
+	assert.Less(t, buf.Len(), len(message))
+
+	r, err := mc.Decompress(buf)
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, message, b)
+}
+
+func TestReportGzipCompression(t *testing.T) {
+	message := []byte(strings.Repeat("story body ", 100))
+
+	mockPrometheus := &reporters.MockPrometheus{}
+	mockPrometheus.On("ObserveCompression", "grpc", "gzip", len(message), mock.AnythingOfType("int"))
+
+	middleware.ReportGzipCompression(mockPrometheus)
+	defer middleware.ReportGzipCompression(nil)
+
+	gc := encoding.GetCompressor(gzip.Name)
+
+	buf := new(bytes.Buffer)
+
+	wc, err := gc.Compress(buf)
+	require.NoError(t, err)
+
+	_, err = wc.Write(message)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	mockPrometheus.AssertNumberOfCalls(t, "ObserveCompression", 1)
+
+	sizer, ok := gc.(interface{ DecompressedSize(buf []byte) int })
+	require.True(t, ok)
+	assert.Equal(t, len(message), sizer.DecompressedSize(buf.Bytes()))
+}
+
+func TestWithRateLimit(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+
+	withKey := func(key string) context.Context {
+		return auth.WithPrincipal(context.Background(), auth.Principal{ID: key, Method: auth.MethodAPIKey})
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/StoriesApi/AddStory"}
+
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportRateLimitAllowed", "addstory")
+	pr.On("ReportRateLimitRejected", "addstory")
+
+	f := middleware.WithRateLimit(map[string]resilience.RateLimiter{"/StoriesApi/AddStory": resilience.NewRateLimiter(1, time.Minute, 1)}, pr)
+
+	resp, err := f(withKey("one"), "request", info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "response", resp)
+
+	_, err = f(withKey("one"), "request", info, handler)
+	require.Error(t, err)
+
+	assert.Equal(t, liberr.RateLimited, err.(*liberr.Error).Kind())
+	assert.Equal(t, "rate limit of addstory exceeded, retry in 60 seconds", err.Error())
+
+	_, err = f(withKey("two"), "request", info, handler)
+	require.NoError(t, err)
+
+	_, err = f(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/StoriesApi/GetStory"}, handler)
+	require.NoError(t, err)
+
+	pr.AssertNumberOfCalls(t, "ReportRateLimitAllowed", 2)
+	pr.AssertNumberOfCalls(t, "ReportRateLimitRejected", 1)
+}
+
+func TestWithAuthentication(t *testing.T) {
+	principal := auth.Principal{ID: "user-1", Method: auth.MethodJWT}
+
+	testCases := map[string]struct {
+		md                metadata.MD
+		fullMethod        string
+		expectedCreds     *auth.Credentials
+		principal         auth.Principal
+		ok                bool
+		err               error
+		expectedKind      liberr.Kind
+		expectedPrincipal bool
+	}{
+		"test anonymous call is let through": {
+			fullMethod:    "/StoriesApi/DeleteStory",
+			expectedCreds: &auth.Credentials{},
+		},
+		"test api key puts the principal on the context": {
+			md:                metadata.Pairs(middleware.APIKeyMetadata, "sk_key"),
+			fullMethod:        "/StoriesApi/DeleteStory",
+			expectedCreds:     &auth.Credentials{APIKey: "sk_key"},
+			principal:         principal,
+			ok:                true,
+			expectedPrincipal: true,
+		},
+		"test bearer token is read from the authorization metadata": {
+			md:                metadata.Pairs("authorization", "Bearer token"),
+			fullMethod:        "/StoriesApi/DeleteStory",
+			expectedCreds:     &auth.Credentials{BearerToken: "token"},
+			principal:         principal,
+			ok:                true,
+			expectedPrincipal: true,
+		},
+		"test invalid credentials are rejected": {
+			md:            metadata.Pairs(middleware.APIKeyMetadata, "sk_key"),
+			fullMethod:    "/StoriesApi/DeleteStory",
+			expectedCreds: &auth.Credentials{APIKey: "sk_key"},
+			err:           liberr.WithArgs(liberr.Unauthenticated, errors.New("invalid api key")),
+			expectedKind:  liberr.Unauthenticated,
+		},
+		"test other authorization schemes are rejected": {
+			md:           metadata.Pairs("authorization", "Basic dXNlcjpwYXNz"),
+			fullMethod:   "/StoriesApi/DeleteStory",
+			expectedKind: liberr.Unauthenticated,
+		},
+		"test public method is not authenticated": {
+			md:         metadata.Pairs(middleware.APIKeyMetadata, "sk_key"),
+			fullMethod: "/StoriesApi/Ping",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			authn := &auth.MockAuthenticator{}
+			if testCase.expectedCreds != nil {
+				authn.On("Authenticate", mock.Anything, *testCase.expectedCreds).Return(testCase.principal, testCase.ok, testCase.err)
+			}
+
+			var seen bool
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				_, seen = auth.PrincipalFrom(ctx)
+				return "response", nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), testCase.md)
+			f := middleware.WithAuthentication(authn, zap.NewNop(), "/StoriesApi/Ping")
+
+			_, err := f(ctx, "request", &grpc.UnaryServerInfo{FullMethod: testCase.fullMethod}, handler)
+
+			if len(testCase.expectedKind) != 0 {
+				require.Error(t, err)
+				assert.Equal(t, testCase.expectedKind, err.(*liberr.Error).Kind())
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, testCase.expectedPrincipal, seen)
+
+			if testCase.expectedCreds == nil {
+				authn.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWithSession(t *testing.T) {
+	var session string
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		session, _ = store.SessionFrom(ctx)
+		return "response", nil
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "user-1", Method: auth.MethodJWT})
+
+	_, err := middleware.WithSession()(ctx, "request", &grpc.UnaryServerInfo{FullMethod: "/StoriesApi/GetStory"}, handler)
+	require.NoError(t, err)
+
+	assert.Equal(t, "jwt:user-1", session)
+}
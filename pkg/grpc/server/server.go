@@ -8,11 +8,13 @@ import (
 	"github.com/newrelic/go-agent/v3/integrations/nrgrpc"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/nsnikhil/stories-proto/proto"
+	"github.com/nsnikhil/stories/pkg/auth"
 	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/grpc/middleware"
 	"github.com/nsnikhil/stories/pkg/grpc/server/health"
 	"github.com/nsnikhil/stories/pkg/grpc/server/stories"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
@@ -38,20 +40,30 @@ type appServer struct {
 	pr  reporters.Prometheus
 	nr  *newrelic.Application
 
-	svc service.StoryService
+	svc   service.StoryService
+	authn auth.Authenticator
+	rl    map[string]resilience.RateLimiter
 }
 
-func NewServer(cfg config.Config, logger *zap.Logger, nr *newrelic.Application, pr reporters.Prometheus, svc service.StoryService) Server {
+// THE HEALTH CHECK AND PING STAY ANONYMOUS SO PROBES NEED NO CREDENTIALS
+var publicMethods = []string{"/Health/Check", "/StoriesApi/Ping"}
+
+// NewServer AUTHENTICATES EVERY METHOD BUT THE PUBLIC ONES WITH authn AND RATE LIMITS EVERY METHOD THAT HAS A LIMITER IN rl
+func NewServer(cfg config.Config, logger *zap.Logger, nr *newrelic.Application, pr reporters.Prometheus, svc service.StoryService, authn auth.Authenticator, rl map[string]resilience.RateLimiter) Server {
 	return &appServer{
-		cfg: cfg,
-		lgr: logger,
-		pr:  pr,
-		nr:  nr,
-		svc: svc,
+		cfg:   cfg,
+		lgr:   logger,
+		pr:    pr,
+		nr:    nr,
+		svc:   svc,
+		authn: authn,
+		rl:    rl,
 	}
 }
 
 func (as *appServer) Start() {
+	middleware.ReportGzipCompression(as.pr)
+
 	grpcServer := newGrpcServer(as)
 
 	storiesServer := stories.NewStoriesServer(as.cfg.StoryConfig(), as.svc)
@@ -99,8 +111,12 @@ func newGrpcServer(as *appServer) *grpc.Server {
 		),
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
+				middleware.WithStatusMapper(),
 				middleware.WithReqRespLogger(as.lgr),
 				middleware.WithPrometheus(as.pr),
+				middleware.WithAuthentication(as.authn, as.lgr, publicMethods...),
+				middleware.WithRateLimit(as.rl, as.pr),
+				middleware.WithSession(),
 				middleware.WithErrorLogger(as.lgr),
 				grpc_recovery.UnaryServerInterceptor(),
 				grpc_prometheus.UnaryServerInterceptor,
@@ -18,7 +18,7 @@ func (ss *Server) AddStory(ctx context.Context, req *proto.AddStoryRequest) (*pr
 			liberr.WithArgs(liberr.Operation("Server.AddStory"), err)
 	}
 
-	if err := ss.svc.AddStory(st); err != nil {
+	if err := ss.svc.AddStory(ctx, st); err != nil {
 		return &proto.AddStoryResponse{Success: false},
 			liberr.WithArgs(liberr.Operation("Server.AddStory"), err)
 	}
@@ -22,7 +22,7 @@ func TestStoriesServerAddStory(t *testing.T) {
 		"test add story success": {
 			input: func() (service.StoryService, *proto.AddStoryRequest) {
 				ms := &service.MockStoriesService{}
-				ms.On("AddStory", mock.AnythingOfType("*model.Story")).Return(nil)
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return(nil)
 
 				req := &proto.AddStoryRequest{
 					Story: &proto.Story{
@@ -57,6 +57,8 @@ func TestStoriesServerAddStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeTitleRequired,
+					liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"),
 					errors.New("title cannot be empty"),
 				),
 			),
@@ -81,6 +83,8 @@ func TestStoriesServerAddStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeBodyRequired,
+					liberr.NewFieldViolation("body", liberr.CodeBodyRequired, "body cannot be empty"),
 					errors.New("body cannot be empty"),
 				),
 			),
@@ -88,7 +92,7 @@ func TestStoriesServerAddStory(t *testing.T) {
 		"test add story return error service calls fails": {
 			input: func() (service.StoryService, *proto.AddStoryRequest) {
 				ms := &service.MockStoriesService{}
-				ms.On("AddStory", mock.AnythingOfType("*model.Story")).Return(liberr.WithArgs(errors.New("failed to add story")))
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return(liberr.WithArgs(errors.New("failed to add story")))
 
 				req := &proto.AddStoryRequest{
 					Story: &proto.Story{
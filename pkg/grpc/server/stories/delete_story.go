@@ -4,10 +4,11 @@ import (
 	"context"
 	"github.com/nsnikhil/stories-proto/proto"
 	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
 )
 
 func (ss *Server) DeleteStory(ctx context.Context, req *proto.DeleteStoryRequest) (*proto.DeleteStoryResponse, error) {
-	_, err := ss.svc.DeleteStory(req.GetStoryID())
+	_, err := ss.svc.DeleteStory(ctx, req.GetStoryID(), time.Time{})
 	if err != nil {
 		return &proto.DeleteStoryResponse{Success: false}, liberr.WithArgs(liberr.Operation("Server.DeleteStory"), err)
 	}
@@ -9,7 +9,9 @@ import (
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 func TestStoriesServerDeleteStory(t *testing.T) {
@@ -22,7 +24,7 @@ func TestStoriesServerDeleteStory(t *testing.T) {
 		"test delete story success": {
 			input: func() service.StoryService {
 				ms := &service.MockStoriesService{}
-				ms.On("DeleteStory", "adbca278-7e5c-4831-bf90-15fadfda0dd1").Return(int64(1), nil)
+				ms.On("DeleteStory", mock.Anything, "adbca278-7e5c-4831-bf90-15fadfda0dd1", time.Time{}).Return(int64(1), nil)
 				return ms
 			},
 			expectedResult: &proto.DeleteStoryResponse{
@@ -32,7 +34,7 @@ func TestStoriesServerDeleteStory(t *testing.T) {
 		"test delete story service failure": {
 			input: func() service.StoryService {
 				ms := &service.MockStoriesService{}
-				ms.On("DeleteStory", "adbca278-7e5c-4831-bf90-15fadfda0dd1").Return(int64(0), liberr.WithArgs(errors.New("failed to delete story")))
+				ms.On("DeleteStory", mock.Anything, "adbca278-7e5c-4831-bf90-15fadfda0dd1", time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to delete story")))
 				return ms
 			},
 			expectedResult: &proto.DeleteStoryResponse{
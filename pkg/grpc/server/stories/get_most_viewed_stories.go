@@ -7,7 +7,7 @@ import (
 )
 
 func (ss *Server) GetMostViewedStories(ctx context.Context, req *proto.MostViewedStoriesRequest) (*proto.MostViewedStoriesResponse, error) {
-	stories, err := ss.svc.GetMostViewsStories(int(req.GetOffset()), int(req.GetLimit()))
+	stories, err := ss.svc.GetMostViewsStories(ctx, int(req.GetOffset()), int(req.GetLimit()))
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("Server.GetMostViewedStories"), err)
 	}
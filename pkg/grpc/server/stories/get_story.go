@@ -7,7 +7,7 @@ import (
 )
 
 func (ss *Server) GetStory(ctx context.Context, req *proto.GetStoryRequest) (*proto.GetStoryResponse, error) {
-	st, err := ss.svc.GetStory(req.GetStoryID())
+	st, err := ss.svc.GetStory(ctx, req.GetStoryID())
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("Server.GetStory"), err)
 	}
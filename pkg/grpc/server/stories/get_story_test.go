@@ -10,6 +10,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
@@ -44,7 +45,7 @@ func TestStoriesServerGetStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetStory", id).Return(st, nil)
+				ms.On("GetStory", mock.Anything, id).Return(st, nil)
 
 				return ms
 			},
@@ -66,7 +67,7 @@ func TestStoriesServerGetStory(t *testing.T) {
 				id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetStory", id).Return(&model.Story{}, liberr.WithArgs(errors.New("failed to get story")))
+				ms.On("GetStory", mock.Anything, id).Return(&model.Story{}, liberr.WithArgs(errors.New("failed to get story")))
 
 				return ms
 			},
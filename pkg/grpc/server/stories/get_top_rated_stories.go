@@ -7,7 +7,7 @@ import (
 )
 
 func (ss *Server) GetTopRatedStories(ctx context.Context, req *proto.TopRatedStoriesRequest) (*proto.TopRatedStoriesResponse, error) {
-	stories, err := ss.svc.GetTopRatedStories(int(req.GetOffset()), int(req.GetLimit()))
+	stories, err := ss.svc.GetTopRatedStories(ctx, int(req.GetOffset()), int(req.GetLimit()))
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("Server.GetTopRatedStories"), err)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
@@ -42,7 +43,7 @@ func TestStoriesServerGetTopRatedStories(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetTopRatedStories", 0, 10).Return([]model.Story{*st}, nil)
+				ms.On("GetTopRatedStories", mock.Anything, 0, 10).Return([]model.Story{*st}, nil)
 
 				return ms
 			},
@@ -64,7 +65,7 @@ func TestStoriesServerGetTopRatedStories(t *testing.T) {
 		"test get top rated story failure": {
 			input: func() service.StoryService {
 				ms := &service.MockStoriesService{}
-				ms.On("GetTopRatedStories", 0, 10).Return([]model.Story{}, liberr.WithArgs(errors.New("failed to get top rated story")))
+				ms.On("GetTopRatedStories", mock.Anything, 0, 10).Return([]model.Story{}, liberr.WithArgs(errors.New("failed to get top rated story")))
 
 				return ms
 			},
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/nsnikhil/stories-proto/proto"
 	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
 )
 
 func (ss *Server) UpdateStory(ctx context.Context, req *proto.UpdateStoryRequest) (*proto.UpdateStoryResponse, error) {
@@ -12,7 +13,7 @@ func (ss *Server) UpdateStory(ctx context.Context, req *proto.UpdateStoryRequest
 		return &proto.UpdateStoryResponse{Success: false}, liberr.WithArgs(liberr.Operation("Server.UpdateStory"), err)
 	}
 
-	_, err = ss.svc.UpdateStory(st)
+	_, err = ss.svc.UpdateStory(ctx, st, time.Time{})
 	if err != nil {
 		return &proto.UpdateStoryResponse{Success: false}, liberr.WithArgs(liberr.Operation("Server.UpdateStory"), err)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"strings"
 	"testing"
@@ -43,7 +44,7 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("UpdateStory", st).Return(int64(1), nil)
+				ms.On("UpdateStory", mock.Anything, st, time.Time{}).Return(int64(1), nil)
 
 				req := &proto.UpdateStoryRequest{
 					Story: &proto.Story{
@@ -86,6 +87,8 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeInvalidStoryID,
+					liberr.NewFieldViolation("id", liberr.CodeInvalidStoryID, "invalid id: abc"),
 					errors.New("invalid id: abc"),
 				),
 			),
@@ -114,6 +117,8 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeTitleRequired,
+					liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"),
 					errors.New("title cannot be empty"),
 				),
 			),
@@ -142,6 +147,8 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeBodyRequired,
+					liberr.NewFieldViolation("body", liberr.CodeBodyRequired, "body cannot be empty"),
 					errors.New("body cannot be empty"),
 				),
 			),
@@ -175,6 +182,8 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeTitleTooLong,
+					liberr.NewFieldViolation("title", liberr.CodeTitleTooLong, "title max length exceeded"),
 					errors.New("title max length exceeded"),
 				),
 			),
@@ -208,6 +217,8 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 					liberr.SeverityError,
 					liberr.ValidationError,
 					liberr.Operation("StoryBuilder.Build"),
+					liberr.CodeBodyTooLong,
+					liberr.NewFieldViolation("body", liberr.CodeBodyTooLong, "body max length exceeded"),
 					errors.New("body max length exceeded"),
 				),
 			),
@@ -227,7 +238,7 @@ func TestStoriesServerUpdateStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("UpdateStory", st).Return(int64(0), liberr.WithArgs(errors.New("failed to update story")))
+				ms.On("UpdateStory", mock.Anything, st, time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to update story")))
 
 				req := &proto.UpdateStoryRequest{
 					Story: &proto.Story{
@@ -8,3 +8,7 @@ type AddStoryRequest struct {
 type AddStoryResponse struct {
 	Success bool `json:"success"`
 }
+
+type CreateStoryResponse struct {
+	ID string `json:"id"`
+}
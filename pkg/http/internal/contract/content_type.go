@@ -0,0 +1,8 @@
+package contract
+
+const (
+	JSONContentType     = "application/json"
+	ProblemContentType  = "application/problem+json"
+	MsgpackContentType  = "application/x-msgpack"
+	ProtobufContentType = "application/x-protobuf"
+)
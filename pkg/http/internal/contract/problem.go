@@ -0,0 +1,27 @@
+package contract
+
+// A PROBLEM OF TYPE about:blank HAS NO SEMANTICS BEYOND ITS STATUS, Code IDENTIFIES THE ERROR
+const problemTypeBlank = "about:blank"
+
+// Problem IS AN RFC 7807 PROBLEM DETAILS DOCUMENT EXTENDED WITH THE CODE AND FIELD VIOLATIONS OF THE ERROR
+type Problem struct {
+	Type       string           `json:"type"`
+	Title      string           `json:"title"`
+	Status     int              `json:"status"`
+	Detail     string           `json:"detail,omitempty"`
+	Instance   string           `json:"instance,omitempty"`
+	Code       string           `json:"code,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+func NewProblem(status int, title, detail, instance, code string, violations ...FieldViolation) Problem {
+	return Problem{
+		Type:       problemTypeBlank,
+		Title:      title,
+		Status:     status,
+		Detail:     detail,
+		Instance:   instance,
+		Code:       code,
+		Violations: violations,
+	}
+}
@@ -0,0 +1,145 @@
+package contract
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/nsnikhil/stories-proto/proto"
+)
+
+// THE CONTRACT TYPES WITH A stories-proto COUNTERPART CONVERT TO AND FROM ITS WIRE FORMAT,
+// THE OTHERS HAVE NO PROTOBUF REPRESENTATION
+
+func (s Story) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(s.toProto())
+}
+
+func (s *Story) UnmarshalProto(data []byte) error {
+	var m proto.Story
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*s = storyFromProto(&m)
+	return nil
+}
+
+func (r *AddStoryRequest) UnmarshalProto(data []byte) error {
+	var m proto.AddStoryRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.Title = m.GetStory().GetTitle()
+	r.Body = m.GetStory().GetBody()
+	return nil
+}
+
+func (r AddStoryResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.AddStoryResponse{Success: r.Success})
+}
+
+func (r *UpdateStoryRequest) UnmarshalProto(data []byte) error {
+	var m proto.UpdateStoryRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.Story = storyFromProto(m.GetStory())
+	return nil
+}
+
+func (r UpdateStoryResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.UpdateStoryResponse{Success: r.Success})
+}
+
+func (r *GetStoryRequest) UnmarshalProto(data []byte) error {
+	var m proto.GetStoryRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.StoryID = m.GetStoryID()
+	return nil
+}
+
+func (r GetStoryResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.GetStoryResponse{Story: r.Story.toProto()})
+}
+
+func (r *DeleteStoryRequest) UnmarshalProto(data []byte) error {
+	var m proto.DeleteStoryRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.StoryID = m.GetStoryID()
+	return nil
+}
+
+func (r DeleteStoryResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.DeleteStoryResponse{Success: r.Success})
+}
+
+func (r *MostViewedStoriesRequest) UnmarshalProto(data []byte) error {
+	var m proto.MostViewedStoriesRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.OffSet = int(m.GetOffset())
+	r.Limit = int(m.GetLimit())
+	return nil
+}
+
+func (r MostViewedStoriesResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.MostViewedStoriesResponse{Stories: storiesToProto(r.Stories)})
+}
+
+func (r *TopRatedStoriesRequest) UnmarshalProto(data []byte) error {
+	var m proto.TopRatedStoriesRequest
+	if err := protobuf.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	r.OffSet = int(m.GetOffset())
+	r.Limit = int(m.GetLimit())
+	return nil
+}
+
+func (r TopRatedStoriesResponse) MarshalProto() ([]byte, error) {
+	return protobuf.Marshal(&proto.TopRatedStoriesResponse{Stories: storiesToProto(r.Stories)})
+}
+
+func (s Story) toProto() *proto.Story {
+	return &proto.Story{
+		Id:            s.ID,
+		Title:         s.Title,
+		Body:          s.Body,
+		Views:         s.ViewCount,
+		UpVotes:       s.UpVotes,
+		DownVotes:     s.DownVotes,
+		CreatedAtUnix: s.CreatedAt,
+		UpdatedAtUnix: s.UpdatedAt,
+	}
+}
+
+func storyFromProto(m *proto.Story) Story {
+	return Story{
+		ID:        m.GetId(),
+		Title:     m.GetTitle(),
+		Body:      m.GetBody(),
+		ViewCount: m.GetViews(),
+		UpVotes:   m.GetUpVotes(),
+		DownVotes: m.GetDownVotes(),
+		CreatedAt: m.GetCreatedAtUnix(),
+		UpdatedAt: m.GetUpdatedAtUnix(),
+	}
+}
+
+func storiesToProto(stories []Story) []*proto.Story {
+	res := make([]*proto.Story, len(stories))
+	for i, s := range stories {
+		res[i] = s.toProto()
+	}
+
+	return res
+}
@@ -7,7 +7,15 @@ type APIResponse struct {
 }
 
 type Error struct {
-	Message string `json:"message,omitempty"`
+	Code       string           `json:"code,omitempty"`
+	Message    string           `json:"message,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 func NewSuccessResponse(data interface{}) APIResponse {
@@ -17,10 +25,12 @@ func NewSuccessResponse(data interface{}) APIResponse {
 	}
 }
 
-func NewFailureResponse(description string) APIResponse {
+func NewFailureResponse(code, description string, violations ...FieldViolation) APIResponse {
 	return APIResponse{
 		Error: &Error{
-			Message: description,
+			Code:       code,
+			Message:    description,
+			Violations: violations,
 		},
 		Success: false,
 	}
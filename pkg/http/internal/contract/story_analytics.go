@@ -0,0 +1,39 @@
+package contract
+
+type StoryTimeSeriesRequest struct {
+	StoryID string `json:"story_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type DailyStats struct {
+	Day       string `json:"day"`
+	Views     int64  `json:"views"`
+	UpVotes   int64  `json:"up_votes"`
+	DownVotes int64  `json:"down_votes"`
+}
+
+type StoryTimeSeriesResponse struct {
+	StoryID string       `json:"story_id"`
+	Series  []DailyStats `json:"series"`
+}
+
+type TopMoversRequest struct {
+	Metric       string `json:"metric"`
+	PreviousFrom string `json:"previous_from"`
+	PreviousTo   string `json:"previous_to"`
+	CurrentFrom  string `json:"current_from"`
+	CurrentTo    string `json:"current_to"`
+	Limit        int    `json:"limit"`
+}
+
+type Mover struct {
+	StoryID  string `json:"story_id"`
+	Previous int64  `json:"previous"`
+	Current  int64  `json:"current"`
+	Delta    int64  `json:"delta"`
+}
+
+type TopMoversResponse struct {
+	Movers []Mover `json:"movers"`
+}
@@ -0,0 +1,13 @@
+package contract
+
+type StoryCounterRequest struct {
+	StoryID string `json:"story_id"`
+}
+
+type StoryCounterResponse struct {
+	Success bool `json:"success"`
+}
+
+type VoteStoryRequest struct {
+	Direction string `json:"direction"`
+}
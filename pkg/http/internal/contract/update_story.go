@@ -7,3 +7,9 @@ type UpdateStoryRequest struct {
 type UpdateStoryResponse struct {
 	Success bool `json:"success"`
 }
+
+// A FIELD LEFT OUT OF THE REQUEST KEEPS ITS CURRENT VALUE
+type PatchStoryRequest struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"net/http"
+	"path"
 )
 
 type AddStoryHandler struct {
@@ -16,10 +17,34 @@ type AddStoryHandler struct {
 }
 
 func (ash *AddStoryHandler) AddStory(resp http.ResponseWriter, req *http.Request) error {
+	if _, err := ash.addStory(req); err != nil {
+		return liberr.WithArgs(liberr.Operation("AddStoryHandler.AddStory"), err)
+	}
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusCreated, contract.AddStoryResponse{Success: true}, resp, req)
+	return nil
+}
+
+// CreateStory POINTS THE Location HEADER AT THE NEW STORY
+func (ash *AddStoryHandler) CreateStory(resp http.ResponseWriter, req *http.Request) error {
+	st, err := ash.addStory(req)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("AddStoryHandler.CreateStory"), err)
+	}
+
+	resp.Header().Set("Location", path.Join(req.URL.Path, st.GetID()))
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusCreated, contract.CreateStoryResponse{ID: st.GetID()}, resp, req)
+	return nil
+}
+
+func (ash *AddStoryHandler) addStory(req *http.Request) (*model.Story, error) {
 	var data contract.AddStoryRequest
 	err := util.ParseRequest(req, &data)
 	if err != nil {
-		return liberr.WithArgs(liberr.Operation("AddStoryHandler.AddStory"), err)
+		return nil, err
 	}
 
 	st, err := model.NewStoryBuilder().
@@ -28,17 +53,14 @@ func (ash *AddStoryHandler) AddStory(resp http.ResponseWriter, req *http.Request
 		Build()
 
 	if err != nil {
-		return liberr.WithArgs(liberr.Operation("AddStoryHandler.AddStory"), err)
+		return nil, err
 	}
 
-	err = ash.svc.AddStory(st)
-	if err != nil {
-		return liberr.WithArgs(liberr.Operation("AddStoryHandler.AddStory"), err)
+	if err := ash.svc.AddStory(req.Context(), st); err != nil {
+		return nil, err
 	}
 
-	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusCreated, contract.AddStoryResponse{Success: true}, resp)
-	return nil
+	return st, nil
 }
 
 func NewAddHandler(cfg config.StoryConfig, svc service.StoryService) *AddStoryHandler {
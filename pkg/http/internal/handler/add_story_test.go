@@ -10,6 +10,7 @@ import (
 	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -29,7 +30,7 @@ func TestAddStory(t *testing.T) {
 		"test add story success": {
 			input: func() (service.StoryService, io.Reader) {
 				ms := &service.MockStoriesService{}
-				ms.On("AddStory", mock.AnythingOfType("*model.Story")).Return(nil)
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return(nil)
 
 				reqSt := contract.AddStoryRequest{Title: "title", Body: "test body"}
 				b, err := json.Marshal(&reqSt)
@@ -45,7 +46,7 @@ func TestAddStory(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test add story failure when body is empty": {
 			input: func() (service.StoryService, io.Reader) {
@@ -56,7 +57,7 @@ func TestAddStory(t *testing.T) {
 				return &service.MockStoriesService{}, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"body cannot be empty\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"BODY_REQUIRED\",\"message\":\"body cannot be empty\",\"violations\":[{\"field\":\"body\",\"code\":\"BODY_REQUIRED\",\"message\":\"body cannot be empty\"}]},\"success\":false}",
 		},
 		"test add story failure when title is empty": {
 			input: func() (service.StoryService, io.Reader) {
@@ -67,12 +68,12 @@ func TestAddStory(t *testing.T) {
 				return &service.MockStoriesService{}, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"title cannot be empty\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\",\"violations\":[{\"field\":\"title\",\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\"}]},\"success\":false}",
 		},
 		"test add story failure when svc call fails": {
 			input: func() (service.StoryService, io.Reader) {
 				ms := &service.MockStoriesService{}
-				ms.On("AddStory", mock.AnythingOfType("*model.Story")).Return(liberr.WithArgs(liberr.SeverityError, errors.New("failed to add story")))
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return(liberr.WithArgs(liberr.SeverityError, errors.New("failed to add story")))
 
 				reqSt := contract.AddStoryRequest{Title: "title", Body: "test body"}
 				b, err := json.Marshal(&reqSt)
@@ -81,7 +82,7 @@ func TestAddStory(t *testing.T) {
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
@@ -107,3 +108,62 @@ func testAddStoryHandler(t *testing.T, expectedCode int, expectedBody string, sv
 	assert.Equal(t, expectedCode, w.Code)
 	assert.Equal(t, expectedBody, w.Body.String())
 }
+
+func TestCreateStory(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	testCases := map[string]struct {
+		input            func() (service.StoryService, io.Reader)
+		expectedResult   string
+		expectedCode     int
+		expectedLocation string
+	}{
+		"test create story success": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Run(func(args mock.Arguments) {
+					args.Get(1).(*model.Story).ID = id
+				}).Return(nil)
+
+				b, err := json.Marshal(&contract.AddStoryRequest{Title: "title", Body: "test body"})
+				require.NoError(t, err)
+
+				return ms, bytes.NewBuffer(b)
+			},
+			expectedCode:     http.StatusCreated,
+			expectedResult:   "{\"data\":{\"id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\"},\"success\":true}",
+			expectedLocation: "/stories/adbca278-7e5c-4831-bf90-15fadfda0dd1",
+		},
+		"test create story failure when service call fails": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return(liberr.WithArgs(errors.New("failed to add story")))
+
+				b, err := json.Marshal(&contract.AddStoryRequest{Title: "title", Body: "test body"})
+				require.NoError(t, err)
+
+				return ms, bytes.NewBuffer(b)
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.NewConfig("../../../../local.env")
+
+			svc, body := testCase.input()
+			ah := handler.NewAddHandler(cfg.StoryConfig(), svc)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/stories", body)
+
+			mdl.WithError(reporters.NewLogger("dev", "debug"), ah.CreateStory)(w, r)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
@@ -19,13 +19,39 @@ func (dsh *DeleteStoryHandler) DeleteStory(resp http.ResponseWriter, req *http.R
 		return liberr.WithArgs(liberr.Operation("DeleteStoryHandler.DeleteStory"), err)
 	}
 
-	_, err = dsh.svc.DeleteStory(data.StoryID)
+	ifUpdatedAt, err := ifMatch(req, "DeleteStoryHandler.DeleteStory", data.StoryID)
+	if err != nil {
+		return err
+	}
+
+	_, err = dsh.svc.DeleteStory(req.Context(), data.StoryID, ifUpdatedAt)
 	if err != nil {
 		return liberr.WithArgs(liberr.Operation("DeleteStoryHandler.DeleteStory"), err)
 	}
 
 	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusOK, contract.DeleteStoryResponse{Success: true}, resp)
+	util.WriteSuccessResponse(http.StatusOK, contract.DeleteStoryResponse{Success: true}, resp, req)
+	return nil
+}
+
+func (dsh *DeleteStoryHandler) DeleteStoryByID(resp http.ResponseWriter, req *http.Request) error {
+	id, err := util.ParsePathParam(req, StoryIDParam)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("DeleteStoryHandler.DeleteStoryByID"), err)
+	}
+
+	ifUpdatedAt, err := ifMatch(req, "DeleteStoryHandler.DeleteStoryByID", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = dsh.svc.DeleteStory(req.Context(), id, ifUpdatedAt)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("DeleteStoryHandler.DeleteStoryByID"), err)
+	}
+
+	//TODO: ADD SUCCESS LOG
+	resp.WriteHeader(http.StatusNoContent)
 	return nil
 }
 
@@ -4,18 +4,23 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/go-chi/chi"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/handler"
 	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestDeleteStory(t *testing.T) {
@@ -34,7 +39,7 @@ func TestDeleteStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("DeleteStory", id).Return(int64(1), nil)
+				ms.On("DeleteStory", mock.Anything, id, time.Time{}).Return(int64(1), nil)
 
 				return ms, bytes.NewBuffer(b)
 			},
@@ -46,7 +51,7 @@ func TestDeleteStory(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test delete story failure when svc call fails": {
 			input: func() (service.StoryService, io.Reader) {
@@ -58,12 +63,12 @@ func TestDeleteStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("DeleteStory", id).Return(int64(0), liberr.WithArgs(liberr.SeverityError, errors.New("failed to delete story")))
+				ms.On("DeleteStory", mock.Anything, id, time.Time{}).Return(int64(0), liberr.WithArgs(liberr.SeverityError, errors.New("failed to delete story")))
 
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
@@ -87,3 +92,88 @@ func testDeleteStory(t *testing.T, expectedCode int, expectedBody string, svc se
 	assert.Equal(t, expectedCode, w.Code)
 	assert.Equal(t, expectedBody, w.Body.String())
 }
+
+func TestDeleteStoryByID(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	updatedAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+	etag := util.StoryETag(&model.Story{ID: id, UpdatedAt: updatedAt})
+
+	testCases := map[string]struct {
+		svc            func() service.StoryService
+		ifMatch        string
+		expectedResult string
+		expectedCode   int
+	}{
+		"test delete story by id success": {
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("DeleteStory", mock.Anything, id, time.Time{}).Return(int64(1), nil)
+
+				return ms
+			},
+			expectedCode:   http.StatusNoContent,
+			expectedResult: "",
+		},
+		"test delete story by id only deletes the version named by if match": {
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("DeleteStory", mock.Anything, id, updatedAt).Return(int64(1), nil)
+
+				return ms
+			},
+			ifMatch:        etag,
+			expectedCode:   http.StatusNoContent,
+			expectedResult: "",
+		},
+		"test delete story by id failure when the story has changed since it was read": {
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("DeleteStory", mock.Anything, id, updatedAt).Return(int64(0), liberr.WithArgs(liberr.PreconditionFailed, errors.New("story has changed since it was read")))
+
+				return ms
+			},
+			ifMatch:        etag,
+			expectedCode:   http.StatusPreconditionFailed,
+			expectedResult: "{\"error\":{\"code\":\"PRECONDITION_FAILED\",\"message\":\"story has changed since it was read\"},\"success\":false}",
+		},
+		"test delete story by id failure when if match is a weak etag": {
+			svc: func() service.StoryService {
+				return &service.MockStoriesService{}
+			},
+			ifMatch:        "W/" + etag,
+			expectedCode:   http.StatusPreconditionFailed,
+			expectedResult: "{\"error\":{\"code\":\"PRECONDITION_FAILED\",\"message\":\"story has changed since it was read\"},\"success\":false}",
+		},
+		"test delete story by id failure when service call fails": {
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("DeleteStory", mock.Anything, id, time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to delete story")))
+
+				return ms
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dh := handler.NewDeleteStoryHandler(testCase.svc())
+
+			r := chi.NewRouter()
+			r.Delete("/stories/{id}", mdl.WithError(reporters.NewLogger("dev", "debug"), dh.DeleteStoryByID))
+
+			req := httptest.NewRequest(http.MethodDelete, "/stories/"+id, nil)
+			if len(testCase.ifMatch) != 0 {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
@@ -19,7 +19,7 @@ func (gmh *GetMostViewedStoriesHandler) GetMostViewedStories(resp http.ResponseW
 		return liberr.WithArgs(liberr.Operation("GetMostViewedStoriesHandler.GetMostViewedStories"), err)
 	}
 
-	dss, err := gmh.svc.GetMostViewsStories(data.OffSet, data.Limit)
+	dss, err := gmh.svc.GetMostViewsStories(req.Context(), data.OffSet, data.Limit)
 	if err != nil {
 		return liberr.WithArgs(liberr.Operation("GetMostViewedStoriesHandler.GetMostViewedStories"), err)
 	}
@@ -33,7 +33,7 @@ func (gmh *GetMostViewedStoriesHandler) GetMostViewedStories(resp http.ResponseW
 	}
 
 	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusOK, res, resp)
+	util.WriteSuccessResponse(http.StatusOK, res, resp, req)
 	return nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
@@ -51,7 +52,7 @@ func TestGetMostViewedStories(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetMostViewsStories", o, l).Return([]model.Story{*st}, nil)
+				ms.On("GetMostViewsStories", mock.Anything, o, l).Return([]model.Story{*st}, nil)
 
 				return ms, bytes.NewBuffer(b)
 			},
@@ -63,7 +64,7 @@ func TestGetMostViewedStories(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test get most viewed stories failure when svc call fails": {
 			input: func() (service.StoryService, io.Reader) {
@@ -74,12 +75,12 @@ func TestGetMostViewedStories(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetMostViewsStories", o, l).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get most viewed stories")))
+				ms.On("GetMostViewsStories", mock.Anything, o, l).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get most viewed stories")))
 
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
@@ -19,13 +19,35 @@ func (gs *GetStoryHandler) GetStory(resp http.ResponseWriter, req *http.Request)
 		return liberr.WithArgs(liberr.Operation("GetStoryHandler.GetStory"), err)
 	}
 
-	st, err := gs.svc.GetStory(data.StoryID)
+	return gs.getStory(resp, req, "GetStoryHandler.GetStory", data.StoryID)
+}
+
+func (gs *GetStoryHandler) GetStoryByID(resp http.ResponseWriter, req *http.Request) error {
+	id, err := util.ParsePathParam(req, StoryIDParam)
 	if err != nil {
-		return liberr.WithArgs(liberr.Operation("GetStoryHandler.GetStory"), err)
+		return liberr.WithArgs(liberr.Operation("GetStoryHandler.GetStoryByID"), err)
+	}
+
+	return gs.getStory(resp, req, "GetStoryHandler.GetStoryByID", id)
+}
+
+// getStory ANSWERS 304 WHEN THE If-None-Match OF THE REQUEST NAMES THE CURRENT ETAG OF THE STORY
+func (gs *GetStoryHandler) getStory(resp http.ResponseWriter, req *http.Request, op string, id string) error {
+	st, err := gs.svc.GetStory(req.Context(), id)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation(op), err)
+	}
+
+	etag := util.StoryETag(st)
+	resp.Header().Set("ETag", etag)
+
+	if util.NoneMatch(req, etag) {
+		resp.WriteHeader(http.StatusNotModified)
+		return nil
 	}
 
 	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusOK, util.ConvertToDTO(st), resp)
+	util.WriteSuccessResponse(http.StatusOK, util.ConvertToDTO(st), resp, req)
 	return nil
 }
 
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/go-chi/chi"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/handler"
 	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
@@ -48,7 +51,7 @@ func TestGetStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetStory", id).Return(ds, nil)
+				ms.On("GetStory", mock.Anything, id).Return(ds, nil)
 
 				gtReq := contract.GetStoryRequest{StoryID: id}
 				b, err := json.Marshal(gtReq)
@@ -64,14 +67,14 @@ func TestGetStory(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test get story failure when service calls fails": {
 			input: func() (service.StoryService, io.Reader) {
 				id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetStory", id).Return(&model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get story")))
+				ms.On("GetStory", mock.Anything, id).Return(&model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get story")))
 
 				gtReq := contract.GetStoryRequest{StoryID: id}
 				b, err := json.Marshal(gtReq)
@@ -80,7 +83,7 @@ func TestGetStory(t *testing.T) {
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
@@ -103,3 +106,128 @@ func testGetStory(t *testing.T, expectedCode int, expectedBody string, svc servi
 	assert.Equal(t, expectedCode, w.Code)
 	assert.Equal(t, expectedBody, w.Body.String())
 }
+
+func TestGetStoryByID(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	testCases := map[string]struct {
+		svc            func() service.StoryService
+		expectedResult string
+		expectedCode   int
+	}{
+		"test get story by id success": {
+			svc: func() service.StoryService {
+				createdAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+
+				ds, err := model.NewStoryBuilder().
+					SetID(id).
+					SetTitle(100, "title").
+					SetBody(100, "test body").
+					SetCreatedAt(createdAt).
+					SetUpdatedAt(createdAt).
+					Build()
+
+				require.NoError(t, err)
+
+				ms := &service.MockStoriesService{}
+				ms.On("GetStory", mock.Anything, id).Return(ds, nil)
+
+				return ms
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: "{\"data\":{\"id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\",\"title\":\"title\",\"body\":\"test body\",\"view_count\":0,\"up_votes\":0,\"down_votes\":0,\"created_at\":1596038400,\"updated_at\":1596038400},\"success\":true}",
+		},
+		"test get story by id failure when story does not exist": {
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("GetStory", mock.Anything, id).Return(&model.Story{}, liberr.WithArgs(liberr.ResourceNotFound, errors.New("no records found")))
+
+				return ms
+			},
+			expectedCode:   http.StatusNotFound,
+			expectedResult: "{\"error\":{\"code\":\"RESOURCE_NOT_FOUND\",\"message\":\"requested resource was not found\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gh := handler.NewGetStoryHandler(testCase.svc())
+
+			r := chi.NewRouter()
+			r.Get("/stories/{id}", mdl.WithError(reporters.NewLogger("dev", "debug"), gh.GetStoryByID))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stories/"+id, nil))
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
+
+func TestGetStoryByIDConditional(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+	createdAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+
+	ds, err := model.NewStoryBuilder().
+		SetID(id).
+		SetTitle(100, "title").
+		SetBody(100, "test body").
+		SetCreatedAt(createdAt).
+		SetUpdatedAt(createdAt).
+		Build()
+
+	require.NoError(t, err)
+
+	etag := util.StoryETag(ds)
+
+	testCases := map[string]struct {
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		"test get story by id without if none match": {
+			expectedCode: http.StatusOK,
+		},
+		"test get story by id when if none match names the current etag": {
+			ifNoneMatch:  "\"other\", " + etag,
+			expectedCode: http.StatusNotModified,
+		},
+		"test get story by id when if none match names the current etag as weak": {
+			ifNoneMatch:  "W/" + etag,
+			expectedCode: http.StatusNotModified,
+		},
+		"test get story by id when if none match is any": {
+			ifNoneMatch:  "*",
+			expectedCode: http.StatusNotModified,
+		},
+		"test get story by id when if none match names an old etag": {
+			ifNoneMatch:  "\"other\"",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ms := &service.MockStoriesService{}
+			ms.On("GetStory", mock.Anything, id).Return(ds, nil)
+
+			gh := handler.NewGetStoryHandler(ms)
+
+			r := chi.NewRouter()
+			r.Get("/stories/{id}", mdl.WithError(reporters.NewLogger("dev", "debug"), gh.GetStoryByID))
+
+			req := httptest.NewRequest(http.MethodGet, "/stories/"+id, nil)
+			req.Header.Set("If-None-Match", testCase.ifNoneMatch)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+
+			if testCase.expectedCode == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"net/http"
+)
+
+type GetStoryTimeSeriesHandler struct {
+	svc service.StoryService
+}
+
+func (gth *GetStoryTimeSeriesHandler) GetStoryTimeSeries(resp http.ResponseWriter, req *http.Request) error {
+	var data contract.StoryTimeSeriesRequest
+	err := util.ParseRequest(req, &data)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetStoryTimeSeriesHandler.GetStoryTimeSeries"), err)
+	}
+
+	from, err := util.ConvertToDay(data.From)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetStoryTimeSeriesHandler.GetStoryTimeSeries"), err)
+	}
+
+	to, err := util.ConvertToDay(data.To)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetStoryTimeSeriesHandler.GetStoryTimeSeries"), err)
+	}
+
+	series, err := gth.svc.GetStoryTimeSeries(req.Context(), data.StoryID, from, to)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetStoryTimeSeriesHandler.GetStoryTimeSeries"), err)
+	}
+
+	sz := len(series)
+	res := make([]contract.DailyStats, sz)
+
+	for i := 0; i < sz; i++ {
+		res[i] = util.ConvertDailyStatsToDTO(&series[i])
+	}
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusOK, contract.StoryTimeSeriesResponse{StoryID: data.StoryID, Series: res}, resp, req)
+	return nil
+}
+
+func NewGetStoryTimeSeriesHandler(svc service.StoryService) *GetStoryTimeSeriesHandler {
+	return &GetStoryTimeSeriesHandler{
+		svc: svc,
+	}
+}
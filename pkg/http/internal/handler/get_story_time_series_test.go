@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/handler"
+	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetStoryTimeSeries(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	from := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	reqBody := func(from, to string) io.Reader {
+		b, err := json.Marshal(contract.StoryTimeSeriesRequest{StoryID: id, From: from, To: to})
+		require.NoError(t, err)
+
+		return bytes.NewBuffer(b)
+	}
+
+	testCases := map[string]struct {
+		input          func() (service.StoryService, io.Reader)
+		expectedResult string
+		expectedCode   int
+	}{
+		"test get story time series success": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("GetStoryTimeSeries", mock.Anything, id, from, to).Return([]model.DailyStats{
+					{StoryID: id, Day: from, Views: 10, UpVotes: 2, DownVotes: 1},
+					{StoryID: id, Day: to},
+				}, nil)
+
+				return ms, reqBody("2020-09-01", "2020-09-02")
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: "{\"data\":{\"story_id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\",\"series\":[{\"day\":\"2020-09-01\",\"views\":10,\"up_votes\":2,\"down_votes\":1},{\"day\":\"2020-09-02\",\"views\":0,\"up_votes\":0,\"down_votes\":0}]},\"success\":true}",
+		},
+		"test get story time series failure when req body is nil": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, nil
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+		},
+		"test get story time series failure when day is invalid": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, reqBody("2020-09-01", "tomorrow")
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"parsing time \\\"tomorrow\\\" as \\\"2006-01-02\\\": cannot parse \\\"tomorrow\\\" as \\\"2006\\\"\"},\"success\":false}",
+		},
+		"test get story time series failure when svc call fails": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("GetStoryTimeSeries", mock.Anything, id, from, to).Return([]model.DailyStats{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get time series")))
+
+				return ms, reqBody("2020-09-01", "2020-09-02")
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			svc, body := testCase.input()
+			testGetStoryTimeSeries(t, testCase.expectedCode, testCase.expectedResult, svc, body)
+		})
+	}
+}
+
+func testGetStoryTimeSeries(t *testing.T, expectedCode int, expectedBody string, svc service.StoryService, body io.Reader) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/story/analytics/time-series", body)
+
+	th := handler.NewGetStoryTimeSeriesHandler(svc)
+
+	mdl.WithError(reporters.NewLogger("dev", "debug"), th.GetStoryTimeSeries)(w, r)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, w.Body.String())
+}
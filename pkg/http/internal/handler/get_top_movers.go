@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"net/http"
+	"time"
+)
+
+type GetTopMoversHandler struct {
+	svc service.StoryService
+}
+
+func (gmh *GetTopMoversHandler) GetTopMovers(resp http.ResponseWriter, req *http.Request) error {
+	var data contract.TopMoversRequest
+	err := util.ParseRequest(req, &data)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetTopMoversHandler.GetTopMovers"), err)
+	}
+
+	days := make([]time.Time, 4)
+	for i, day := range []string{data.PreviousFrom, data.PreviousTo, data.CurrentFrom, data.CurrentTo} {
+		days[i], err = util.ConvertToDay(day)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("GetTopMoversHandler.GetTopMovers"), err)
+		}
+	}
+
+	movers, err := gmh.svc.GetTopMovers(req.Context(), model.Metric(data.Metric), days[0], days[1], days[2], days[3], data.Limit)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("GetTopMoversHandler.GetTopMovers"), err)
+	}
+
+	sz := len(movers)
+	res := make([]contract.Mover, sz)
+
+	for i := 0; i < sz; i++ {
+		res[i] = util.ConvertMoverToDTO(&movers[i])
+	}
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusOK, contract.TopMoversResponse{Movers: res}, resp, req)
+	return nil
+}
+
+func NewGetTopMoversHandler(svc service.StoryService) *GetTopMoversHandler {
+	return &GetTopMoversHandler{
+		svc: svc,
+	}
+}
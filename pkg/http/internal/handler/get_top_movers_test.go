@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/handler"
+	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetTopMovers(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, 9, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	reqBody := func(previousFrom string) io.Reader {
+		b, err := json.Marshal(contract.TopMoversRequest{
+			Metric:       "up_votes",
+			PreviousFrom: previousFrom,
+			PreviousTo:   "2020-09-07",
+			CurrentFrom:  "2020-09-08",
+			CurrentTo:    "2020-09-14",
+			Limit:        5,
+		})
+		require.NoError(t, err)
+
+		return bytes.NewBuffer(b)
+	}
+
+	testCases := map[string]struct {
+		input          func() (service.StoryService, io.Reader)
+		expectedResult string
+		expectedCode   int
+	}{
+		"test get top movers success": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("GetTopMovers", mock.Anything, model.MetricUpVotes, day(1), day(7), day(8), day(14), 5).Return([]model.Mover{
+					{StoryID: "adbca278-7e5c-4831-bf90-15fadfda0dd1", Previous: 3, Current: 12},
+				}, nil)
+
+				return ms, reqBody("2020-09-01")
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: "{\"data\":{\"movers\":[{\"story_id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\",\"previous\":3,\"current\":12,\"delta\":9}]},\"success\":true}",
+		},
+		"test get top movers failure when req body is nil": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, nil
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+		},
+		"test get top movers failure when day is invalid": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, reqBody("")
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"parsing time \\\"\\\" as \\\"2006-01-02\\\": cannot parse \\\"\\\" as \\\"2006\\\"\"},\"success\":false}",
+		},
+		"test get top movers failure when svc call fails": {
+			input: func() (service.StoryService, io.Reader) {
+				ms := &service.MockStoriesService{}
+				ms.On("GetTopMovers", mock.Anything, model.MetricUpVotes, day(1), day(7), day(8), day(14), 5).Return([]model.Mover{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get top movers")))
+
+				return ms, reqBody("2020-09-01")
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			svc, body := testCase.input()
+			testGetTopMovers(t, testCase.expectedCode, testCase.expectedResult, svc, body)
+		})
+	}
+}
+
+func testGetTopMovers(t *testing.T, expectedCode int, expectedBody string, svc service.StoryService, body io.Reader) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/story/analytics/top-movers", body)
+
+	mh := handler.NewGetTopMoversHandler(svc)
+
+	mdl.WithError(reporters.NewLogger("dev", "debug"), mh.GetTopMovers)(w, r)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, w.Body.String())
+}
@@ -19,7 +19,7 @@ func (gmh *GetTopRatedStoriesHandler) GetTopRatedStories(resp http.ResponseWrite
 		return liberr.WithArgs(liberr.Operation("GetTopRatedStoriesHandler.GetTopRatedStories"), err)
 	}
 
-	dss, err := gmh.svc.GetTopRatedStories(data.OffSet, data.Limit)
+	dss, err := gmh.svc.GetTopRatedStories(req.Context(), data.OffSet, data.Limit)
 	if err != nil {
 		return liberr.WithArgs(liberr.Operation("GetTopRatedStoriesHandler.GetTopRatedStories"), err)
 	}
@@ -33,7 +33,7 @@ func (gmh *GetTopRatedStoriesHandler) GetTopRatedStories(resp http.ResponseWrite
 	}
 
 	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusOK, res, resp)
+	util.WriteSuccessResponse(http.StatusOK, res, resp, req)
 	return nil
 }
 
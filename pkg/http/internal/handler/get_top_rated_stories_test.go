@@ -12,6 +12,7 @@ import (
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
@@ -50,7 +51,7 @@ func TestGetTopRatedStories(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetTopRatedStories", o, l).Return([]model.Story{*st}, nil)
+				ms.On("GetTopRatedStories", mock.Anything, o, l).Return([]model.Story{*st}, nil)
 
 				return ms, bytes.NewBuffer(b)
 			},
@@ -62,7 +63,7 @@ func TestGetTopRatedStories(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test get top rated stories failure when svc call fails": {
 			input: func() (service.StoryService, io.Reader) {
@@ -73,12 +74,12 @@ func TestGetTopRatedStories(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("GetTopRatedStories", o, l).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get top rated stories")))
+				ms.On("GetTopRatedStories", mock.Anything, o, l).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get top rated stories")))
 
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
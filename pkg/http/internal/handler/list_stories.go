@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"net/http"
+)
+
+const (
+	sortParam   = "sort"
+	offsetParam = "offset"
+	limitParam  = "limit"
+
+	sortByViews  = "views"
+	sortByRating = "rating"
+
+	defaultListLimit = 10
+
+	// maxListLimit KEEPS A SINGLE PAGE FROM READING THE WHOLE TABLE
+	maxListLimit = 100
+)
+
+type ListStoriesHandler struct {
+	svc service.StoryService
+}
+
+// ListStories READS ?sort=views|rating&offset=&limit= AND DEFAULTS TO THE FIRST PAGE OF THE MOST VIEWED STORIES
+func (lsh *ListStoriesHandler) ListStories(resp http.ResponseWriter, req *http.Request) error {
+	sort := req.URL.Query().Get(sortParam)
+	if len(sort) == 0 {
+		sort = sortByViews
+	}
+
+	list, ok := map[string]func(ctx context.Context, offset, limit int) ([]model.Story, error){
+		sortByViews:  lsh.svc.GetMostViewsStories,
+		sortByRating: lsh.svc.GetTopRatedStories,
+	}[sort]
+
+	if !ok {
+		msg := fmt.Sprintf("invalid sort %s, expected one of %s, %s", sort, sortByViews, sortByRating)
+		return liberr.WithArgs(liberr.Operation("ListStoriesHandler.ListStories"), liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, liberr.NewFieldViolation(sortParam, liberr.CodeInvalidParameter, msg), errors.New(msg))
+	}
+
+	offset, err := util.ParseQueryInt(req, offsetParam, 0)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("ListStoriesHandler.ListStories"), err)
+	}
+
+	limit, err := util.ParseQueryInt(req, limitParam, defaultListLimit)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("ListStoriesHandler.ListStories"), err)
+	}
+
+	var violations []liberr.FieldViolation
+	if offset < 0 {
+		violations = append(violations, liberr.NewFieldViolation(offsetParam, liberr.CodeInvalidParameter, "offset cannot be negative"))
+	}
+
+	if limit < 1 {
+		violations = append(violations, liberr.NewFieldViolation(limitParam, liberr.CodeInvalidParameter, "limit must be positive"))
+	} else if limit > maxListLimit {
+		violations = append(violations, liberr.NewFieldViolation(limitParam, liberr.CodeInvalidParameter, fmt.Sprintf("limit cannot exceed %d", maxListLimit)))
+	}
+
+	if len(violations) != 0 {
+		return liberr.WithArgs(liberr.Operation("ListStoriesHandler.ListStories"), liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, violations, fmt.Errorf("invalid page offset %d limit %d", offset, limit))
+	}
+
+	dss, err := list(req.Context(), offset, limit)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("ListStoriesHandler.ListStories"), err)
+	}
+
+	res := make([]contract.Story, len(dss))
+	for i := range dss {
+		res[i] = util.ConvertToDTO(&dss[i])
+	}
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusOK, res, resp, req)
+	return nil
+}
+
+func NewListStoriesHandler(svc service.StoryService) *ListStoriesHandler {
+	return &ListStoriesHandler{
+		svc: svc,
+	}
+}
@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/handler"
+	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListStories(t *testing.T) {
+	story := func() model.Story {
+		createdAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+
+		st, err := model.NewStoryBuilder().
+			SetID("adbca278-7e5c-4831-bf90-15fadfda0dd1").
+			SetTitle(10, "title").
+			SetBody(10, "test body").
+			SetViewCount(25).
+			SetUpVotes(10).
+			SetDownVotes(2).
+			SetCreatedAt(createdAt).
+			SetUpdatedAt(createdAt).
+			Build()
+
+		require.NoError(t, err)
+		return *st
+	}
+
+	listResult := "{\"data\":[{\"id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\",\"title\":\"title\",\"body\":\"test body\",\"view_count\":25,\"up_votes\":10,\"down_votes\":2,\"created_at\":1596038400,\"updated_at\":1596038400}],\"success\":true}"
+
+	testCases := map[string]struct {
+		query          string
+		svc            func() service.StoryService
+		expectedResult string
+		expectedCode   int
+	}{
+		"test list stories defaults to the most viewed stories": {
+			query: "",
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("GetMostViewsStories", mock.Anything, 0, 10).Return([]model.Story{story()}, nil)
+
+				return ms
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: listResult,
+		},
+		"test list stories sorted by rating": {
+			query: "?sort=rating&offset=5&limit=1",
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("GetTopRatedStories", mock.Anything, 5, 1).Return([]model.Story{story()}, nil)
+
+				return ms
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: listResult,
+		},
+		"test list stories failure when sort is unknown": {
+			query: "?sort=title",
+			svc: func() service.StoryService {
+				return &service.MockStoriesService{}
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid sort title, expected one of views, rating\",\"violations\":[{\"field\":\"sort\",\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid sort title, expected one of views, rating\"}]},\"success\":false}",
+		},
+		"test list stories failure when limit is not an integer": {
+			query: "?limit=ten",
+			svc: func() service.StoryService {
+				return &service.MockStoriesService{}
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid limit ten, expected an integer\",\"violations\":[{\"field\":\"limit\",\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid limit ten, expected an integer\"}]},\"success\":false}",
+		},
+		"test list stories failure when limit is zero": {
+			query: "?limit=0",
+			svc: func() service.StoryService {
+				return &service.MockStoriesService{}
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid page offset 0 limit 0\",\"violations\":[{\"field\":\"limit\",\"code\":\"INVALID_PARAMETER\",\"message\":\"limit must be positive\"}]},\"success\":false}",
+		},
+		"test list stories failure when limit is too large": {
+			query: "?limit=100000000",
+			svc: func() service.StoryService {
+				return &service.MockStoriesService{}
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid page offset 0 limit 100000000\",\"violations\":[{\"field\":\"limit\",\"code\":\"INVALID_PARAMETER\",\"message\":\"limit cannot exceed 100\"}]},\"success\":false}",
+		},
+		"test list stories failure when service call fails": {
+			query: "?sort=views",
+			svc: func() service.StoryService {
+				ms := &service.MockStoriesService{}
+				ms.On("GetMostViewsStories", mock.Anything, 0, 10).Return([]model.Story{}, liberr.WithArgs(errors.New("failed to get stories")))
+
+				return ms
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			lh := handler.NewListStoriesHandler(testCase.svc())
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/stories"+testCase.query, nil)
+
+			mdl.WithError(reporters.NewLogger("dev", "debug"), lh.ListStories)(w, r)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
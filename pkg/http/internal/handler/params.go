@@ -0,0 +1,4 @@
+package handler
+
+// StoryIDParam IS THE PATH PARAMETER THE RESOURCE ROUTES CARRY THE STORY ID IN, AS IN /stories/{id}
+const StoryIDParam = "id"
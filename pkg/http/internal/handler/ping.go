@@ -7,6 +7,6 @@ import (
 
 func PingHandler() http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
-		util.WriteSuccessResponse(http.StatusOK, "pong", resp)
+		util.WriteSuccessResponse(http.StatusOK, "pong", resp, req)
 	}
 }
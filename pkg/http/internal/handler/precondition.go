@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"net/http"
+	"time"
+)
+
+// ifMatch RETURNS THE UPDATE TIME THE WRITE IS CONDITIONAL ON, THE STORE COMPARES IT IN THE WRITE ITSELF SO OF TWO
+// WRITERS HOLDING THE SAME ETAG ONLY ONE SUCCEEDS. AN If-Match THAT CANNOT NAME THE STORY FAILS WITHOUT A WRITE
+func ifMatch(req *http.Request, op string, id string) (time.Time, error) {
+	updatedAt, ok := util.IfMatch(req, id)
+	if !ok {
+		return time.Time{}, liberr.WithArgs(liberr.Operation(op), liberr.PreconditionFailed, liberr.SeverityError, errors.New("story has changed since it was read"))
+	}
+
+	return updatedAt, nil
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"net/http"
+)
+
+const (
+	directionField = "direction"
+
+	upVote   = "up"
+	downVote = "down"
+)
+
+// StoryCountersHandler ANSWERS WITH 202 SINCE THE COUNTERS ARE WRITTEN BEHIND
+type StoryCountersHandler struct {
+	svc service.StoryService
+}
+
+func (sch *StoryCountersHandler) ViewStory(resp http.ResponseWriter, req *http.Request) error {
+	return sch.increment(resp, req, "StoryCountersHandler.ViewStory", sch.svc.ViewStory)
+}
+
+func (sch *StoryCountersHandler) UpVoteStory(resp http.ResponseWriter, req *http.Request) error {
+	return sch.increment(resp, req, "StoryCountersHandler.UpVoteStory", sch.svc.UpVoteStory)
+}
+
+func (sch *StoryCountersHandler) DownVoteStory(resp http.ResponseWriter, req *http.Request) error {
+	return sch.increment(resp, req, "StoryCountersHandler.DownVoteStory", sch.svc.DownVoteStory)
+}
+
+func (sch *StoryCountersHandler) ViewStoryByID(resp http.ResponseWriter, req *http.Request) error {
+	id, err := util.ParsePathParam(req, StoryIDParam)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoryCountersHandler.ViewStoryByID"), err)
+	}
+
+	return sch.count(resp, req, "StoryCountersHandler.ViewStoryByID", id, sch.svc.ViewStory)
+}
+
+// VoteStory READS THE DIRECTION OF THE VOTE, up OR down, FROM THE BODY AND THE STORY FROM THE PATH
+func (sch *StoryCountersHandler) VoteStory(resp http.ResponseWriter, req *http.Request) error {
+	id, err := util.ParsePathParam(req, StoryIDParam)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoryCountersHandler.VoteStory"), err)
+	}
+
+	var data contract.VoteStoryRequest
+	err = util.ParseRequest(req, &data)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoryCountersHandler.VoteStory"), err)
+	}
+
+	vote, ok := map[string]func(ctx context.Context, storyID string) error{
+		upVote:   sch.svc.UpVoteStory,
+		downVote: sch.svc.DownVoteStory,
+	}[data.Direction]
+
+	if !ok {
+		msg := fmt.Sprintf("invalid direction %s, expected one of %s, %s", data.Direction, upVote, downVote)
+		return liberr.WithArgs(liberr.Operation("StoryCountersHandler.VoteStory"), liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, liberr.NewFieldViolation(directionField, liberr.CodeInvalidParameter, msg), errors.New(msg))
+	}
+
+	return sch.count(resp, req, "StoryCountersHandler.VoteStory", id, vote)
+}
+
+func (sch *StoryCountersHandler) increment(resp http.ResponseWriter, req *http.Request, op string, fn func(ctx context.Context, storyID string) error) error {
+	var data contract.StoryCounterRequest
+	err := util.ParseRequest(req, &data)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation(op), err)
+	}
+
+	return sch.count(resp, req, op, data.StoryID, fn)
+}
+
+func (sch *StoryCountersHandler) count(resp http.ResponseWriter, req *http.Request, op, storyID string, fn func(ctx context.Context, storyID string) error) error {
+	err := fn(req.Context(), storyID)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation(op), err)
+	}
+
+	util.WriteSuccessResponse(http.StatusAccepted, contract.StoryCounterResponse{Success: true}, resp, req)
+	return nil
+}
+
+func NewStoryCountersHandler(svc service.StoryService) *StoryCountersHandler {
+	return &StoryCountersHandler{
+		svc: svc,
+	}
+}
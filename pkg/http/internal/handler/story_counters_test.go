@@ -0,0 +1,191 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/handler"
+	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStoryCounters(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	body := func() io.Reader {
+		b, err := json.Marshal(contract.StoryCounterRequest{StoryID: id})
+		require.NoError(t, err)
+
+		return bytes.NewBuffer(b)
+	}
+
+	type handlerFunc func(sch *handler.StoryCountersHandler) func(resp http.ResponseWriter, req *http.Request) error
+
+	view := func(sch *handler.StoryCountersHandler) func(resp http.ResponseWriter, req *http.Request) error {
+		return sch.ViewStory
+	}
+
+	upVote := func(sch *handler.StoryCountersHandler) func(resp http.ResponseWriter, req *http.Request) error {
+		return sch.UpVoteStory
+	}
+
+	downVote := func(sch *handler.StoryCountersHandler) func(resp http.ResponseWriter, req *http.Request) error {
+		return sch.DownVoteStory
+	}
+
+	testCases := map[string]struct {
+		method         string
+		handler        handlerFunc
+		body           io.Reader
+		err            error
+		expectedCode   int
+		expectedResult string
+	}{
+		"test view story success": {
+			method:         "ViewStory",
+			handler:        view,
+			body:           body(),
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test up vote story success": {
+			method:         "UpVoteStory",
+			handler:        upVote,
+			body:           body(),
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test down vote story success": {
+			method:         "DownVoteStory",
+			handler:        downVote,
+			body:           body(),
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test view story failure when req body is nil": {
+			handler:        view,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+		},
+		"test up vote story failure when svc call fails": {
+			method:         "UpVoteStory",
+			handler:        upVote,
+			body:           body(),
+			err:            liberr.WithArgs(liberr.ValidationError, errors.New("invalid uuid abc")),
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"invalid uuid abc\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ms := &service.MockStoriesService{}
+			if testCase.method != "" {
+				ms.On(testCase.method, mock.Anything, id).Return(testCase.err)
+			}
+
+			sch := handler.NewStoryCountersHandler(ms)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/story/view", testCase.body)
+
+			mdl.WithError(reporters.NewLogger("dev", "debug"), testCase.handler(sch))(w, r)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
+
+func TestStoryCountersByID(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
+	vote := func(direction string) io.Reader {
+		b, err := json.Marshal(contract.VoteStoryRequest{Direction: direction})
+		require.NoError(t, err)
+
+		return bytes.NewBuffer(b)
+	}
+
+	testCases := map[string]struct {
+		path           string
+		method         string
+		body           io.Reader
+		err            error
+		expectedCode   int
+		expectedResult string
+	}{
+		"test view story by id success": {
+			path:           "/views",
+			method:         "ViewStory",
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test vote story up success": {
+			path:           "/votes",
+			method:         "UpVoteStory",
+			body:           vote("up"),
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test vote story down success": {
+			path:           "/votes",
+			method:         "DownVoteStory",
+			body:           vote("down"),
+			expectedCode:   http.StatusAccepted,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test vote story failure when req body is nil": {
+			path:           "/votes",
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+		},
+		"test vote story failure when direction is invalid": {
+			path:           "/votes",
+			body:           vote("sideways"),
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid direction sideways, expected one of up, down\",\"violations\":[{\"field\":\"direction\",\"code\":\"INVALID_PARAMETER\",\"message\":\"invalid direction sideways, expected one of up, down\"}]},\"success\":false}",
+		},
+		"test view story by id failure when svc call fails": {
+			path:           "/views",
+			method:         "ViewStory",
+			err:            liberr.WithArgs(liberr.ValidationError, errors.New("invalid uuid abc")),
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"invalid uuid abc\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ms := &service.MockStoriesService{}
+			if testCase.method != "" {
+				ms.On(testCase.method, mock.Anything, id).Return(testCase.err)
+			}
+
+			sch := handler.NewStoryCountersHandler(ms)
+			lgr := reporters.NewLogger("dev", "debug")
+
+			r := chi.NewRouter()
+			r.Post("/stories/{id}/views", mdl.WithError(lgr, sch.ViewStoryByID))
+			r.Post("/stories/{id}/votes", mdl.WithError(lgr, sch.VoteStory))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stories/"+id+testCase.path, testCase.body))
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+			ms.AssertExpectations(t)
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"net/http"
 )
@@ -26,13 +28,65 @@ func (ush *UpdateStoryHandler) UpdateStory(resp http.ResponseWriter, req *http.R
 		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.UpdateStory.ConvertToDAO"), err)
 	}
 
-	_, err = ush.svc.UpdateStory(st)
+	ifUpdatedAt, err := ifMatch(req, "UpdateStoryHandler.UpdateStory", st.GetID())
+	if err != nil {
+		return err
+	}
+
+	_, err = ush.svc.UpdateStory(req.Context(), st, ifUpdatedAt)
 	if err != nil {
 		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.UpdateStory"), err)
 	}
 
 	//TODO: ADD SUCCESS LOG
-	util.WriteSuccessResponse(http.StatusOK, contract.UpdateStoryResponse{Success: true}, resp)
+	util.WriteSuccessResponse(http.StatusOK, contract.UpdateStoryResponse{Success: true}, resp, req)
+	return nil
+}
+
+// PatchStory ONLY CHANGES THE FIELDS SET IN THE REQUEST, THE COUNTERS ARE NOT PART OF IT.
+// AN If-Match THAT DOES NOT NAME THE CURRENT ETAG OF THE STORY FAILS THE REQUEST
+func (ush *UpdateStoryHandler) PatchStory(resp http.ResponseWriter, req *http.Request) error {
+	id, err := util.ParsePathParam(req, StoryIDParam)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.PatchStory"), err)
+	}
+
+	var data contract.PatchStoryRequest
+	err = util.ParseRequest(req, &data)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.PatchStory"), err)
+	}
+
+	if data.Title == nil && data.Body == nil {
+		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.PatchStory"), liberr.ValidationError, liberr.SeverityError, errors.New("nothing to update, expected title or body"))
+	}
+
+	b := model.NewStoryBuilder().SetID(id)
+	if data.Title != nil {
+		b.SetTitle(ush.cfg.TitleMaxLength(), *data.Title)
+	}
+
+	if data.Body != nil {
+		b.SetBody(ush.cfg.BodyMaxLength(), *data.Body)
+	}
+
+	_, err = b.Build()
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.PatchStory"), err)
+	}
+
+	ifUpdatedAt, err := ifMatch(req, "UpdateStoryHandler.PatchStory", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = ush.svc.PatchStory(req.Context(), model.StoryPatch{ID: id, Title: data.Title, Body: data.Body}, ifUpdatedAt)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("UpdateStoryHandler.PatchStory"), err)
+	}
+
+	//TODO: ADD SUCCESS LOG
+	util.WriteSuccessResponse(http.StatusOK, contract.UpdateStoryResponse{Success: true}, resp, req)
 	return nil
 }
 
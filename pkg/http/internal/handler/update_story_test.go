@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/go-chi/chi"
 	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/handler"
 	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -62,7 +67,7 @@ func TestUpdateStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("UpdateStory", ds).Return(int64(1), nil)
+				ms.On("UpdateStory", mock.Anything, ds, time.Time{}).Return(int64(1), nil)
 
 				return ms, bytes.NewBuffer(b)
 			},
@@ -74,7 +79,7 @@ func TestUpdateStory(t *testing.T) {
 				return &service.MockStoriesService{}, nil
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"unexpected end of JSON input\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"unexpected end of JSON input\"},\"success\":false}",
 		},
 		"test update story failure when id is invalid": {
 			input: func() (service.StoryService, io.Reader) {
@@ -100,7 +105,7 @@ func TestUpdateStory(t *testing.T) {
 				return &service.MockStoriesService{}, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"invalid id: invalid-id\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_STORY_ID\",\"message\":\"invalid id: invalid-id\",\"violations\":[{\"field\":\"id\",\"code\":\"INVALID_STORY_ID\",\"message\":\"invalid id: invalid-id\"}]},\"success\":false}",
 		},
 		"test update story failure when title is empty": {
 			input: func() (service.StoryService, io.Reader) {
@@ -126,7 +131,7 @@ func TestUpdateStory(t *testing.T) {
 				return &service.MockStoriesService{}, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"title cannot be empty\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\",\"violations\":[{\"field\":\"title\",\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\"}]},\"success\":false}",
 		},
 		"test update story failure when body is empty": {
 			input: func() (service.StoryService, io.Reader) {
@@ -152,7 +157,7 @@ func TestUpdateStory(t *testing.T) {
 				return &service.MockStoriesService{}, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"body cannot be empty\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"BODY_REQUIRED\",\"message\":\"body cannot be empty\",\"violations\":[{\"field\":\"body\",\"code\":\"BODY_REQUIRED\",\"message\":\"body cannot be empty\"}]},\"success\":false}",
 		},
 		"test update story failure when svc call fails": {
 			input: func() (service.StoryService, io.Reader) {
@@ -189,12 +194,12 @@ func TestUpdateStory(t *testing.T) {
 				require.NoError(t, err)
 
 				ms := &service.MockStoriesService{}
-				ms.On("UpdateStory", ds).Return(int64(0), liberr.WithArgs(liberr.SeverityError, errors.New("failed to update story")))
+				ms.On("UpdateStory", mock.Anything, ds, time.Time{}).Return(int64(0), liberr.WithArgs(liberr.SeverityError, errors.New("failed to update story")))
 
 				return ms, bytes.NewBuffer(b)
 			},
 			expectedCode:   http.StatusInternalServerError,
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 		},
 	}
 
@@ -220,3 +225,112 @@ func testUpdateStory(t *testing.T, expectedCode int, expectedBody string, svc se
 	assert.Equal(t, expectedCode, w.Code)
 	assert.Equal(t, expectedBody, w.Body.String())
 }
+
+func TestPatchStory(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+	updatedAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+	etag := util.StoryETag(&model.Story{ID: id, UpdatedAt: updatedAt})
+
+	testCases := map[string]struct {
+		input          func() (service.StoryService, io.Reader)
+		ifMatch        string
+		expectedResult string
+		expectedCode   int
+	}{
+		"test patch story only changes the fields set": {
+			input: func() (service.StoryService, io.Reader) {
+				title := "new title"
+
+				ms := &service.MockStoriesService{}
+				ms.On("PatchStory", mock.Anything, model.StoryPatch{ID: id, Title: &title}, time.Time{}).Return(int64(1), nil)
+
+				return ms, bytes.NewBufferString("{\"title\":\"new title\"}")
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test patch story only patches the version named by if match": {
+			input: func() (service.StoryService, io.Reader) {
+				body := "new body"
+
+				ms := &service.MockStoriesService{}
+				ms.On("PatchStory", mock.Anything, model.StoryPatch{ID: id, Body: &body}, updatedAt).Return(int64(1), nil)
+
+				return ms, bytes.NewBufferString("{\"body\":\"new body\"}")
+			},
+			ifMatch:        etag,
+			expectedCode:   http.StatusOK,
+			expectedResult: "{\"data\":{\"success\":true},\"success\":true}",
+		},
+		"test patch story failure when the story has changed since it was read": {
+			input: func() (service.StoryService, io.Reader) {
+				body := "new body"
+
+				ms := &service.MockStoriesService{}
+				ms.On("PatchStory", mock.Anything, model.StoryPatch{ID: id, Body: &body}, updatedAt).Return(int64(0), liberr.WithArgs(liberr.PreconditionFailed, errors.New("story has changed since it was read")))
+
+				return ms, bytes.NewBufferString("{\"body\":\"new body\"}")
+			},
+			ifMatch:        etag,
+			expectedCode:   http.StatusPreconditionFailed,
+			expectedResult: "{\"error\":{\"code\":\"PRECONDITION_FAILED\",\"message\":\"story has changed since it was read\"},\"success\":false}",
+		},
+		"test patch story failure when if match names no version of the story": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, bytes.NewBufferString("{\"body\":\"new body\"}")
+			},
+			ifMatch:        "\"stale\"",
+			expectedCode:   http.StatusPreconditionFailed,
+			expectedResult: "{\"error\":{\"code\":\"PRECONDITION_FAILED\",\"message\":\"story has changed since it was read\"},\"success\":false}",
+		},
+		"test patch story failure when nothing is set": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, bytes.NewBufferString("{}")
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"nothing to update, expected title or body\"},\"success\":false}",
+		},
+		"test patch story failure when the title is too long": {
+			input: func() (service.StoryService, io.Reader) {
+				return &service.MockStoriesService{}, bytes.NewBufferString(fmt.Sprintf("{\"title\":%q}", strings.Repeat("a", 101)))
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"TITLE_TOO_LONG\",\"message\":\"title max length exceeded\",\"violations\":[{\"field\":\"title\",\"code\":\"TITLE_TOO_LONG\",\"message\":\"title max length exceeded\"}]},\"success\":false}",
+		},
+		"test patch story failure when story does not exist": {
+			input: func() (service.StoryService, io.Reader) {
+				body := "new body"
+
+				ms := &service.MockStoriesService{}
+				ms.On("PatchStory", mock.Anything, model.StoryPatch{ID: id, Body: &body}, time.Time{}).Return(int64(0), liberr.WithArgs(liberr.ResourceNotFound, errors.New("failed to update story")))
+
+				return ms, bytes.NewBufferString("{\"body\":\"new body\"}")
+			},
+			expectedCode:   http.StatusNotFound,
+			expectedResult: "{\"error\":{\"code\":\"RESOURCE_NOT_FOUND\",\"message\":\"requested resource was not found\"},\"success\":false}",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.NewConfig("../../../../local.env")
+
+			svc, body := testCase.input()
+			uh := handler.NewUpdateStoryHandler(cfg.StoryConfig(), svc)
+
+			r := chi.NewRouter()
+			r.Patch("/stories/{id}", mdl.WithError(reporters.NewLogger("dev", "debug"), uh.PatchStory))
+
+			req := httptest.NewRequest(http.MethodPatch, "/stories/"+id, body)
+			if len(testCase.ifMatch) != 0 {
+				req.Header.Set("If-Match", testCase.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
@@ -2,12 +2,22 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/resperr"
 	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/store"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,7 +36,7 @@ func WithError(lgr *zap.Logger, handler func(resp http.ResponseWriter, req *http
 			lgr.Error(err.Error())
 		}
 
-		util.WriteFailureResponse(resperr.MapError(err), resp)
+		util.WriteFailureResponse(resperr.MapError(err), resp, req)
 	}
 }
 
@@ -46,7 +56,7 @@ func WithReqRespLog(lgr *zap.Logger, handler http.HandlerFunc) http.HandlerFunc
 
 func WithResponseHeaders(handler http.HandlerFunc) http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
-		resp.Header().Set("Content-Type", "application/json")
+		resp.Header().Set("Content-Type", contract.JSONContentType)
 		handler(resp, req)
 	}
 }
@@ -85,3 +95,177 @@ func WithPrometheus(prometheus reporters.Prometheus, api string, handler http.Ha
 		prometheus.ReportSuccess(api)
 	}
 }
+
+// WithDeprecation MARKS A LEGACY ROUTE AS DEPRECATED AND POINTS THE CLIENT TO THE ROUTE REPLACING IT
+func WithDeprecation(successor string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		resp.Header().Set("Deprecation", "true")
+		resp.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		handler(resp, req)
+	}
+}
+
+// WithCacheControl ONLY MARKS SUCCESSFUL AND NOT MODIFIED RESPONSES AS CACHEABLE, AN EMPTY VALUE SENDS NO HEADER
+func WithCacheControl(value string, handler http.HandlerFunc) http.HandlerFunc {
+	if len(value) == 0 {
+		return handler
+	}
+
+	return func(resp http.ResponseWriter, req *http.Request) {
+		handler(&cacheControlWriter{ResponseWriter: resp, value: value}, req)
+	}
+}
+
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(statusCode int) {
+	if !cw.wroteHeader && (statusCode < http.StatusMultipleChoices || statusCode == http.StatusNotModified) {
+		cw.Header().Set("Cache-Control", cw.value)
+	}
+
+	cw.wroteHeader = true
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	return cw.ResponseWriter.Write(b)
+}
+
+// WithCompression COMPRESSES THE RESPONSE IN THE CODING THE CLIENT PREFERS, ONLY BODIES OF AN ALLOWED MEDIA TYPE
+// THAT REACH THE MINIMUM SIZE ARE COMPRESSED
+func WithCompression(cfg config.HTTPCompressionConfig, lgr *zap.Logger, prometheus reporters.Prometheus) func(http.Handler) http.Handler {
+	contentTypes := make(map[string]bool)
+	for _, contentType := range cfg.ContentTypes() {
+		contentTypes[strings.TrimSpace(contentType)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			encoding := util.NegotiateEncoding(req.Header.Get("Accept-Encoding"))
+
+			cw := util.NewCompressWriter(resp, encoding, cfg.MinSize(), contentTypes)
+			next.ServeHTTP(cw, req)
+
+			if err := cw.Close(); err != nil {
+				lgr.Error(err.Error())
+				return
+			}
+
+			if uncompressed, compressed := cw.Sizes(); uncompressed != 0 {
+				prometheus.ObserveCompression("http", encoding, uncompressed, compressed)
+			}
+		})
+	}
+}
+
+const (
+	// APIKeyHeader CARRIES THE API KEY OF A CLIENT
+	APIKeyHeader = "X-API-Key"
+
+	bearerScheme = "bearer"
+	authRealm    = `Bearer realm="stories"`
+)
+
+// WithAuthentication PUTS THE PRINCIPAL OF THE API KEY OR OF THE BEARER TOKEN ON THE REQUEST CONTEXT, THE RATE LIMIT
+// AND THE SESSION KNOW THE CLIENT BY IT. A NIL AUTHENTICATOR LEAVES THE API ANONYMOUS.
+func WithAuthentication(authn auth.Authenticator, lgr *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if authn == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			p, ok, err := authenticate(authn, req)
+			if err != nil {
+				t, isLibErr := err.(*liberr.Error)
+				if isLibErr && t.Kind() == liberr.Unauthenticated {
+					resp.Header().Set("WWW-Authenticate", authRealm)
+				} else if isLibErr {
+					lgr.Error(t.EncodedStack())
+				} else {
+					lgr.Error(err.Error())
+				}
+
+				util.WriteFailureResponse(resperr.MapError(err), resp, req)
+				return
+			}
+
+			if ok {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), p))
+			}
+
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+func authenticate(authn auth.Authenticator, req *http.Request) (auth.Principal, bool, error) {
+	creds := auth.Credentials{APIKey: req.Header.Get(APIKeyHeader)}
+
+	if authorization := req.Header.Get("Authorization"); len(authorization) != 0 {
+		parts := strings.SplitN(authorization, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], bearerScheme) {
+			return auth.Principal{}, false, liberr.WithArgs(liberr.Operation("WithAuthentication"), liberr.Unauthenticated, liberr.SeverityInfo, errors.New("only the bearer authorization scheme is supported"))
+		}
+
+		creds.BearerToken = strings.TrimSpace(parts[1])
+	}
+
+	return authn.Authenticate(req.Context(), creds)
+}
+
+// WithRateLimit ANSWERS 429 ONCE A CLIENT HAS USED UP ITS TOKENS FOR THE API, A CLIENT IS KNOWN BY ITS PRINCIPAL OR
+// ELSE BY ITS ADDRESS, A NIL LIMITER LEAVES THE API UNLIMITED
+func WithRateLimit(limiter resilience.RateLimiter, api string, prometheus reporters.Prometheus) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			allowed, wait := limiter.Allow(clientIdentity(req))
+			if allowed {
+				prometheus.ReportRateLimitAllowed(api)
+				next.ServeHTTP(resp, req)
+				return
+			}
+
+			prometheus.ReportRateLimitRejected(api)
+
+			retryAfter := resilience.RetryAfter(wait)
+			resp.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+			err := liberr.WithArgs(liberr.Operation("WithRateLimit"), liberr.RateLimited, liberr.SeverityInfo, fmt.Errorf("rate limit of %s exceeded, retry in %d seconds", api, retryAfter))
+			util.WriteFailureResponse(resperr.MapError(err), resp, req)
+		})
+	}
+}
+
+// WithSession LETS THE STORE SEND THE READS OF A CLIENT THAT JUST WROTE TO THE PRIMARY, A CLIENT IS KNOWN THE SAME
+// WAY AS FOR THE RATE LIMIT
+func WithSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(resp, req.WithContext(store.WithSession(req.Context(), clientIdentity(req))))
+	})
+}
+
+func clientIdentity(req *http.Request) string {
+	if p, ok := auth.PrincipalFrom(req.Context()); ok {
+		return p.String()
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return "ip:" + req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
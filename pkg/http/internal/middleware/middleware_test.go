@@ -4,16 +4,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/http/internal/middleware"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWithErrorHandling(t *testing.T) {
@@ -52,7 +58,7 @@ func TestWithErrorHandling(t *testing.T) {
 					svc(),
 				)
 			},
-			expectedResult: "{\"error\":{\"message\":\"insertion failed\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"insertion failed\"},\"success\":false}",
 			expectedCode:   http.StatusBadRequest,
 			expectedLog:    "insertion failed",
 		},
@@ -61,7 +67,7 @@ func TestWithErrorHandling(t *testing.T) {
 			handler: func(resp http.ResponseWriter, req *http.Request) error {
 				return errors.New("some random error")
 			},
-			expectedResult: "{\"error\":{\"message\":\"internal server error\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INTERNAL\",\"message\":\"internal server error\"},\"success\":false}",
 			expectedCode:   http.StatusInternalServerError,
 			expectedLog:    "some random error",
 		},
@@ -272,3 +278,342 @@ func TestWithRequestContext(t *testing.T) {
 
 	middleware.WithRequestContext(th)(w, r)
 }
+
+func TestWithDeprecation(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/story/get", nil)
+	require.NoError(t, err)
+
+	th := func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}
+
+	middleware.WithDeprecation("/stories/{id}", th)(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, "</stories/{id}>; rel=\"successor-version\"", w.Header().Get("Link"))
+}
+
+func TestWithCacheControl(t *testing.T) {
+	testCases := map[string]struct {
+		value                string
+		code                 int
+		expectedCacheControl string
+	}{
+		"test cache control is set on success": {
+			value:                "max-age=30",
+			code:                 http.StatusOK,
+			expectedCacheControl: "max-age=30",
+		},
+		"test cache control is set on not modified": {
+			value:                "no-cache",
+			code:                 http.StatusNotModified,
+			expectedCacheControl: "no-cache",
+		},
+		"test cache control is not set on failure": {
+			value: "max-age=30",
+			code:  http.StatusNotFound,
+		},
+		"test cache control is not set when empty": {
+			code: http.StatusOK,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/stories", nil)
+			require.NoError(t, err)
+
+			th := func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(testCase.code)
+			}
+
+			middleware.WithCacheControl(testCase.value, th)(w, r)
+
+			assert.Equal(t, testCase.code, w.Code)
+			assert.Equal(t, testCase.expectedCacheControl, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	cfg := config.NewConfig("../../../../local.env").HTTPCompressionConfig()
+
+	testCases := map[string]struct {
+		acceptEncoding          string
+		body                    string
+		expectedContentEncoding string
+		expectedReport          bool
+	}{
+		"test large body is compressed and reported": {
+			acceptEncoding:          "gzip",
+			body:                    strings.Repeat("a", cfg.MinSize()),
+			expectedContentEncoding: "gzip",
+			expectedReport:          true,
+		},
+		"test small body is not compressed": {
+			acceptEncoding: "gzip",
+			body:           "{}",
+		},
+		"test body is not compressed without accept encoding": {
+			body: strings.Repeat("a", cfg.MinSize()),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodGet, "/stories", nil)
+			require.NoError(t, err)
+
+			r.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+
+			th := func(resp http.ResponseWriter, req *http.Request) {
+				resp.Header().Set("Content-Type", "application/json")
+				_, _ = resp.Write([]byte(testCase.body))
+			}
+
+			mockPrometheus := &reporters.MockPrometheus{}
+			mockPrometheus.On("ObserveCompression", "http", "gzip", len(testCase.body), mock.AnythingOfType("int"))
+
+			middleware.WithCompression(cfg, zap.NewNop(), mockPrometheus)(http.HandlerFunc(th)).ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, testCase.expectedContentEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+			if testCase.expectedReport {
+				mockPrometheus.AssertCalled(t, "ObserveCompression", "http", "gzip", len(testCase.body), w.Body.Len())
+			} else {
+				mockPrometheus.AssertNotCalled(t, "ObserveCompression", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportRateLimitAllowed", "add")
+	pr.On("ReportRateLimitRejected", "add")
+
+	th := func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusCreated)
+	}
+
+	h := middleware.WithRateLimit(resilience.NewRateLimiter(1, time.Minute, 1), "add", pr)(http.HandlerFunc(th))
+
+	call := func(remoteAddr, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodPost, "/story/add", nil)
+		require.NoError(t, err)
+
+		r.RemoteAddr = remoteAddr
+		if len(key) != 0 {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{ID: key, Method: auth.MethodAPIKey}))
+		}
+
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	testCases := []struct {
+		name               string
+		remoteAddr         string
+		key                string
+		expectedCode       int
+		expectedRetryAfter string
+	}{
+		{
+			name:         "test first call of an address is allowed",
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:               "test second call of an address from another port is limited",
+			remoteAddr:         "10.0.0.1:4321",
+			expectedCode:       http.StatusTooManyRequests,
+			expectedRetryAfter: "60",
+		},
+		{
+			name:         "test call of a principal has its own bucket",
+			remoteAddr:   "10.0.0.1:1234",
+			key:          "key",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "test another address has its own bucket",
+			remoteAddr:   "10.0.0.2:1234",
+			expectedCode: http.StatusCreated,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			w := call(testCase.remoteAddr, testCase.key)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedRetryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+
+	pr.AssertNumberOfCalls(t, "ReportRateLimitAllowed", 3)
+	pr.AssertNumberOfCalls(t, "ReportRateLimitRejected", 1)
+}
+
+func TestWithRateLimitWithoutLimiter(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/stories", nil)
+	require.NoError(t, err)
+
+	th := func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}
+
+	middleware.WithRateLimit(nil, "listStories", &reporters.MockPrometheus{})(http.HandlerFunc(th)).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithAuthentication(t *testing.T) {
+	principal := auth.Principal{ID: "key-1", Name: "ci", Method: auth.MethodAPIKey}
+
+	testCases := map[string]struct {
+		headers              map[string]string
+		expectedCreds        *auth.Credentials
+		principal            auth.Principal
+		ok                   bool
+		err                  error
+		expectedCode         int
+		expectedPrincipal    bool
+		expectedAuthenticate string
+	}{
+		"test anonymous request is let through": {
+			expectedCreds: &auth.Credentials{},
+			expectedCode:  http.StatusOK,
+		},
+		"test api key puts the principal on the context": {
+			headers:           map[string]string{middleware.APIKeyHeader: "sk_key"},
+			expectedCreds:     &auth.Credentials{APIKey: "sk_key"},
+			principal:         principal,
+			ok:                true,
+			expectedCode:      http.StatusOK,
+			expectedPrincipal: true,
+		},
+		"test bearer token is read from the authorization header": {
+			headers:           map[string]string{"Authorization": "bearer token"},
+			expectedCreds:     &auth.Credentials{BearerToken: "token"},
+			principal:         principal,
+			ok:                true,
+			expectedCode:      http.StatusOK,
+			expectedPrincipal: true,
+		},
+		"test invalid credentials are rejected": {
+			headers:              map[string]string{middleware.APIKeyHeader: "sk_key"},
+			expectedCreds:        &auth.Credentials{APIKey: "sk_key"},
+			err:                  liberr.WithArgs(liberr.Unauthenticated, errors.New("invalid api key")),
+			expectedCode:         http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="stories"`,
+		},
+		"test other authorization schemes are rejected": {
+			headers:              map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			expectedCode:         http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="stories"`,
+		},
+		"test failed lookup is not reported as unauthenticated": {
+			headers:       map[string]string{middleware.APIKeyHeader: "sk_key"},
+			expectedCreds: &auth.Credentials{APIKey: "sk_key"},
+			err:           liberr.WithArgs(liberr.Unavailable, errors.New("database is down")),
+			expectedCode:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest(http.MethodDelete, "/stories/1", nil)
+			require.NoError(t, err)
+
+			for k, v := range testCase.headers {
+				r.Header.Set(k, v)
+			}
+
+			authn := &auth.MockAuthenticator{}
+			if testCase.expectedCreds != nil {
+				authn.On("Authenticate", mock.Anything, *testCase.expectedCreds).Return(testCase.principal, testCase.ok, testCase.err)
+			}
+
+			var seen bool
+			th := func(resp http.ResponseWriter, req *http.Request) {
+				var p auth.Principal
+				p, seen = auth.PrincipalFrom(req.Context())
+				assert.Equal(t, testCase.principal, p)
+				resp.WriteHeader(http.StatusOK)
+			}
+
+			middleware.WithAuthentication(authn, zap.NewNop())(http.HandlerFunc(th)).ServeHTTP(w, r)
+
+			assert.Equal(t, testCase.expectedCode, w.Code)
+			assert.Equal(t, testCase.expectedPrincipal, seen)
+			assert.Equal(t, testCase.expectedAuthenticate, w.Header().Get("WWW-Authenticate"))
+
+			if testCase.expectedCreds == nil {
+				authn.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWithAuthenticationWithoutAuthenticator(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/ping", nil)
+	require.NoError(t, err)
+
+	r.Header.Set(middleware.APIKeyHeader, "sk_key")
+
+	th := func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}
+
+	middleware.WithAuthentication(nil, zap.NewNop())(http.HandlerFunc(th)).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithSession(t *testing.T) {
+	testCases := map[string]struct {
+		request         func() *http.Request
+		expectedSession string
+	}{
+		"test client is known by its principal": {
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/stories", nil)
+				return r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{ID: "key-1", Method: auth.MethodAPIKey}))
+			},
+			expectedSession: "apiKey:key-1",
+		},
+		"test anonymous client is known by its address": {
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/stories", nil)
+				r.RemoteAddr = "10.0.0.1:4321"
+				return r
+			},
+			expectedSession: "ip:10.0.0.1",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var session string
+
+			th := func(resp http.ResponseWriter, req *http.Request) {
+				session, _ = store.SessionFrom(req.Context())
+			}
+
+			middleware.WithSession(http.HandlerFunc(th)).ServeHTTP(httptest.NewRecorder(), testCase.request())
+
+			assert.Equal(t, testCase.expectedSession, session)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	version = "3.0.3"
+
+	jsonContentType = "application/json"
+
+	schemaRefPrefix   = "#/components/schemas/"
+	responseRefPrefix = "#/components/responses/"
+
+	errorResponseName = "Error"
+)
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem IS KEYED BY THE LOWER CASE HTTP METHOD
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+
+	// Security IS A POINTER SO A PUBLIC OPERATION CAN OVERRIDE THE DOCUMENT WITH AN EMPTY LIST
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Minimum    *int               `json:"minimum,omitempty"`
+	Maximum    *int               `json:"maximum,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]*Response       `json:"responses"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecurityRequirement IS KEYED BY THE NAME OF A SECURITY SCHEME, EVERY SCHEME IN IT IS NEEDED
+type SecurityRequirement map[string][]string
+
+// Route DESCRIBES ONE ROUTE OF THE ROUTER, Request AND Response ARE ZERO VALUES OF THE CONTRACT TYPES.
+// Response IS WRAPPED IN THE APIResponse ENVELOPE UNLESS THE ROUTE ANSWERS A RAW ContentType.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Deprecated  bool
+	Parameters  []Parameter
+	Request     interface{}
+	Status      int
+	Response    interface{}
+	ContentType string
+
+	// Public ROUTES NEED NO CREDENTIALS EVEN WHEN THE DOCUMENT HAS SECURITY
+	Public bool
+}
+
+func PathParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "string"}}
+}
+
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func HeaderParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// NewDocument DESCRIBES EVERY ROUTE, errorBodies ARE THE BODIES OF EVERY FAILED RESPONSE BY CONTENT TYPE
+func NewDocument(info Info, errorBodies map[string]interface{}, routes ...Route) Document {
+	g := &generator{schemas: make(map[string]*Schema)}
+
+	errorContent := make(map[string]MediaType, len(errorBodies))
+	for contentType, body := range errorBodies {
+		errorContent[contentType] = MediaType{Schema: g.schemaOf(reflect.TypeOf(body))}
+	}
+
+	doc := Document{
+		OpenAPI: version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: g.schemas,
+			Responses: map[string]*Response{
+				errorResponseName: {
+					Description: "the request failed, the status code is mapped from the kind of the error",
+					Content:     errorContent,
+				},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		item, ok := doc.Paths[route.Path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[route.Path] = item
+		}
+
+		item[strings.ToLower(route.Method)] = g.operationOf(route)
+	}
+
+	return doc
+}
+
+func APIKeySecurity(header, description string) *SecurityScheme {
+	return &SecurityScheme{Type: "apiKey", Description: description, Name: header, In: "header"}
+}
+
+func BearerSecurity(format, description string) *SecurityScheme {
+	return &SecurityScheme{Type: "http", Description: description, Scheme: "bearer", BearerFormat: format}
+}
+
+// WithSecurity LETS EVERY OPERATION THAT IS NOT PUBLIC BE CALLED WITH ANY ONE OF THE SCHEMES
+func (d Document) WithSecurity(schemes map[string]*SecurityScheme) Document {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	d.Components.SecuritySchemes = schemes
+	d.Security = make([]SecurityRequirement, 0, len(names))
+
+	for _, name := range names {
+		d.Security = append(d.Security, SecurityRequirement{name: {}})
+	}
+
+	return d
+}
+
+func (d Document) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func (g *generator) operationOf(route Route) *Operation {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Deprecated:  route.Deprecated,
+		Parameters:  route.Parameters,
+		Responses: map[string]*Response{
+			"default": {Ref: responseRefPrefix + errorResponseName},
+		},
+	}
+
+	if route.Public {
+		op.Security = &[]SecurityRequirement{}
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(g.schemaOf(reflect.TypeOf(route.Request)))}
+	}
+
+	if route.Status == 0 {
+		return op
+	}
+
+	res := &Response{Description: http.StatusText(route.Status)}
+
+	switch {
+	case len(route.ContentType) != 0:
+		res.Content = map[string]MediaType{route.ContentType: {Schema: g.schemaOrAny(route.Response)}}
+	case route.Response != nil:
+		res.Content = jsonContent(g.envelopeOf(route.Response))
+	}
+
+	op.Responses[strconv.Itoa(route.Status)] = res
+	return op
+}
+
+// envelopeOf MIRRORS contract.NewSuccessResponse WITH THE TYPE OF THE DATA FILLED IN
+func (g *generator) envelopeOf(data interface{}) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":    g.schemaOf(reflect.TypeOf(data)),
+			"success": {Type: "boolean"},
+		},
+		Required: []string{"data", "success"},
+	}
+}
+
+func (g *generator) schemaOrAny(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// schemaOf ADDS EVERY NAMED STRUCT TO THE COMPONENTS AND REFERENCES IT, A POINTER TO ANYTHING
+// ELSE IS NULLABLE AND AN interface{} ACCEPTS ANY VALUE
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.schemaOf(t.Elem())
+		if len(s.Ref) == 0 {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return g.structSchemaOf(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) structSchemaOf(t reflect.Type) *Schema {
+	name := t.Name()
+	if _, ok := g.schemas[name]; ok && len(name) != 0 {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	// RESERVE THE NAME BEFORE WALKING THE FIELDS SO A RECURSIVE TYPE ENDS IN A REFERENCE
+	if len(name) != 0 {
+		g.schemas[name] = s
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) != 0 {
+			continue
+		}
+
+		field, opts := jsonName(f)
+		if field == "-" {
+			continue
+		}
+
+		s.Properties[field] = g.schemaOf(f.Type)
+
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, field)
+		}
+	}
+
+	if len(name) == 0 {
+		return s
+	}
+
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func jsonName(f reflect.StructField) (string, string) {
+	tag := f.Tag.Get("json")
+	if len(tag) == 0 {
+		return f.Name, ""
+	}
+
+	parts := strings.SplitN(tag, ",", 2)
+	if len(parts[0]) == 0 {
+		parts[0] = f.Name
+	}
+
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{jsonContentType: {Schema: schema}}
+}
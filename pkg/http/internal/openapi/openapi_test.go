@@ -0,0 +1,108 @@
+package openapi_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/http/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+type testEnvelope struct {
+	Data    interface{} `json:"data,omitempty"`
+	Success bool        `json:"success"`
+}
+
+type testProblem struct {
+	Title string `json:"title"`
+}
+
+type testItem struct {
+	ID    string  `json:"id"`
+	Note  *string `json:"note"`
+	Count int64   `json:"count,omitempty"`
+	Tags  []string
+	Next  *testItem `json:"next,omitempty"`
+	skip  bool
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := openapi.NewDocument(
+		openapi.Info{Title: "test", Version: "1"},
+		map[string]interface{}{"application/json": testEnvelope{}, "application/problem+json": testProblem{}},
+		openapi.Route{
+			Method:      http.MethodPost,
+			Path:        "/items/{id}",
+			OperationID: "addItem",
+			Parameters:  []openapi.Parameter{openapi.PathParam("id", "id of the item")},
+			Request:     testItem{},
+			Status:      http.StatusCreated,
+			Response:    []testItem{},
+		},
+		openapi.Route{Method: http.MethodDelete, Path: "/items/{id}", OperationID: "deleteItem", Status: http.StatusNoContent},
+	)
+
+	require.Contains(t, doc.Paths, "/items/{id}")
+	require.Len(t, doc.Paths["/items/{id}"], 2)
+
+	add := doc.Paths["/items/{id}"]["post"]
+	require.NotNil(t, add)
+
+	itemRef := &openapi.Schema{Ref: "#/components/schemas/testItem"}
+
+	assert.Equal(t, "addItem", add.OperationID)
+	assert.Equal(t, itemRef, add.RequestBody.Content["application/json"].Schema)
+	assert.Equal(t, "#/components/responses/Error", add.Responses["default"].Ref)
+
+	assert.Equal(t, &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data":    {Type: "array", Items: itemRef},
+			"success": {Type: "boolean"},
+		},
+		Required: []string{"data", "success"},
+	}, add.Responses["201"].Content["application/json"].Schema)
+
+	assert.Equal(t, &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"id":    {Type: "string"},
+			"note":  {Type: "string", Nullable: true},
+			"count": {Type: "integer", Format: "int64"},
+			"Tags":  {Type: "array", Items: &openapi.Schema{Type: "string"}},
+			"next":  itemRef,
+		},
+		Required: []string{"id", "Tags"},
+	}, doc.Components.Schemas["testItem"])
+
+	del := doc.Paths["/items/{id}"]["delete"]
+	require.NotNil(t, del)
+	assert.Nil(t, del.RequestBody)
+	assert.Nil(t, del.Responses["204"].Content)
+
+	assert.Equal(t, &openapi.Schema{Ref: "#/components/schemas/testEnvelope"}, doc.Components.Responses["Error"].Content["application/json"].Schema)
+	assert.Equal(t, &openapi.Schema{Ref: "#/components/schemas/testProblem"}, doc.Components.Responses["Error"].Content["application/problem+json"].Schema)
+}
+
+func TestDocumentWithSecurity(t *testing.T) {
+	doc := openapi.NewDocument(
+		openapi.Info{Title: "test", Version: "1"},
+		map[string]interface{}{"application/json": testEnvelope{}},
+		openapi.Route{Method: http.MethodGet, Path: "/ping", OperationID: "ping", Status: http.StatusOK, Public: true},
+		openapi.Route{Method: http.MethodDelete, Path: "/items/{id}", OperationID: "deleteItem", Status: http.StatusNoContent},
+	).WithSecurity(map[string]*openapi.SecurityScheme{
+		"bearerAuth": openapi.BearerSecurity("JWT", ""),
+		"apiKeyAuth": openapi.APIKeySecurity("X-API-Key", ""),
+	})
+
+	assert.Equal(t, []openapi.SecurityRequirement{{"apiKeyAuth": {}}, {"bearerAuth": {}}}, doc.Security)
+	assert.Equal(t, &openapi.SecurityScheme{Type: "apiKey", Name: "X-API-Key", In: "header"}, doc.Components.SecuritySchemes["apiKeyAuth"])
+	assert.Equal(t, &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}, doc.Components.SecuritySchemes["bearerAuth"])
+
+	b, err := doc.JSON()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(b), `"security": []`)
+	assert.Equal(t, &[]openapi.SecurityRequirement{}, doc.Paths["/ping"]["get"].Security)
+	assert.Nil(t, doc.Paths["/items/{id}"]["delete"].Security)
+}
@@ -1,29 +1,62 @@
 package resperr
 
 import (
+	"context"
+	"errors"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"net/http"
 )
 
 const (
-	defaultStatusCode = http.StatusInternalServerError
-	defaultMessage    = "internal server error"
-	notFoundMessage   = "requested resource was not found"
+	defaultStatusCode  = http.StatusInternalServerError
+	defaultMessage     = "internal server error"
+	notFoundMessage    = "requested resource was not found"
+	unavailableMessage = "service unavailable"
+	conflictMessage    = "request conflicts with the current state of the resource"
+	constraintMessage  = "request violates a constraint on the stored data"
+
+	// NON STANDARD STATUS USED BY NGINX WHEN THE CLIENT GOES AWAY BEFORE THE RESPONSE IS WRITTEN
+	clientClosedRequest        = 499
+	clientClosedRequestMessage = "client closed request"
+	timeoutMessage             = "request timed out"
 )
 
 func MapError(err error) ResponseError {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewResponseError(http.StatusGatewayTimeout, liberr.CodeTimeout, timeoutMessage)
+	case errors.Is(err, context.Canceled):
+		return NewResponseError(clientClosedRequest, liberr.CodeCanceled, clientClosedRequestMessage)
+	}
+
 	t, ok := err.(*liberr.Error)
 	if !ok {
-		return NewResponseError(defaultStatusCode, defaultMessage)
+		return NewResponseError(defaultStatusCode, liberr.CodeInternal, defaultMessage)
 	}
 
+	c := t.Code()
+
 	k := t.Kind()
 	switch k {
 	case liberr.ValidationError:
-		return NewResponseError(http.StatusBadRequest, t.Error())
+		return NewResponseError(http.StatusBadRequest, c, t.Error(), t.FieldViolations()...)
 	case liberr.ResourceNotFound:
-		return NewResponseError(http.StatusNotFound, notFoundMessage)
+		return NewResponseError(http.StatusNotFound, c, notFoundMessage)
+	case liberr.Conflict:
+		return NewResponseError(http.StatusConflict, c, conflictMessage)
+	case liberr.ConstraintViolation:
+		return NewResponseError(http.StatusUnprocessableEntity, c, constraintMessage)
+	case liberr.Unavailable:
+		return NewResponseError(http.StatusServiceUnavailable, c, unavailableMessage)
+	case liberr.UnsupportedMediaType:
+		return NewResponseError(http.StatusUnsupportedMediaType, c, t.Error())
+	case liberr.PreconditionFailed:
+		return NewResponseError(http.StatusPreconditionFailed, c, t.Error())
+	case liberr.RateLimited:
+		return NewResponseError(http.StatusTooManyRequests, c, t.Error())
+	case liberr.Unauthenticated:
+		return NewResponseError(http.StatusUnauthorized, c, t.Error())
 	default:
-		return NewResponseError(defaultStatusCode, defaultMessage)
+		return NewResponseError(defaultStatusCode, c, defaultMessage)
 	}
 }
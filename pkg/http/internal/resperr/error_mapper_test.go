@@ -1,3 +1,85 @@
-package resperr
+package resperr_test
 
-//TODO: ADD TEST FOR ERROR MAPPER
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/resperr"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMapError(t *testing.T) {
+	testCases := map[string]struct {
+		err            error
+		expectedResult resperr.ResponseError
+	}{
+		"test map validation error": {
+			err:            liberr.WithArgs(liberr.ValidationError, errors.New("invalid id")),
+			expectedResult: resperr.NewResponseError(http.StatusBadRequest, liberr.CodeInvalidRequest, "invalid id"),
+		},
+		"test map validation error with field violations": {
+			err:            liberr.WithArgs(liberr.Operation("AddStoryHandler.AddStory"), liberr.WithArgs(liberr.ValidationError, liberr.CodeTitleRequired, liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"), errors.New("title cannot be empty"))),
+			expectedResult: resperr.NewResponseError(http.StatusBadRequest, liberr.CodeTitleRequired, "title cannot be empty", liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty")),
+		},
+		"test map story not found error": {
+			err:            liberr.WithArgs(liberr.Operation("StoryService.GetStory"), liberr.WithArgs(liberr.ResourceNotFound, liberr.CodeStoryNotFound, errors.New("no records found"))),
+			expectedResult: resperr.NewResponseError(http.StatusNotFound, liberr.CodeStoryNotFound, "requested resource was not found"),
+		},
+		"test map resource not found error": {
+			err:            liberr.WithArgs(liberr.ResourceNotFound, errors.New("no records found")),
+			expectedResult: resperr.NewResponseError(http.StatusNotFound, liberr.CodeResourceNotFound, "requested resource was not found"),
+		},
+		"test map internal error": {
+			err:            liberr.WithArgs(liberr.InternalError, errors.New("some error")),
+			expectedResult: resperr.NewResponseError(http.StatusInternalServerError, liberr.CodeInternal, "internal server error"),
+		},
+		"test map conflict error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.Conflict, errors.New("pq: duplicate key value"))),
+			expectedResult: resperr.NewResponseError(http.StatusConflict, liberr.CodeConflict, "request conflicts with the current state of the resource"),
+		},
+		"test map constraint violation error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.ConstraintViolation, errors.New("pq: violates check constraint"))),
+			expectedResult: resperr.NewResponseError(http.StatusUnprocessableEntity, liberr.CodeConstraintViolation, "request violates a constraint on the stored data"),
+		},
+		"test map unavailable error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.Unavailable, errors.New("circuit breaker is open"))),
+			expectedResult: resperr.NewResponseError(http.StatusServiceUnavailable, liberr.CodeUnavailable, "service unavailable"),
+		},
+		"test map unsupported media type error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.UnsupportedMediaType, errors.New("unsupported media type application/x-protobuf"))),
+			expectedResult: resperr.NewResponseError(http.StatusUnsupportedMediaType, liberr.CodeUnsupportedMediaType, "unsupported media type application/x-protobuf"),
+		},
+		"test map precondition failed error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.PreconditionFailed, errors.New("story has changed"))),
+			expectedResult: resperr.NewResponseError(http.StatusPreconditionFailed, liberr.CodePreconditionFailed, "story has changed"),
+		},
+		"test map rate limited error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.RateLimited, errors.New("rate limit of add exceeded"))),
+			expectedResult: resperr.NewResponseError(http.StatusTooManyRequests, liberr.CodeRateLimited, "rate limit of add exceeded"),
+		},
+		"test map unauthenticated error": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.WithArgs(liberr.Unauthenticated, errors.New("invalid api key"))),
+			expectedResult: resperr.NewResponseError(http.StatusUnauthorized, liberr.CodeUnauthenticated, "invalid api key"),
+		},
+		"test map unknown error": {
+			err:            errors.New("some error"),
+			expectedResult: resperr.NewResponseError(http.StatusInternalServerError, liberr.CodeInternal, "internal server error"),
+		},
+		"test map deadline exceeded": {
+			err:            liberr.WithArgs(liberr.Operation("op"), liberr.InternalError, context.DeadlineExceeded),
+			expectedResult: resperr.NewResponseError(http.StatusGatewayTimeout, liberr.CodeTimeout, "request timed out"),
+		},
+		"test map canceled": {
+			err:            liberr.WithArgs(liberr.Operation("op"), context.Canceled),
+			expectedResult: resperr.NewResponseError(499, liberr.CodeCanceled, "client closed request"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedResult, resperr.MapError(testCase.err))
+		})
+	}
+}
@@ -1,21 +1,35 @@
 package resperr
 
+import "github.com/nsnikhil/stories/pkg/liberr"
+
 type ResponseError struct {
 	statusCode  int
+	code        liberr.Code
 	description string
+	violations  []liberr.FieldViolation
 }
 
 func (re ResponseError) StatusCode() int {
 	return re.statusCode
 }
 
+func (re ResponseError) Code() liberr.Code {
+	return re.code
+}
+
 func (re ResponseError) Description() string {
 	return re.description
 }
 
-func NewResponseError(statusCode int, description string) ResponseError {
+func (re ResponseError) Violations() []liberr.FieldViolation {
+	return re.violations
+}
+
+func NewResponseError(statusCode int, code liberr.Code, description string, violations ...liberr.FieldViolation) ResponseError {
 	return ResponseError{
 		statusCode:  statusCode,
+		code:        code,
 		description: description,
+		violations:  violations,
 	}
 }
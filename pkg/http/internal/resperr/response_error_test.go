@@ -3,13 +3,15 @@ package resperr_test
 import (
 	"github.com/bmizerany/assert"
 	"github.com/nsnikhil/stories/pkg/http/internal/resperr"
+	"github.com/nsnikhil/stories/pkg/liberr"
 	"net/http"
 	"testing"
 )
 
 func TestGenericErrorGetErrorCode(t *testing.T) {
-	ge := resperr.NewResponseError(http.StatusBadRequest, "some reason")
+	ge := resperr.NewResponseError(http.StatusBadRequest, liberr.CodeInvalidRequest, "some reason")
 
 	assert.Equal(t, http.StatusBadRequest, ge.StatusCode())
+	assert.Equal(t, liberr.CodeInvalidRequest, ge.Code())
 	assert.Equal(t, "some reason", ge.Description())
 }
@@ -0,0 +1,163 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/vmihailenco/msgpack/v4"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+var errUnsupportedValue = errors.New("value has no representation in the media type")
+
+// codec ENCODES AND DECODES THE BODIES OF ONE MEDIA TYPE, enveloped TELLS WHETHER A SUCCESSFUL
+// RESPONSE IS WRAPPED IN THE APIResponse ENVELOPE OR WRITTEN AS THE BARE DATA
+type codec interface {
+	contentType() string
+	enveloped() bool
+	marshal(v interface{}) ([]byte, error)
+	unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) contentType() string { return contract.JSONContentType }
+
+func (jsonCodec) enveloped() bool { return true }
+
+func (jsonCodec) marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// msgpackCodec READS THE JSON TAGS SO THE FIELDS ARE NAMED AS THEY ARE IN JSON
+type msgpackCodec struct{}
+
+func (msgpackCodec) contentType() string { return contract.MsgpackContentType }
+
+func (msgpackCodec) enveloped() bool { return true }
+
+func (msgpackCodec) marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := msgpack.NewEncoder(&buf).UseJSONTag(true).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) unmarshal(data []byte, v interface{}) error {
+	return msgpack.NewDecoder(bytes.NewReader(data)).UseJSONTag(true).Decode(v)
+}
+
+// THE CONTRACT TYPES WITH A stories-proto COUNTERPART IMPLEMENT protoMarshaler AND protoUnmarshaler
+type protoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+type protoUnmarshaler interface {
+	UnmarshalProto(data []byte) error
+}
+
+// protobufCodec HAS NO ENVELOPE MESSAGE TO WRAP THE DATA IN, THE STATUS CODE TELLS SUCCESS FROM FAILURE
+type protobufCodec struct{}
+
+func (protobufCodec) contentType() string { return contract.ProtobufContentType }
+
+func (protobufCodec) enveloped() bool { return false }
+
+func (protobufCodec) marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(protoMarshaler)
+	if !ok {
+		return nil, errUnsupportedValue
+	}
+
+	return m.MarshalProto()
+}
+
+func (protobufCodec) unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(protoUnmarshaler)
+	if !ok {
+		return errUnsupportedValue
+	}
+
+	return m.UnmarshalProto(data)
+}
+
+var codecs = newCodecRegistry(jsonCodec{}, msgpackCodec{}, protobufCodec{})
+
+type codecRegistry struct {
+	fallback codec
+	others   []codec
+}
+
+func newCodecRegistry(fallback codec, others ...codec) *codecRegistry {
+	return &codecRegistry{fallback: fallback, others: others}
+}
+
+// forContentType RETURNS THE CODEC OF A REQUEST BODY, A BODY OF ANY OTHER TYPE IS READ BY THE FALLBACK
+// SO CLIENTS THAT NEVER SET A CONTENT TYPE KEEP WORKING
+func (cr *codecRegistry) forContentType(contentType string) codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return cr.fallback
+	}
+
+	for _, c := range cr.others {
+		if c.contentType() == mediaType {
+			return c
+		}
+	}
+
+	return cr.fallback
+}
+
+// forAccept RETURNS THE CODEC THE CLIENT PREFERS, ONLY AN EXPLICIT MEDIA TYPE SELECTS A CODEC OTHER
+// THAN THE FALLBACK WHICH ALSO WINS EVERY TIE
+func (cr *codecRegistry) forAccept(accept string) codec {
+	qualities := parseAccept(accept)
+
+	best, bestQ := cr.fallback, qualities[cr.fallback.contentType()]
+	for _, c := range cr.others {
+		if q := qualities[c.contentType()]; q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+
+	return best
+}
+
+// parseAccept RETURNS THE QUALITY OF EVERY MEDIA RANGE OF AN ACCEPT HEADER, A RANGE WITHOUT q HAS A QUALITY OF 1
+func parseAccept(accept string) map[string]float64 {
+	qualities := make(map[string]float64)
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if len(mediaType) == 0 {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || strings.ToLower(kv[0]) != "q" {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				v = 0
+			}
+
+			q = v
+		}
+
+		qualities[mediaType] = q
+	}
+
+	return qualities
+}
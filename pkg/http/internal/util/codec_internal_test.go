@@ -0,0 +1,84 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCodecRegistryForAccept(t *testing.T) {
+	testCases := map[string]struct {
+		accept              string
+		expectedContentType string
+	}{
+		"test no accept header gets json": {
+			expectedContentType: "application/json",
+		},
+		"test wildcard gets json": {
+			accept:              "*/*",
+			expectedContentType: "application/json",
+		},
+		"test unknown media type gets json": {
+			accept:              "text/html",
+			expectedContentType: "application/json",
+		},
+		"test msgpack": {
+			accept:              "application/x-msgpack",
+			expectedContentType: "application/x-msgpack",
+		},
+		"test protobuf": {
+			accept:              "Application/X-Protobuf",
+			expectedContentType: "application/x-protobuf",
+		},
+		"test highest quality wins": {
+			accept:              "application/x-msgpack;q=0.4, application/x-protobuf;q=0.8, application/json;q=0.6",
+			expectedContentType: "application/x-protobuf",
+		},
+		"test json wins a tie": {
+			accept:              "application/x-protobuf, application/json",
+			expectedContentType: "application/json",
+		},
+		"test refused media type gets json": {
+			accept:              "application/x-protobuf;q=0",
+			expectedContentType: "application/json",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedContentType, codecs.forAccept(testCase.accept).contentType())
+		})
+	}
+}
+
+func TestCodecRegistryForContentType(t *testing.T) {
+	testCases := map[string]struct {
+		contentType         string
+		expectedContentType string
+	}{
+		"test no content type is json": {
+			expectedContentType: "application/json",
+		},
+		"test invalid content type is json": {
+			contentType:         ";;",
+			expectedContentType: "application/json",
+		},
+		"test unknown content type is json": {
+			contentType:         "text/plain",
+			expectedContentType: "application/json",
+		},
+		"test msgpack": {
+			contentType:         "application/x-msgpack",
+			expectedContentType: "application/x-msgpack",
+		},
+		"test protobuf with parameters": {
+			contentType:         "application/x-protobuf; charset=binary",
+			expectedContentType: "application/x-protobuf",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedContentType, codecs.forContentType(testCase.contentType).contentType())
+		})
+	}
+}
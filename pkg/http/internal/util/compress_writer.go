@@ -0,0 +1,185 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	GzipEncoding   = "gzip"
+	BrotliEncoding = "br"
+
+	anyEncoding = "*"
+)
+
+// NegotiateEncoding RETURNS THE CODING OUT OF br AND gzip THE CLIENT PREFERS, BROTLI WINS A TIE SINCE IT SHRINKS
+// TEXT FURTHER, AN EMPTY ENCODING MEANS THE BODY IS SENT AS IS
+func NegotiateEncoding(acceptEncoding string) string {
+	qualities := parseAccept(acceptEncoding)
+
+	quality := func(encoding string) float64 {
+		if q, ok := qualities[encoding]; ok {
+			return q
+		}
+
+		return qualities[anyEncoding]
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{BrotliEncoding, GzipEncoding} {
+		if q := quality(encoding); q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// CompressWriter HOLDS THE BODY BACK UNTIL IT REACHES minSize, ONLY THEN IT KNOWS THE COMPRESSION IS WORTH IT
+// AND SENDS THE HEADERS, A BODY THAT NEVER REACHES IT IS SENT AS IS ON Close
+type CompressWriter struct {
+	http.ResponseWriter
+	encoding     string
+	minSize      int
+	contentTypes map[string]bool
+
+	code    int
+	started bool
+	pending bytes.Buffer
+
+	encoder      io.WriteCloser
+	out          *countingWriter
+	uncompressed int
+}
+
+func NewCompressWriter(resp http.ResponseWriter, encoding string, minSize int, contentTypes map[string]bool) *CompressWriter {
+	return &CompressWriter{
+		ResponseWriter: resp,
+		encoding:       encoding,
+		minSize:        minSize,
+		contentTypes:   contentTypes,
+	}
+}
+
+func (cw *CompressWriter) WriteHeader(statusCode int) {
+	if cw.code == 0 {
+		cw.code = statusCode
+	}
+}
+
+func (cw *CompressWriter) Write(b []byte) (int, error) {
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+
+	if cw.started {
+		return cw.write(b)
+	}
+
+	cw.pending.Write(b)
+	if cw.pending.Len() < cw.minSize {
+		return len(b), nil
+	}
+
+	if err := cw.start(true); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// Close SENDS WHAT IS STILL HELD BACK AND ENDS THE COMPRESSED STREAM
+func (cw *CompressWriter) Close() error {
+	if !cw.started && cw.code != 0 {
+		if err := cw.start(false); err != nil {
+			return err
+		}
+	}
+
+	if cw.encoder == nil {
+		return nil
+	}
+
+	return cw.encoder.Close()
+}
+
+// Sizes RETURNS THE SIZE OF THE BODY BEFORE AND AFTER THE COMPRESSION, BOTH ARE ZERO WHEN IT WAS SENT AS IS
+func (cw *CompressWriter) Sizes() (int, int) {
+	if cw.encoder == nil {
+		return 0, 0
+	}
+
+	return cw.uncompressed, cw.out.n
+}
+
+func (cw *CompressWriter) start(compress bool) error {
+	cw.started = true
+
+	allowed := cw.allowed()
+	if allowed {
+		cw.Header().Add("Vary", "Accept-Encoding")
+	}
+
+	if compress && allowed && len(cw.encoding) != 0 && bodyAllowed(cw.code) && len(cw.Header().Get("Content-Encoding")) == 0 {
+		cw.Header().Set("Content-Encoding", cw.encoding)
+		cw.Header().Del("Content-Length")
+
+		cw.out = &countingWriter{Writer: cw.ResponseWriter}
+		cw.encoder = newEncoder(cw.encoding, cw.out)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.code)
+
+	if cw.pending.Len() == 0 {
+		return nil
+	}
+
+	_, err := cw.write(cw.pending.Bytes())
+	cw.pending.Reset()
+	return err
+}
+
+func (cw *CompressWriter) write(b []byte) (int, error) {
+	if cw.encoder == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.uncompressed += len(b)
+	return cw.encoder.Write(b)
+}
+
+func (cw *CompressWriter) allowed() bool {
+	mediaType, _, err := mime.ParseMediaType(cw.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return cw.contentTypes[mediaType]
+}
+
+func bodyAllowed(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == BrotliEncoding {
+		return brotli.NewWriter(w)
+	}
+
+	return gzip.NewWriter(w)
+}
+
+type countingWriter struct {
+	io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.Writer.Write(b)
+	cw.n += n
+	return n, err
+}
@@ -0,0 +1,185 @@
+package util_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := map[string]struct {
+		acceptEncoding   string
+		expectedEncoding string
+	}{
+		"test no header sends the body as is": {
+			acceptEncoding: "",
+		},
+		"test gzip is selected": {
+			acceptEncoding:   "gzip",
+			expectedEncoding: util.GzipEncoding,
+		},
+		"test brotli wins a tie": {
+			acceptEncoding:   "gzip, deflate, br",
+			expectedEncoding: util.BrotliEncoding,
+		},
+		"test higher quality wins": {
+			acceptEncoding:   "br;q=0.5, gzip;q=0.8",
+			expectedEncoding: util.GzipEncoding,
+		},
+		"test wildcard selects brotli": {
+			acceptEncoding:   "*",
+			expectedEncoding: util.BrotliEncoding,
+		},
+		"test wildcard does not select an excluded coding": {
+			acceptEncoding:   "br;q=0, *",
+			expectedEncoding: util.GzipEncoding,
+		},
+		"test unsupported coding sends the body as is": {
+			acceptEncoding: "deflate",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedEncoding, util.NegotiateEncoding(testCase.acceptEncoding))
+		})
+	}
+}
+
+func TestCompressWriter(t *testing.T) {
+	large := strings.Repeat("story body ", 100)
+
+	testCases := map[string]struct {
+		encoding                string
+		contentType             string
+		contentEncoding         string
+		code                    int
+		body                    string
+		expectedContentEncoding string
+		expectedVary            string
+		expectedCompressed      bool
+	}{
+		"test large json body is compressed in gzip": {
+			encoding:                util.GzipEncoding,
+			contentType:             "application/json",
+			code:                    http.StatusOK,
+			body:                    large,
+			expectedContentEncoding: util.GzipEncoding,
+			expectedVary:            "Accept-Encoding",
+			expectedCompressed:      true,
+		},
+		"test large json body is compressed in brotli": {
+			encoding:                util.BrotliEncoding,
+			contentType:             "application/json; charset=utf-8",
+			code:                    http.StatusCreated,
+			body:                    large,
+			expectedContentEncoding: util.BrotliEncoding,
+			expectedVary:            "Accept-Encoding",
+			expectedCompressed:      true,
+		},
+		"test small body is sent as is": {
+			encoding:     util.GzipEncoding,
+			contentType:  "application/json",
+			code:         http.StatusOK,
+			body:         "{}",
+			expectedVary: "Accept-Encoding",
+		},
+		"test body of other media type is sent as is": {
+			encoding:    util.GzipEncoding,
+			contentType: "text/plain",
+			code:        http.StatusOK,
+			body:        large,
+		},
+		"test body is sent as is when the client accepts no coding": {
+			contentType:  "application/json",
+			code:         http.StatusOK,
+			body:         large,
+			expectedVary: "Accept-Encoding",
+		},
+		"test encoded body is not encoded again": {
+			encoding:                util.GzipEncoding,
+			contentType:             "application/json",
+			contentEncoding:         "identity",
+			code:                    http.StatusOK,
+			body:                    large,
+			expectedContentEncoding: "identity",
+			expectedVary:            "Accept-Encoding",
+		},
+		"test no content is sent as is": {
+			encoding:     util.GzipEncoding,
+			contentType:  "application/json",
+			code:         http.StatusNoContent,
+			expectedVary: "Accept-Encoding",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			cw := util.NewCompressWriter(w, testCase.encoding, 100, map[string]bool{"application/json": true})
+			cw.Header().Set("Content-Type", testCase.contentType)
+			if len(testCase.contentEncoding) != 0 {
+				cw.Header().Set("Content-Encoding", testCase.contentEncoding)
+			}
+
+			cw.WriteHeader(testCase.code)
+			for i := 0; i < len(testCase.body); i += 64 {
+				end := i + 64
+				if end > len(testCase.body) {
+					end = len(testCase.body)
+				}
+
+				_, err := cw.Write([]byte(testCase.body[i:end]))
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, cw.Close())
+
+			written := w.Body.Len()
+
+			assert.Equal(t, testCase.code, w.Code)
+			assert.Equal(t, testCase.expectedContentEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, testCase.expectedVary, w.Header().Get("Vary"))
+			assert.Equal(t, testCase.body, decode(t, testCase.expectedCompressed, testCase.encoding, w.Body))
+
+			uncompressed, compressed := cw.Sizes()
+			if testCase.expectedCompressed {
+				assert.Equal(t, len(testCase.body), uncompressed)
+				assert.Equal(t, written, compressed)
+				assert.Less(t, compressed, uncompressed)
+			} else {
+				assert.Zero(t, uncompressed)
+				assert.Zero(t, compressed)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, compressed bool, encoding string, body *bytes.Buffer) string {
+	if !compressed {
+		return body.String()
+	}
+
+	var r io.Reader = brotli.NewReader(body)
+	if encoding == util.GzipEncoding {
+		gr, err := gzip.NewReader(body)
+		require.NoError(t, err)
+
+		r = gr
+	}
+
+	b, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(b)
+}
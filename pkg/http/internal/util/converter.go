@@ -2,10 +2,13 @@ package util
 
 import (
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"time"
 )
 
+const dayLayout = "2006-01-02"
+
 func ConvertToDTO(st *model.Story) contract.Story {
 	return contract.Story{
 		ID:        st.GetID(),
@@ -31,3 +34,43 @@ func ConvertToDAO(titleMaxLength, bodyMaxLength int, st contract.Story) (*model.
 		SetUpdatedAt(time.Unix(st.UpdatedAt, 0).UTC()).
 		Build()
 }
+
+func ConvertDailyStatsToDTO(ds *model.DailyStats) contract.DailyStats {
+	return contract.DailyStats{
+		Day:       ds.GetDay().Format(dayLayout),
+		Views:     ds.GetViews(),
+		UpVotes:   ds.GetUpVotes(),
+		DownVotes: ds.GetDownVotes(),
+	}
+}
+
+func ConvertMoverToDTO(m *model.Mover) contract.Mover {
+	return contract.Mover{
+		StoryID:  m.GetStoryID(),
+		Previous: m.GetPrevious(),
+		Current:  m.GetCurrent(),
+		Delta:    m.GetDelta(),
+	}
+}
+
+func ConvertToViolations(violations []liberr.FieldViolation) []contract.FieldViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	res := make([]contract.FieldViolation, len(violations))
+	for i, v := range violations {
+		res[i] = contract.FieldViolation{Field: v.Field, Code: string(v.Code), Message: v.Message}
+	}
+
+	return res
+}
+
+func ConvertToDay(day string) (time.Time, error) {
+	t, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return time.Time{}, liberr.WithArgs(liberr.Operation("ConvertToDay.time.Parse"), liberr.ValidationError, liberr.SeverityError, err)
+	}
+
+	return t, nil
+}
@@ -1,6 +1,7 @@
 package util_test
 
 import (
+	"errors"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/story/model"
@@ -74,3 +75,34 @@ func TestConvertRequestToDomainStory(t *testing.T) {
 
 	assert.Equal(t, ds, res)
 }
+
+func TestConvertToDay(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		expectedResult time.Time
+		expectedError  error
+	}{
+		"test convert to day success": {
+			input:          "2020-07-29",
+			expectedResult: time.Date(2020, 07, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"test convert to day failure for invalid day": {
+			input:         "29-07-2020",
+			expectedError: errors.New("parsing time \"29-07-2020\" as \"2006-01-02\": cannot parse \"29-07-2020\" as \"2006\""),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			res, err := util.ConvertToDay(testCase.input)
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
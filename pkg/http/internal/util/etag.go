@@ -0,0 +1,79 @@
+package util
+
+import (
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const anyETag = "*"
+
+// StoryETag IS A STRONG ETAG OF THE STORY MADE OF ITS ID AND UPDATE TIME, THE COUNTERS ARE LEFT OUT SO A VIEW OR A VOTE
+// DOES NOT FAIL THE If-Match OF A WRITER. IfMatch READS THE UPDATE TIME BACK FOR THE CONDITIONAL WRITES
+func StoryETag(st *model.Story) string {
+	return fmt.Sprintf(`"%s.%d"`, st.GetID(), st.GetUpdatedAt().UnixNano())
+}
+
+// NoneMatch IS TRUE WHEN If-None-Match NAMES THE ETAG, IT USES THE WEAK COMPARISON SO W/ TAGS MATCH TOO
+func NoneMatch(req *http.Request, etag string) bool {
+	for _, tag := range splitETags(req.Header.Get("If-None-Match")) {
+		if tag == anyETag || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IfMatch RETURNS THE UPDATE TIME THE If-Match OF THE REQUEST EXPECTS THE STORY TO HAVE, THE ZERO TIME WHEN THERE IS
+// NO If-Match OR IT IS *. IT IS FALSE WHEN NONE OF THE ETAGS CAN BE ONE OF THE STORY, IT USES THE STRONG COMPARISON SO
+// W/ TAGS NEVER MATCH. ONLY THE FIRST ETAG OF THE STORY IN THE LIST IS USED
+func IfMatch(req *http.Request, storyID string) (time.Time, bool) {
+	tags := splitETags(req.Header.Get("If-Match"))
+	if len(tags) == 0 {
+		return time.Time{}, true
+	}
+
+	for _, tag := range tags {
+		if tag == anyETag {
+			return time.Time{}, true
+		}
+	}
+
+	for _, tag := range tags {
+		if updatedAt, ok := parseStoryETag(tag, storyID); ok {
+			return updatedAt, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseStoryETag(tag, storyID string) (time.Time, bool) {
+	prefix := fmt.Sprintf(`"%s.`, storyID)
+	if !strings.HasPrefix(tag, prefix) || !strings.HasSuffix(tag, `"`) || len(tag) <= len(prefix) {
+		return time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(tag[len(prefix):len(tag)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, nanos).UTC(), true
+}
+
+func splitETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) != 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
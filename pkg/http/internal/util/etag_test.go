@@ -0,0 +1,82 @@
+package util_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStoryETag(t *testing.T) {
+	updatedAt := time.Date(2020, 07, 29, 16, 0, 0, 0, time.UTC)
+
+	st := &model.Story{ID: "adbca278-7e5c-4831-bf90-15fadfda0dd1", Title: "title", UpdatedAt: updatedAt}
+
+	etag := util.StoryETag(st)
+
+	assert.Equal(t, `"adbca278-7e5c-4831-bf90-15fadfda0dd1.1596038400000000000"`, etag)
+	assert.Equal(t, etag, util.StoryETag(&model.Story{ID: st.ID, Title: "title", UpdatedAt: updatedAt, ViewCount: 1, UpVotes: 1}))
+	assert.NotEqual(t, etag, util.StoryETag(&model.Story{ID: st.ID, UpdatedAt: updatedAt.Add(time.Microsecond)}))
+}
+
+func TestNoneMatch(t *testing.T) {
+	etag := `"abc"`
+
+	testCases := map[string]struct {
+		header         string
+		expectedResult bool
+	}{
+		"test no header":               {header: "", expectedResult: false},
+		"test same etag":               {header: `"abc"`, expectedResult: true},
+		"test weak etag":               {header: `W/"abc"`, expectedResult: true},
+		"test etag in list":            {header: `"xyz", "abc"`, expectedResult: true},
+		"test any":                     {header: "*", expectedResult: true},
+		"test other etag":              {header: `"xyz"`, expectedResult: false},
+		"test etag without the quotes": {header: "abc", expectedResult: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/stories", nil)
+			req.Header.Set("If-None-Match", testCase.header)
+
+			assert.Equal(t, testCase.expectedResult, util.NoneMatch(req, etag))
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	id := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+	updatedAt := time.Date(2020, 07, 29, 16, 0, 0, 1000, time.UTC)
+	etag := util.StoryETag(&model.Story{ID: id, UpdatedAt: updatedAt})
+
+	testCases := map[string]struct {
+		header            string
+		expectedUpdatedAt time.Time
+		expectedResult    bool
+	}{
+		"test no header":             {header: "", expectedResult: true},
+		"test etag of the story":     {header: etag, expectedUpdatedAt: updatedAt, expectedResult: true},
+		"test etag in list":          {header: `"xyz", ` + etag, expectedUpdatedAt: updatedAt, expectedResult: true},
+		"test any":                   {header: "*", expectedResult: true},
+		"test weak etag":             {header: "W/" + etag, expectedResult: false},
+		"test etag of another story": {header: `"ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a.1596038400000001000"`, expectedResult: false},
+		"test other etag":            {header: `"xyz"`, expectedResult: false},
+		"test malformed version":     {header: `"` + id + `.abc"`, expectedResult: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/stories", nil)
+			req.Header.Set("If-Match", testCase.header)
+
+			res, ok := util.IfMatch(req, id)
+
+			assert.Equal(t, testCase.expectedResult, ok)
+			assert.True(t, testCase.expectedUpdatedAt.Equal(res))
+		})
+	}
+}
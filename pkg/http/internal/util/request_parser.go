@@ -1,14 +1,17 @@
 package util
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/go-chi/chi"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 )
 
+// ParseRequest DECODES THE BODY IN THE MEDIA TYPE OF ITS Content-Type, A BODY THE DATA HAS NO
+// REPRESENTATION FOR IN THAT MEDIA TYPE IS AN UNSUPPORTED MEDIA TYPE ERROR
 func ParseRequest(req *http.Request, data interface{}) error {
 	if req == nil {
 		return e("", errors.New("request is nil"))
@@ -23,14 +26,47 @@ func ParseRequest(req *http.Request, data interface{}) error {
 		return e("ioutil.ReadAll", err)
 	}
 
-	err = json.Unmarshal(b, &data)
+	c := codecs.forContentType(req.Header.Get("Content-Type"))
+
+	err = c.unmarshal(b, data)
+	if errors.Is(err, errUnsupportedValue) {
+		return liberr.WithArgs(liberr.Operation("ParseRequest.codec.unmarshal"), liberr.UnsupportedMediaType, liberr.SeverityError, fmt.Errorf("unsupported media type %s", c.contentType()))
+	}
+
 	if err != nil {
-		return e("json.Unmarshal", err)
+		return e("codec.unmarshal", err)
 	}
 
 	return nil
 }
 
+// ParsePathParam RETURNS THE VALUE OF A {name} SEGMENT OF THE ROUTE, A MISSING VALUE IS A VALIDATION ERROR
+func ParsePathParam(req *http.Request, name string) (string, error) {
+	v := chi.URLParam(req, name)
+	if len(v) == 0 {
+		msg := fmt.Sprintf("missing path parameter %s", name)
+		return "", liberr.WithArgs(liberr.Operation("ParsePathParam"), liberr.ValidationError, liberr.SeverityError, liberr.CodeMissingParameter, liberr.NewFieldViolation(name, liberr.CodeMissingParameter, msg), errors.New(msg))
+	}
+
+	return v, nil
+}
+
+// ParseQueryInt RETURNS def WHEN THE PARAMETER IS NOT SET
+func ParseQueryInt(req *http.Request, name string, def int) (int, error) {
+	v := req.URL.Query().Get(name)
+	if len(v) == 0 {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		msg := fmt.Sprintf("invalid %s %s, expected an integer", name, v)
+		return 0, liberr.WithArgs(liberr.Operation("ParseQueryInt"), liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, liberr.NewFieldViolation(name, liberr.CodeInvalidParameter, msg), errors.New(msg))
+	}
+
+	return i, nil
+}
+
 //TODO: REMOVE THIS HELPER FUNCTION OR AT-LEAST RENAME
 func e(op string, err error) *liberr.Error {
 	opf := func() liberr.Operation {
@@ -2,15 +2,22 @@ package util_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"github.com/go-chi/chi"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/nsnikhil/stories-proto/proto"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/util"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v4"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -71,7 +78,7 @@ func TestParseRequest(t *testing.T) {
 				return r
 			},
 			expectedResult: CusReq{},
-			expectedError:  liberr.WithArgs(liberr.SeverityError, liberr.ValidationError, liberr.Operation("ParseRequest.json.Unmarshal"), errors.New("unexpected end of JSON input")),
+			expectedError:  liberr.WithArgs(liberr.SeverityError, liberr.ValidationError, liberr.Operation("ParseRequest.codec.unmarshal"), errors.New("unexpected end of JSON input")),
 		},
 		"test request parse failure when unmarshalling fails": {
 			input: func() *http.Request {
@@ -110,3 +117,130 @@ func TestParseRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRequestDecodesTheContentType(t *testing.T) {
+	msgpackBody, err := msgpack.Marshal(map[string]string{"title": "title", "body": "body"})
+	require.NoError(t, err)
+
+	protobufBody, err := protobuf.Marshal(&proto.AddStoryRequest{Story: &proto.Story{Title: "title", Body: "body"}})
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		contentType    string
+		body           []byte
+		expectedResult contract.AddStoryRequest
+	}{
+		"test json": {
+			contentType:    "application/json; charset=utf-8",
+			body:           []byte(`{"title":"title","body":"body"}`),
+			expectedResult: contract.AddStoryRequest{Title: "title", Body: "body"},
+		},
+		"test unknown content type is read as json": {
+			contentType:    "application/x-www-form-urlencoded",
+			body:           []byte(`{"title":"title","body":"body"}`),
+			expectedResult: contract.AddStoryRequest{Title: "title", Body: "body"},
+		},
+		"test msgpack": {
+			contentType:    "application/x-msgpack",
+			body:           msgpackBody,
+			expectedResult: contract.AddStoryRequest{Title: "title", Body: "body"},
+		},
+		"test protobuf": {
+			contentType:    "application/x-protobuf",
+			body:           protobufBody,
+			expectedResult: contract.AddStoryRequest{Title: "title", Body: "body"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/random", bytes.NewReader(testCase.body))
+			req.Header.Set("Content-Type", testCase.contentType)
+
+			var target contract.AddStoryRequest
+
+			require.NoError(t, util.ParseRequest(req, &target))
+			assert.Equal(t, testCase.expectedResult, target)
+		})
+	}
+}
+
+func TestParseRequestFailsWhenTheDataHasNoProtobufMessage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/random", bytes.NewReader([]byte{0x0a, 0x01, 0x74}))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	var target contract.PatchStoryRequest
+
+	err := util.ParseRequest(req, &target)
+	require.Error(t, err)
+
+	assert.Equal(t, liberr.UnsupportedMediaType, err.(*liberr.Error).Kind())
+	assert.Equal(t, "unsupported media type application/x-protobuf", err.Error())
+}
+
+func TestParsePathParam(t *testing.T) {
+	testCases := map[string]struct {
+		params         map[string]string
+		expectedResult string
+		expectedError  error
+	}{
+		"test parse path param success": {
+			params:         map[string]string{"id": "adbca278-7e5c-4831-bf90-15fadfda0dd1"},
+			expectedResult: "adbca278-7e5c-4831-bf90-15fadfda0dd1",
+		},
+		"test parse path param failure when param is missing": {
+			params:        map[string]string{},
+			expectedError: liberr.WithArgs(liberr.Operation("ParsePathParam"), liberr.ValidationError, liberr.SeverityError, liberr.CodeMissingParameter, liberr.NewFieldViolation("id", liberr.CodeMissingParameter, "missing path parameter id"), errors.New("missing path parameter id")),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			for k, v := range testCase.params {
+				rctx.URLParams.Add(k, v)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/random", nil)
+			require.NoError(t, err)
+
+			res, err := util.ParsePathParam(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)), "id")
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
+
+func TestParseQueryInt(t *testing.T) {
+	testCases := map[string]struct {
+		query          string
+		expectedResult int
+		expectedError  error
+	}{
+		"test parse query int success": {
+			query:          "limit=25",
+			expectedResult: 25,
+		},
+		"test parse query int returns default when param is not set": {
+			query:          "",
+			expectedResult: 10,
+		},
+		"test parse query int failure when param is not an integer": {
+			query:         "limit=ten",
+			expectedError: liberr.WithArgs(liberr.Operation("ParseQueryInt"), liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, liberr.NewFieldViolation("limit", liberr.CodeInvalidParameter, "invalid limit ten, expected an integer"), errors.New("invalid limit ten, expected an integer")),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/random?"+testCase.query, nil)
+			require.NoError(t, err)
+
+			res, err := util.ParseQueryInt(req, "limit", 10)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/resperr"
 	"net/http"
@@ -13,21 +14,68 @@ func writeResponse(code int, data []byte, resp http.ResponseWriter) {
 	_, _ = resp.Write(data)
 }
 
-func writeAPIResponse(code int, ar contract.APIResponse, resp http.ResponseWriter) {
-	b, err := json.Marshal(&ar)
+// writeBody WRITES THE BARE DATA WHEN THE CODEC HAS NO ENVELOPE, A VALUE THE CODEC CANNOT REPRESENT IS WRITTEN
+// AS THE ENVELOPE IN THE FALLBACK MEDIA TYPE
+func writeBody(c codec, code int, data, envelope interface{}, resp http.ResponseWriter) {
+	v := data
+	if c.enveloped() {
+		v = envelope
+	}
+
+	b, err := c.marshal(v)
+	if errors.Is(err, errUnsupportedValue) {
+		c = codecs.fallback
+		b, err = c.marshal(envelope)
+	}
+
 	if err != nil {
 		//TODO: SHOULD YOU WRITE INTERNAL SERVER ERROR WHEN MARSHALLING FAILS
 		writeResponse(http.StatusInternalServerError, []byte("internal server error"), resp)
 		return
 	}
 
+	resp.Header().Set("Content-Type", c.contentType())
 	writeResponse(code, b, resp)
 }
 
-func WriteSuccessResponse(statusCode int, data interface{}, resp http.ResponseWriter) {
-	writeAPIResponse(statusCode, contract.NewSuccessResponse(data), resp)
+// WriteSuccessResponse ENCODES THE DATA IN THE MEDIA TYPE THE CLIENT ACCEPTS
+func WriteSuccessResponse(statusCode int, data interface{}, resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Add("Vary", "Accept")
+
+	ar := contract.NewSuccessResponse(data)
+	writeBody(codecs.forAccept(req.Header.Get("Accept")), statusCode, data, &ar, resp)
 }
 
-func WriteFailureResponse(gr resperr.ResponseError, resp http.ResponseWriter) {
-	writeAPIResponse(gr.StatusCode(), contract.NewFailureResponse(gr.Description()), resp)
+// WriteFailureResponse WRITES THE APIResponse ENVELOPE UNLESS THE CLIENT PREFERS A PROBLEM DETAILS DOCUMENT,
+// A MEDIA TYPE WITHOUT AN ENVELOPE GETS THE ENVELOPE IN JSON
+func WriteFailureResponse(gr resperr.ResponseError, resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Add("Vary", "Accept")
+
+	violations := ConvertToViolations(gr.Violations())
+
+	if !acceptsProblem(req.Header.Get("Accept")) {
+		ar := contract.NewFailureResponse(string(gr.Code()), gr.Description(), violations...)
+		writeBody(codecs.forAccept(req.Header.Get("Accept")), gr.StatusCode(), nil, &ar, resp)
+		return
+	}
+
+	pr := contract.NewProblem(gr.StatusCode(), http.StatusText(gr.StatusCode()), gr.Description(), req.URL.Path, string(gr.Code()), violations...)
+
+	b, err := json.Marshal(&pr)
+	if err != nil {
+		writeResponse(http.StatusInternalServerError, []byte("internal server error"), resp)
+		return
+	}
+
+	resp.Header().Set("Content-Type", contract.ProblemContentType)
+	writeResponse(gr.StatusCode(), b, resp)
+}
+
+// acceptsProblem IS TRUE WHEN THE ACCEPT HEADER NAMES problem+json WITH A QUALITY NOT LOWER THAN THAT OF JSON,
+// WILDCARDS NEVER SELECT IT SO CLIENTS THAT DO NOT ASK FOR IT KEEP GETTING THE ENVELOPE
+func acceptsProblem(accept string) bool {
+	qualities := parseAccept(accept)
+
+	problemQ := qualities[contract.ProblemContentType]
+	return problemQ > 0 && problemQ >= qualities[contract.JSONContentType]
 }
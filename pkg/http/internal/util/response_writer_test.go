@@ -1,9 +1,15 @@
 package util_test
 
 import (
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/nsnikhil/stories-proto/proto"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
 	"github.com/nsnikhil/stories/pkg/http/internal/resperr"
 	"github.com/nsnikhil/stories/pkg/http/internal/util"
+	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v4"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,7 +34,7 @@ func TestWriteSuccessResponse(t *testing.T) {
 
 				w := httptest.NewRecorder()
 
-				util.WriteSuccessResponse(http.StatusCreated, cr, w)
+				util.WriteSuccessResponse(http.StatusCreated, cr, w, httptest.NewRequest(http.MethodGet, "/random", nil))
 
 				return w.Body.String(), w.Code
 			},
@@ -42,7 +48,7 @@ func TestWriteSuccessResponse(t *testing.T) {
 
 				c := make(chan int)
 
-				util.WriteSuccessResponse(http.StatusCreated, c, w)
+				util.WriteSuccessResponse(http.StatusCreated, c, w, httptest.NewRequest(http.MethodGet, "/random", nil))
 
 				return w.Body.String(), w.Code
 			},
@@ -71,16 +77,30 @@ func TestWriteFailureResponse(t *testing.T) {
 		{
 			name: "write failure response success",
 			actualResult: func() (string, int) {
-				err := resperr.NewResponseError(http.StatusBadRequest, "failed to parse")
+				err := resperr.NewResponseError(http.StatusBadRequest, liberr.CodeInvalidRequest, "failed to parse")
 
 				w := httptest.NewRecorder()
 
-				util.WriteFailureResponse(err, w)
+				util.WriteFailureResponse(err, w, httptest.NewRequest(http.MethodGet, "/stories", nil))
 
 				return w.Body.String(), w.Code
 			},
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: "{\"error\":{\"message\":\"failed to parse\"},\"success\":false}",
+			expectedResult: "{\"error\":{\"code\":\"INVALID_REQUEST\",\"message\":\"failed to parse\"},\"success\":false}",
+		},
+		{
+			name: "write failure response with field violations",
+			actualResult: func() (string, int) {
+				err := resperr.NewResponseError(http.StatusBadRequest, liberr.CodeTitleRequired, "title cannot be empty", liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"))
+
+				w := httptest.NewRecorder()
+
+				util.WriteFailureResponse(err, w, httptest.NewRequest(http.MethodGet, "/stories", nil))
+
+				return w.Body.String(), w.Code
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: "{\"error\":{\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\",\"violations\":[{\"field\":\"title\",\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\"}]},\"success\":false}",
 		},
 	}
 
@@ -93,3 +113,161 @@ func TestWriteFailureResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFailureResponseNegotiatesTheContentType(t *testing.T) {
+	envelope := "{\"error\":{\"code\":\"STORY_NOT_FOUND\",\"message\":\"requested resource was not found\"},\"success\":false}"
+	problem := "{\"type\":\"about:blank\",\"title\":\"Not Found\",\"status\":404,\"detail\":\"requested resource was not found\",\"instance\":\"/stories/some-id\",\"code\":\"STORY_NOT_FOUND\"}"
+
+	testCases := map[string]struct {
+		accept              string
+		expectedContentType string
+		expectedResult      string
+	}{
+		"test no accept header gets the envelope": {
+			expectedContentType: "application/json",
+			expectedResult:      envelope,
+		},
+		"test json gets the envelope": {
+			accept:              "application/json",
+			expectedContentType: "application/json",
+			expectedResult:      envelope,
+		},
+		"test wildcard gets the envelope": {
+			accept:              "*/*",
+			expectedContentType: "application/json",
+			expectedResult:      envelope,
+		},
+		"test problem json gets a problem": {
+			accept:              "application/problem+json",
+			expectedContentType: "application/problem+json",
+			expectedResult:      problem,
+		},
+		"test problem json preferred over json gets a problem": {
+			accept:              "application/json;q=0.5, Application/Problem+JSON",
+			expectedContentType: "application/problem+json",
+			expectedResult:      problem,
+		},
+		"test json preferred over problem json gets the envelope": {
+			accept:              "application/problem+json; q=0.2, application/json",
+			expectedContentType: "application/json",
+			expectedResult:      envelope,
+		},
+		"test refused problem json gets the envelope": {
+			accept:              "application/problem+json;q=0",
+			expectedContentType: "application/json",
+			expectedResult:      envelope,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/stories/some-id", nil)
+			req.Header.Set("Accept", testCase.accept)
+
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Type", "application/json")
+
+			util.WriteFailureResponse(resperr.NewResponseError(http.StatusNotFound, liberr.CodeStoryNotFound, "requested resource was not found"), w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, testCase.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.Equal(t, testCase.expectedResult, w.Body.String())
+		})
+	}
+}
+
+func TestWriteFailureResponseWritesViolationsInAProblem(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/stories", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	w := httptest.NewRecorder()
+
+	err := resperr.NewResponseError(http.StatusBadRequest, liberr.CodeTitleRequired, "title cannot be empty", liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty"))
+
+	util.WriteFailureResponse(err, w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "{\"type\":\"about:blank\",\"title\":\"Bad Request\",\"status\":400,\"detail\":\"title cannot be empty\",\"instance\":\"/stories\",\"code\":\"TITLE_REQUIRED\",\"violations\":[{\"field\":\"title\",\"code\":\"TITLE_REQUIRED\",\"message\":\"title cannot be empty\"}]}", w.Body.String())
+}
+
+func TestWriteSuccessResponseNegotiatesTheCodec(t *testing.T) {
+	story := contract.Story{ID: "adbca278-7e5c-4831-bf90-15fadfda0dd1", Title: "title", Body: "body", ViewCount: 2}
+
+	testCases := map[string]struct {
+		accept              string
+		data                interface{}
+		expectedContentType string
+		assertBody          func(t *testing.T, body []byte)
+	}{
+		"test json": {
+			accept:              "application/json",
+			data:                contract.DeleteStoryResponse{Success: true},
+			expectedContentType: "application/json",
+			assertBody: func(t *testing.T, body []byte) {
+				assert.Equal(t, "{\"data\":{\"success\":true},\"success\":true}", string(body))
+			},
+		},
+		"test msgpack uses the json field names": {
+			accept:              "application/x-msgpack",
+			data:                contract.DeleteStoryResponse{Success: true},
+			expectedContentType: "application/x-msgpack",
+			assertBody: func(t *testing.T, body []byte) {
+				var res map[string]interface{}
+				require.NoError(t, msgpack.Unmarshal(body, &res))
+
+				assert.Equal(t, map[string]interface{}{"data": map[string]interface{}{"success": true}, "success": true}, res)
+			},
+		},
+		"test protobuf writes the bare message": {
+			accept:              "application/json;q=0.5, application/x-protobuf",
+			data:                story,
+			expectedContentType: "application/x-protobuf",
+			assertBody: func(t *testing.T, body []byte) {
+				var res proto.Story
+				require.NoError(t, protobuf.Unmarshal(body, &res))
+
+				assert.Equal(t, story.ID, res.GetId())
+				assert.Equal(t, story.Title, res.GetTitle())
+				assert.Equal(t, story.Body, res.GetBody())
+				assert.Equal(t, story.ViewCount, res.GetViews())
+			},
+		},
+		"test protobuf falls back to json when the data has no message": {
+			accept:              "application/x-protobuf",
+			data:                contract.CreateStoryResponse{ID: story.ID},
+			expectedContentType: "application/json",
+			assertBody: func(t *testing.T, body []byte) {
+				assert.Equal(t, "{\"data\":{\"id\":\"adbca278-7e5c-4831-bf90-15fadfda0dd1\"},\"success\":true}", string(body))
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/random", nil)
+			req.Header.Set("Accept", testCase.accept)
+
+			w := httptest.NewRecorder()
+
+			util.WriteSuccessResponse(http.StatusOK, testCase.data, w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, testCase.expectedContentType, w.Header().Get("Content-Type"))
+			testCase.assertBody(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestWriteFailureResponseWritesJSONWhenTheCodecHasNoEnvelope(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/random", nil)
+	req.Header.Set("Accept", "application/x-protobuf")
+
+	w := httptest.NewRecorder()
+
+	util.WriteFailureResponse(resperr.NewResponseError(http.StatusNotFound, liberr.CodeStoryNotFound, "requested resource was not found"), w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"error\":{\"code\":\"STORY_NOT_FOUND\",\"message\":\"requested resource was not found\"},\"success\":false}", w.Body.String())
+}
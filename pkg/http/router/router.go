@@ -5,10 +5,12 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/newrelic/go-agent/v3/integrations/nrgorilla"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/nsnikhil/stories/pkg/auth"
 	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/http/internal/handler"
 	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
@@ -16,7 +18,9 @@ import (
 )
 
 const (
-	pingAPI = "ping"
+	pingAPI    = "ping"
+	metricAPI  = "metrics"
+	openAPIAPI = "openAPI"
 
 	addAPI        = "add"
 	getAPI        = "get"
@@ -25,6 +29,19 @@ const (
 	topRatedAPI   = "topRated"
 	searchAPI     = "search"
 	updateAPI     = "update"
+	viewAPI       = "view"
+	upVoteAPI     = "upVote"
+	downVoteAPI   = "downVote"
+	timeSeriesAPI = "timeSeries"
+	topMoversAPI  = "topMovers"
+
+	listStoriesAPI  = "listStories"
+	createStoryAPI  = "createStory"
+	getStoryByIDAPI = "getStoryByID"
+	patchStoryAPI   = "patchStory"
+	deleteStoryAPI  = "deleteStoryByID"
+	viewStoryAPI    = "viewStory"
+	voteStoryAPI    = "voteStory"
 
 	pingPath = "/ping"
 
@@ -36,29 +53,50 @@ const (
 	topRatedPath   = "/top-rated"
 	searchPath     = "/search"
 	updatePath     = "/update"
+	viewPath       = "/view"
+	upVotePath     = "/up-vote"
+	downVotePath   = "/down-vote"
+
+	analyticsPath  = "/analytics"
+	timeSeriesPath = "/time-series"
+	topMoversPath  = "/top-movers"
 
-	metricPath = "/metrics"
+	storiesPath = "/stories"
+	rootPath    = "/"
+	storyIDPath = "/{" + handler.StoryIDParam + "}"
+	viewsPath   = "/views"
+	votesPath   = "/votes"
+
+	metricPath  = "/metrics"
+	openAPIPath = "/openapi.json"
 )
 
-func NewRouter(cfg config.StoryConfig, lgr *zap.Logger, newRelic *newrelic.Application, prometheus reporters.Prometheus, svc service.StoryService) http.Handler {
-	return getChiRouter(cfg, lgr, newRelic, prometheus, svc)
+// NewRouter AUTHENTICATES EVERY STORY API WITH authn AND RATE LIMITS EVERY API THAT HAS A LIMITER IN rl,
+// PING AND THE SPEC STAY ANONYMOUS
+func NewRouter(cfg config.Config, lgr *zap.Logger, newRelic *newrelic.Application, prometheus reporters.Prometheus, svc service.StoryService, authn auth.Authenticator, rl map[string]resilience.RateLimiter) http.Handler {
+	return getChiRouter(cfg, lgr, newRelic, prometheus, svc, authn, rl)
 }
 
-func getChiRouter(cfg config.StoryConfig, lgr *zap.Logger, newRelic *newrelic.Application, pr reporters.Prometheus, svc service.StoryService) *chi.Mux {
+func getChiRouter(cfg config.Config, lgr *zap.Logger, newRelic *newrelic.Application, pr reporters.Prometheus, svc service.StoryService, authn auth.Authenticator, rl map[string]resilience.RateLimiter) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
+	if cfg.RateLimitConfig().TrustProxyHeaders() {
+		r.Use(middleware.RealIP)
+	}
 	r.Use(nrgorilla.Middleware(newRelic))
+	r.Use(mdl.WithCompression(cfg.HTTPCompressionConfig(), lgr, pr))
 
 	//TODO: SHOULD ANY MIDDLEWARE BE ADDED TO PING API ?
-	r.Get(pingPath, withMiddlewares(lgr, pr, pingAPI, handler.PingHandler()))
-	r.Handle(metricPath, promhttp.Handler())
+	r.Get(pingPath, withMiddlewares(lgr, pr, nil, rl, pingAPI, handler.PingHandler()))
+	r.Method(http.MethodGet, metricPath, promhttp.Handler())
+	r.Get(openAPIPath, withMiddlewares(lgr, pr, nil, rl, openAPIAPI, specHandler))
 
-	addStoryRoutes(cfg, lgr, pr, svc, r)
+	addStoryRoutes(cfg.StoryConfig(), cfg.HTTPCacheConfig(), lgr, pr, authn, rl, svc, r)
 
 	return r
 }
 
-func addStoryRoutes(cfg config.StoryConfig, lgr *zap.Logger, pr reporters.Prometheus, svc service.StoryService, r chi.Router) {
+func addStoryRoutes(cfg config.StoryConfig, cc config.HTTPCacheConfig, lgr *zap.Logger, pr reporters.Prometheus, authn auth.Authenticator, rl map[string]resilience.RateLimiter, svc service.StoryService, r chi.Router) {
 	ah := handler.NewAddHandler(cfg, svc)
 	gh := handler.NewGetStoryHandler(svc)
 	dh := handler.NewDeleteStoryHandler(svc)
@@ -66,22 +104,49 @@ func addStoryRoutes(cfg config.StoryConfig, lgr *zap.Logger, pr reporters.Promet
 	trh := handler.NewGetTopRatedStoriesHandler(svc)
 	sh := handler.NewSearchStoriesHandler(svc)
 	uh := handler.NewUpdateStoryHandler(cfg, svc)
+	tsh := handler.NewGetStoryTimeSeriesHandler(svc)
+	tmh := handler.NewGetTopMoversHandler(svc)
+	ch := handler.NewStoryCountersHandler(svc)
+	lh := handler.NewListStoriesHandler(svc)
 
+	r.Route(storiesPath, func(r chi.Router) {
+		r.Get(rootPath, withMiddlewares(lgr, pr, authn, rl, listStoriesAPI, mdl.WithCacheControl(cc.ListStories(), mdl.WithError(lgr, lh.ListStories))))
+		r.Post(rootPath, withMiddlewares(lgr, pr, authn, rl, createStoryAPI, mdl.WithError(lgr, ah.CreateStory)))
+		r.Get(storyIDPath, withMiddlewares(lgr, pr, authn, rl, getStoryByIDAPI, mdl.WithCacheControl(cc.GetStory(), mdl.WithError(lgr, gh.GetStoryByID))))
+		r.Patch(storyIDPath, withMiddlewares(lgr, pr, authn, rl, patchStoryAPI, mdl.WithError(lgr, uh.PatchStory)))
+		r.Delete(storyIDPath, withMiddlewares(lgr, pr, authn, rl, deleteStoryAPI, mdl.WithError(lgr, dh.DeleteStoryByID)))
+		r.Post(storyIDPath+viewsPath, withMiddlewares(lgr, pr, authn, rl, viewStoryAPI, mdl.WithError(lgr, ch.ViewStoryByID)))
+		r.Post(storyIDPath+votesPath, withMiddlewares(lgr, pr, authn, rl, voteStoryAPI, mdl.WithError(lgr, ch.VoteStory)))
+	})
+
+	//TODO: REMOVE THE DEPRECATED ROUTES ONCE THE CLIENTS MOVE TO /stories
 	r.Route(storyPath, func(r chi.Router) {
-		r.Post(addPath, withMiddlewares(lgr, pr, addAPI, mdl.WithError(lgr, ah.AddStory)))
-		r.Get(getPath, withMiddlewares(lgr, pr, getAPI, mdl.WithError(lgr, gh.GetStory)))
-		r.Delete(deletePath, withMiddlewares(lgr, pr, deleteAPI, mdl.WithError(lgr, dh.DeleteStory)))
-		r.Get(mostViewedPath, withMiddlewares(lgr, pr, mostViewedAPI, mdl.WithError(lgr, mvh.GetMostViewedStories)))
-		r.Get(topRatedPath, withMiddlewares(lgr, pr, topRatedAPI, mdl.WithError(lgr, trh.GetTopRatedStories)))
-		r.Get(searchPath, withMiddlewares(lgr, pr, searchAPI, mdl.WithError(lgr, sh.SearchStories)))
-		r.Patch(updatePath, withMiddlewares(lgr, pr, updateAPI, mdl.WithError(lgr, uh.UpdateStory)))
+		r.Post(addPath, withMiddlewares(lgr, pr, authn, rl, addAPI, mdl.WithDeprecation(storiesPath, mdl.WithError(lgr, ah.AddStory))))
+		r.Get(getPath, withMiddlewares(lgr, pr, authn, rl, getAPI, mdl.WithDeprecation(storiesPath+storyIDPath, mdl.WithCacheControl(cc.GetStory(), mdl.WithError(lgr, gh.GetStory)))))
+		r.Delete(deletePath, withMiddlewares(lgr, pr, authn, rl, deleteAPI, mdl.WithDeprecation(storiesPath+storyIDPath, mdl.WithError(lgr, dh.DeleteStory))))
+		r.Get(mostViewedPath, withMiddlewares(lgr, pr, authn, rl, mostViewedAPI, mdl.WithDeprecation(storiesPath+"?sort=views", mdl.WithCacheControl(cc.ListStories(), mdl.WithError(lgr, mvh.GetMostViewedStories)))))
+		r.Get(topRatedPath, withMiddlewares(lgr, pr, authn, rl, topRatedAPI, mdl.WithDeprecation(storiesPath+"?sort=rating", mdl.WithCacheControl(cc.ListStories(), mdl.WithError(lgr, trh.GetTopRatedStories)))))
+		r.Get(searchPath, withMiddlewares(lgr, pr, authn, rl, searchAPI, mdl.WithCacheControl(cc.SearchStories(), mdl.WithError(lgr, sh.SearchStories))))
+		r.Patch(updatePath, withMiddlewares(lgr, pr, authn, rl, updateAPI, mdl.WithDeprecation(storiesPath+storyIDPath, mdl.WithError(lgr, uh.UpdateStory))))
+		r.Post(viewPath, withMiddlewares(lgr, pr, authn, rl, viewAPI, mdl.WithDeprecation(storiesPath+storyIDPath+viewsPath, mdl.WithError(lgr, ch.ViewStory))))
+		r.Post(upVotePath, withMiddlewares(lgr, pr, authn, rl, upVoteAPI, mdl.WithDeprecation(storiesPath+storyIDPath+votesPath, mdl.WithError(lgr, ch.UpVoteStory))))
+		r.Post(downVotePath, withMiddlewares(lgr, pr, authn, rl, downVoteAPI, mdl.WithDeprecation(storiesPath+storyIDPath+votesPath, mdl.WithError(lgr, ch.DownVoteStory))))
+
+		r.Route(analyticsPath, func(r chi.Router) {
+			r.Get(timeSeriesPath, withMiddlewares(lgr, pr, authn, rl, timeSeriesAPI, mdl.WithCacheControl(cc.Analytics(), mdl.WithError(lgr, tsh.GetStoryTimeSeries))))
+			r.Get(topMoversPath, withMiddlewares(lgr, pr, authn, rl, topMoversAPI, mdl.WithCacheControl(cc.Analytics(), mdl.WithError(lgr, tmh.GetTopMovers))))
+		})
 	})
 }
 
-func withMiddlewares(lgr *zap.Logger, prometheus reporters.Prometheus, api string, handler func(resp http.ResponseWriter, req *http.Request)) http.HandlerFunc {
+// withMiddlewares AUTHENTICATES BEFORE RATE LIMITING SO AN AUTHENTICATED CLIENT IS LIMITED BY ITS PRINCIPAL,
+// REJECTED CALLS ARE STILL REPORTED AS FAILURES OF THE API
+func withMiddlewares(lgr *zap.Logger, prometheus reporters.Prometheus, authn auth.Authenticator, rl map[string]resilience.RateLimiter, api string, handler func(resp http.ResponseWriter, req *http.Request)) http.HandlerFunc {
+	chain := mdl.WithAuthentication(authn, lgr)(mdl.WithRateLimit(rl[api], api, prometheus)(mdl.WithSession(http.HandlerFunc(handler))))
+
 	return mdl.WithReqRespLog(lgr,
 		mdl.WithResponseHeaders(
-			mdl.WithPrometheus(prometheus, api, handler),
+			mdl.WithPrometheus(prometheus, api, chain.ServeHTTP),
 		),
 	)
 }
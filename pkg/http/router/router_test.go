@@ -5,24 +5,43 @@ import (
 	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/http/router"
 	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/nsnikhil/stories/pkg/story/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestRouter(t *testing.T) {
+	storyID := "adbca278-7e5c-4831-bf90-15fadfda0dd1"
+
 	cfg := config.NewConfig("../../../local.env")
 
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportAttempt", mock.AnythingOfType("string"))
+	pr.On("ReportSuccess", mock.AnythingOfType("string"))
+	pr.On("ReportFailure", mock.AnythingOfType("string"))
+	pr.On("Observe", mock.AnythingOfType("string"), mock.AnythingOfType("float64"))
+
+	svc := &service.MockStoriesService{}
+	svc.On("GetMostViewsStories", mock.Anything, 0, 10).Return([]model.Story{}, nil)
+	svc.On("GetStory", mock.Anything, storyID).Return(&model.Story{ID: storyID}, nil)
+	svc.On("DeleteStory", mock.Anything, storyID, time.Time{}).Return(int64(1), nil)
+	svc.On("ViewStory", mock.Anything, storyID).Return(nil)
+
 	r := router.NewRouter(
-		cfg.StoryConfig(),
+		cfg,
 		zap.NewNop(),
 		&newrelic.Application{},
-		&reporters.MockPrometheus{},
-		&service.MockStoriesService{},
+		pr,
+		svc,
+		nil,
+		nil,
 	)
 
 	rf := func(method, path string) *http.Request {
@@ -39,6 +58,9 @@ func TestRouter(t *testing.T) {
 		"test ping route": {
 			request: rf(http.MethodGet, "/ping"),
 		},
+		"test openapi route": {
+			request: rf(http.MethodGet, "/openapi.json"),
+		},
 		"test add story route": {
 			request: rf(http.MethodPost, "/story/add"),
 		},
@@ -60,6 +82,42 @@ func TestRouter(t *testing.T) {
 		"test update story route": {
 			request: rf(http.MethodPost, "/story/update"),
 		},
+		"test view story route": {
+			request: rf(http.MethodPost, "/story/view"),
+		},
+		"test up vote story route": {
+			request: rf(http.MethodPost, "/story/up-vote"),
+		},
+		"test down vote story route": {
+			request: rf(http.MethodPost, "/story/down-vote"),
+		},
+		"test story time series route": {
+			request: rf(http.MethodGet, "/story/analytics/time-series"),
+		},
+		"test top movers route": {
+			request: rf(http.MethodGet, "/story/analytics/top-movers"),
+		},
+		"test list stories route": {
+			request: rf(http.MethodGet, "/stories"),
+		},
+		"test create story route": {
+			request: rf(http.MethodPost, "/stories"),
+		},
+		"test get story by id route": {
+			request: rf(http.MethodGet, "/stories/"+storyID),
+		},
+		"test patch story route": {
+			request: rf(http.MethodPatch, "/stories/"+storyID),
+		},
+		"test delete story by id route": {
+			request: rf(http.MethodDelete, "/stories/"+storyID),
+		},
+		"test view story by id route": {
+			request: rf(http.MethodPost, "/stories/"+storyID+"/views"),
+		},
+		"test vote story route": {
+			request: rf(http.MethodPost, "/stories/"+storyID+"/votes"),
+		},
 	}
 
 	for name, testCase := range testCases {
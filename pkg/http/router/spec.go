@@ -0,0 +1,192 @@
+package router
+
+import (
+	_ "embed"
+	"github.com/nsnikhil/stories/pkg/http/internal/contract"
+	"github.com/nsnikhil/stories/pkg/http/internal/handler"
+	mdl "github.com/nsnikhil/stories/pkg/http/internal/middleware"
+	"github.com/nsnikhil/stories/pkg/http/internal/openapi"
+	"net/http"
+)
+
+const (
+	specTitle   = "stories"
+	specVersion = "1.0.0"
+
+	apiKeyScheme = "apiKey"
+	bearerScheme = "bearer"
+)
+
+// THE SPEC IS GENERATED FROM specRoutes AND THE CONTRACT TYPES, REGENERATE IT WITH make openapi
+//
+//go:embed openapi.json
+var spec []byte
+
+func specHandler(resp http.ResponseWriter, req *http.Request) {
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write(spec)
+}
+
+func generateSpec() ([]byte, error) {
+	info := openapi.Info{
+		Title:       specTitle,
+		Description: "every JSON response is wrapped in the APIResponse envelope, failures are a Problem instead when the client accepts application/problem+json",
+		Version:     specVersion,
+	}
+
+	errorBodies := map[string]interface{}{
+		contract.JSONContentType:    contract.APIResponse{},
+		contract.ProblemContentType: contract.Problem{},
+	}
+
+	// A REQUEST WITHOUT CREDENTIALS IS ONLY SERVED WHEN AUTH_REQUIRED IS NOT SET
+	security := map[string]*openapi.SecurityScheme{
+		apiKeyScheme: openapi.APIKeySecurity(mdl.APIKeyHeader, "api key created with the api-key create command"),
+		bearerScheme: openapi.BearerSecurity("JWT", "HS256 or RS256 token with a sub and an exp claim"),
+	}
+
+	return openapi.NewDocument(info, errorBodies, specRoutes()...).WithSecurity(security).JSON()
+}
+
+func specRoutes() []openapi.Route {
+	storyID := openapi.PathParam(handler.StoryIDParam, "id of the story")
+	ifNoneMatch := openapi.HeaderParam("If-None-Match", "etag of the story the client has, answered with 304 when it is still current")
+	ifMatch := openapi.HeaderParam("If-Match", "etag of the story the change is based on, answered with 412 when the story has changed since")
+
+	bound := func(n int) *int {
+		return &n
+	}
+
+	legacy := func(method, path, operationID, summary string, request, response interface{}, status int) openapi.Route {
+		return openapi.Route{
+			Method:      method,
+			Path:        storyPath + path,
+			OperationID: operationID,
+			Summary:     summary,
+			Deprecated:  true,
+			Request:     request,
+			Status:      status,
+			Response:    response,
+		}
+	}
+
+	counter := func(path, operationID, summary string) openapi.Route {
+		return openapi.Route{
+			Method:      http.MethodPost,
+			Path:        storyPath + path,
+			OperationID: operationID,
+			Summary:     summary,
+			Deprecated:  true,
+			Request:     contract.StoryCounterRequest{},
+			Status:      http.StatusAccepted,
+			Response:    contract.StoryCounterResponse{},
+		}
+	}
+
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: pingPath, OperationID: pingAPI, Summary: "health check", Status: http.StatusOK, Response: "", Public: true},
+		{Method: http.MethodGet, Path: metricPath, OperationID: metricAPI, Summary: "prometheus metrics", Status: http.StatusOK, Response: "", ContentType: "text/plain", Public: true},
+		{Method: http.MethodGet, Path: openAPIPath, OperationID: openAPIAPI, Summary: "this document", Status: http.StatusOK, ContentType: "application/json", Public: true},
+
+		{
+			Method:      http.MethodGet,
+			Path:        storiesPath,
+			OperationID: listStoriesAPI,
+			Summary:     "list a page of stories",
+			Parameters: []openapi.Parameter{
+				openapi.QueryParam("sort", "order of the stories, defaults to views", &openapi.Schema{Type: "string", Enum: []string{"views", "rating"}}),
+				openapi.QueryParam("offset", "number of stories to skip, defaults to 0", &openapi.Schema{Type: "integer", Minimum: bound(0)}),
+				openapi.QueryParam("limit", "number of stories to return, defaults to 10", &openapi.Schema{Type: "integer", Minimum: bound(1), Maximum: bound(100)}),
+			},
+			Status:   http.StatusOK,
+			Response: []contract.Story{},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        storiesPath,
+			OperationID: createStoryAPI,
+			Summary:     "create a story, the Location header points at it",
+			Request:     contract.AddStoryRequest{},
+			Status:      http.StatusCreated,
+			Response:    contract.CreateStoryResponse{},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        storiesPath + storyIDPath,
+			OperationID: getStoryByIDAPI,
+			Summary:     "get a story, the ETag header identifies its version",
+			Parameters:  []openapi.Parameter{storyID, ifNoneMatch},
+			Status:      http.StatusOK,
+			Response:    contract.Story{},
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        storiesPath + storyIDPath,
+			OperationID: patchStoryAPI,
+			Summary:     "change the title or the body of a story",
+			Parameters:  []openapi.Parameter{storyID, ifMatch},
+			Request:     contract.PatchStoryRequest{},
+			Status:      http.StatusOK,
+			Response:    contract.UpdateStoryResponse{},
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        storiesPath + storyIDPath,
+			OperationID: deleteStoryAPI,
+			Summary:     "delete a story",
+			Parameters:  []openapi.Parameter{storyID, ifMatch},
+			Status:      http.StatusNoContent,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        storiesPath + storyIDPath + viewsPath,
+			OperationID: viewStoryAPI,
+			Summary:     "count a view of a story",
+			Parameters:  []openapi.Parameter{storyID},
+			Status:      http.StatusAccepted,
+			Response:    contract.StoryCounterResponse{},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        storiesPath + storyIDPath + votesPath,
+			OperationID: voteStoryAPI,
+			Summary:     "vote a story, the direction is up or down",
+			Parameters:  []openapi.Parameter{storyID},
+			Request:     contract.VoteStoryRequest{},
+			Status:      http.StatusAccepted,
+			Response:    contract.StoryCounterResponse{},
+		},
+
+		legacy(http.MethodPost, addPath, addAPI, "use POST /stories", contract.AddStoryRequest{}, contract.AddStoryResponse{}, http.StatusCreated),
+		legacy(http.MethodGet, getPath, getAPI, "use GET /stories/{id}", contract.GetStoryRequest{}, contract.Story{}, http.StatusOK),
+		legacy(http.MethodDelete, deletePath, deleteAPI, "use DELETE /stories/{id}", contract.DeleteStoryRequest{}, contract.DeleteStoryResponse{}, http.StatusOK),
+		legacy(http.MethodGet, mostViewedPath, mostViewedAPI, "use GET /stories?sort=views", contract.MostViewedStoriesRequest{}, []contract.Story{}, http.StatusOK),
+		legacy(http.MethodGet, topRatedPath, topRatedAPI, "use GET /stories?sort=rating", contract.TopRatedStoriesRequest{}, []contract.Story{}, http.StatusOK),
+		legacy(http.MethodPatch, updatePath, updateAPI, "use PATCH /stories/{id}", contract.UpdateStoryRequest{}, contract.UpdateStoryResponse{}, http.StatusOK),
+
+		{Method: http.MethodGet, Path: storyPath + searchPath, OperationID: searchAPI, Summary: "search stories, not implemented yet"},
+
+		counter(viewPath, viewAPI, "use POST /stories/{id}/views"),
+		counter(upVotePath, upVoteAPI, "use POST /stories/{id}/votes"),
+		counter(downVotePath, downVoteAPI, "use POST /stories/{id}/votes"),
+
+		{
+			Method:      http.MethodGet,
+			Path:        storyPath + analyticsPath + timeSeriesPath,
+			OperationID: timeSeriesAPI,
+			Summary:     "daily views and votes of a story, the days are YYYY-MM-DD",
+			Request:     contract.StoryTimeSeriesRequest{},
+			Status:      http.StatusOK,
+			Response:    contract.StoryTimeSeriesResponse{},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        storyPath + analyticsPath + topMoversPath,
+			OperationID: topMoversAPI,
+			Summary:     "stories whose metric grew the most between two windows",
+			Request:     contract.TopMoversRequest{},
+			Status:      http.StatusOK,
+			Response:    contract.TopMoversResponse{},
+		},
+	}
+}
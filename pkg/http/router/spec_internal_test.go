@@ -0,0 +1,87 @@
+package router
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/go-chi/chi"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/http/internal/openapi"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate openapi.json")
+
+func TestSpecMatchesTheContract(t *testing.T) {
+	b, err := generateSpec()
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile("openapi.json", b, 0644))
+		return
+	}
+
+	assert.Equal(t, string(b), string(spec), "openapi.json is out of date, run make openapi")
+}
+
+func TestSpecDescribesEveryRoute(t *testing.T) {
+	cfg := config.NewConfig("../../../local.env")
+
+	r := getChiRouter(cfg, zap.NewNop(), &newrelic.Application{}, &reporters.MockPrometheus{}, &service.MockStoriesService{}, nil, nil)
+
+	var routes []string
+	err := chi.Walk(r, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+
+	require.NoError(t, err)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(spec, &doc))
+
+	var described []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			described = append(described, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(described)
+
+	assert.Equal(t, routes, described)
+}
+
+func TestSpecHandler(t *testing.T) {
+	cfg := config.NewConfig("../../../local.env")
+
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportAttempt", openAPIAPI)
+	pr.On("Observe", openAPIAPI, mock.AnythingOfType("float64"))
+	pr.On("ReportSuccess", openAPIAPI)
+
+	r := getChiRouter(cfg, zap.NewNop(), &newrelic.Application{}, pr, &service.MockStoriesService{}, nil, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, string(spec), w.Body.String())
+}
@@ -0,0 +1,55 @@
+package liberr
+
+// Code IS THE STABLE, MACHINE READABLE IDENTITY OF AN ERROR THAT CLIENTS BRANCH ON,
+// A CODE IS NEVER RENAMED OR REUSED ONCE RELEASED
+type Code string
+
+const (
+	CodeInternal            Code = "INTERNAL"
+	CodeInvalidRequest      Code = "INVALID_REQUEST"
+	CodeInvalidParameter    Code = "INVALID_PARAMETER"
+	CodeMissingParameter    Code = "MISSING_PARAMETER"
+	CodeInvalidStoryID      Code = "INVALID_STORY_ID"
+	CodeTitleRequired       Code = "TITLE_REQUIRED"
+	CodeTitleTooLong        Code = "TITLE_TOO_LONG"
+	CodeBodyRequired        Code = "BODY_REQUIRED"
+	CodeBodyTooLong         Code = "BODY_TOO_LONG"
+	CodeResourceNotFound    Code = "RESOURCE_NOT_FOUND"
+	CodeStoryNotFound       Code = "STORY_NOT_FOUND"
+	CodeConflict            Code = "CONFLICT"
+	CodeStoryAlreadyExists  Code = "STORY_ALREADY_EXISTS"
+	CodeVersionConflict     Code = "VERSION_CONFLICT"
+	CodeConstraintViolation Code = "CONSTRAINT_VIOLATION"
+	CodeUnavailable         Code = "UNAVAILABLE"
+	CodeTimeout             Code = "TIMEOUT"
+	CodeCanceled            Code = "CANCELED"
+
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodePreconditionFailed   Code = "PRECONDITION_FAILED"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeUnauthenticated      Code = "UNAUTHENTICATED"
+)
+
+// AN ERROR WITHOUT AN EXPLICIT CODE FALLS BACK TO THE CODE OF ITS KIND
+var kindCodes = map[Kind]Code{
+	InternalError:       CodeInternal,
+	ResourceNotFound:    CodeResourceNotFound,
+	ValidationError:     CodeInvalidRequest,
+	Unavailable:         CodeUnavailable,
+	Conflict:            CodeConflict,
+	ConstraintViolation: CodeConstraintViolation,
+
+	UnsupportedMediaType: CodeUnsupportedMediaType,
+	PreconditionFailed:   CodePreconditionFailed,
+	RateLimited:          CodeRateLimited,
+	Unauthenticated:      CodeUnauthenticated,
+}
+
+func codeOfKind(kind Kind) Code {
+	c, ok := kindCodes[kind]
+	if !ok {
+		return CodeInternal
+	}
+
+	return c
+}
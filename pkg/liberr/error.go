@@ -10,7 +10,10 @@ type Error struct {
 	kind      Kind
 	operation Operation
 	severity  Severity
+	code      Code
 	cause     error
+
+	violations []FieldViolation
 }
 
 func (e *Error) Kind() Kind {
@@ -56,6 +59,42 @@ func (e *Error) Severity() Severity {
 	return t.Severity()
 }
 
+// Code RETURNS THE OUTERMOST EXPLICIT CODE IN THE CHAIN, OR THE CODE OF THE KIND WHEN THERE IS NONE
+func (e *Error) Code() Code {
+	if c := e.explicitCode(); len(c) != 0 {
+		return c
+	}
+
+	return codeOfKind(e.Kind())
+}
+
+func (e *Error) explicitCode() Code {
+	if len(e.code) != 0 {
+		return e.code
+	}
+
+	t, ok := e.cause.(*Error)
+	if !ok {
+		return ""
+	}
+
+	return t.explicitCode()
+}
+
+// FieldViolations RETURNS THE VIOLATIONS OF THE OUTERMOST ERROR THAT HAS ANY
+func (e *Error) FieldViolations() []FieldViolation {
+	if len(e.violations) != 0 {
+		return e.violations
+	}
+
+	t, ok := e.cause.(*Error)
+	if !ok {
+		return nil
+	}
+
+	return t.FieldViolations()
+}
+
 func (e *Error) Unwrap() error {
 	return e.cause
 }
@@ -93,6 +132,12 @@ func WithArgs(args ...interface{}) *Error {
 			e.kind = t
 		case Severity:
 			e.severity = t
+		case Code:
+			e.code = t
+		case FieldViolation:
+			e.violations = append(e.violations, t)
+		case []FieldViolation:
+			e.violations = append(e.violations, t...)
 		case error:
 			e.cause = t
 		default:
@@ -143,6 +188,10 @@ func (e *Error) Stack() map[string]interface{} {
 		res["severity"] = string(e.severity)
 	}
 
+	if len(e.code) != 0 {
+		res["code"] = string(e.code)
+	}
+
 	if e.cause != nil {
 		t, ok := e.cause.(*Error)
 		if ok {
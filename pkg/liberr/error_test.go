@@ -1,6 +1,9 @@
 package liberr_test
 
 import (
+	"errors"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -8,3 +11,98 @@ import (
 func TestCreateError(t *testing.T) {
 
 }
+
+func TestErrorFieldViolations(t *testing.T) {
+	title := liberr.NewFieldViolation("title", liberr.CodeTitleRequired, "title cannot be empty")
+	body := liberr.NewFieldViolation("body", liberr.CodeBodyTooLong, "body max length exceeded")
+
+	testCases := map[string]struct {
+		err                error
+		expectedViolations []liberr.FieldViolation
+	}{
+		"test error without violations": {
+			err: liberr.WithArgs(liberr.ValidationError, errors.New("invalid")),
+		},
+		"test error with violations": {
+			err:                liberr.WithArgs(liberr.ValidationError, title, []liberr.FieldViolation{body}, errors.New("invalid")),
+			expectedViolations: []liberr.FieldViolation{title, body},
+		},
+		"test wrapped error returns the violations of its cause": {
+			err:                liberr.WithArgs(liberr.Operation("Handler.AddStory"), liberr.WithArgs(liberr.ValidationError, title, errors.New("invalid"))),
+			expectedViolations: []liberr.FieldViolation{title},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedViolations, testCase.err.(*liberr.Error).FieldViolations())
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		expectedCode liberr.Code
+	}{
+		"test error without kind or code is internal": {
+			err:          liberr.WithArgs(errors.New("failed")),
+			expectedCode: liberr.CodeInternal,
+		},
+		"test error without code falls back to the code of its kind": {
+			err:          liberr.WithArgs(liberr.ResourceNotFound, errors.New("not found")),
+			expectedCode: liberr.CodeResourceNotFound,
+		},
+		"test error with code returns its code": {
+			err:          liberr.WithArgs(liberr.ResourceNotFound, liberr.CodeStoryNotFound, errors.New("not found")),
+			expectedCode: liberr.CodeStoryNotFound,
+		},
+		"test wrapped error returns the code of its cause": {
+			err:          liberr.WithArgs(liberr.Operation("Service.GetStory"), liberr.WithArgs(liberr.ResourceNotFound, liberr.CodeStoryNotFound, errors.New("not found"))),
+			expectedCode: liberr.CodeStoryNotFound,
+		},
+		"test outermost code wins over the code of the cause": {
+			err:          liberr.WithArgs(liberr.CodeVersionConflict, liberr.WithArgs(liberr.Conflict, liberr.CodeStoryAlreadyExists, errors.New("conflict"))),
+			expectedCode: liberr.CodeVersionConflict,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedCode, testCase.err.(*liberr.Error).Code())
+		})
+	}
+}
+
+// CODES ARE PART OF THE PUBLIC CONTRACT, THIS TEST FAILS WHEN ONE IS RENAMED
+func TestCodeValues(t *testing.T) {
+	codes := map[liberr.Code]string{
+		liberr.CodeInternal:            "INTERNAL",
+		liberr.CodeInvalidRequest:      "INVALID_REQUEST",
+		liberr.CodeInvalidParameter:    "INVALID_PARAMETER",
+		liberr.CodeMissingParameter:    "MISSING_PARAMETER",
+		liberr.CodeInvalidStoryID:      "INVALID_STORY_ID",
+		liberr.CodeTitleRequired:       "TITLE_REQUIRED",
+		liberr.CodeTitleTooLong:        "TITLE_TOO_LONG",
+		liberr.CodeBodyRequired:        "BODY_REQUIRED",
+		liberr.CodeBodyTooLong:         "BODY_TOO_LONG",
+		liberr.CodeResourceNotFound:    "RESOURCE_NOT_FOUND",
+		liberr.CodeStoryNotFound:       "STORY_NOT_FOUND",
+		liberr.CodeConflict:            "CONFLICT",
+		liberr.CodeStoryAlreadyExists:  "STORY_ALREADY_EXISTS",
+		liberr.CodeVersionConflict:     "VERSION_CONFLICT",
+		liberr.CodeConstraintViolation: "CONSTRAINT_VIOLATION",
+		liberr.CodeUnavailable:         "UNAVAILABLE",
+		liberr.CodeTimeout:             "TIMEOUT",
+		liberr.CodeCanceled:            "CANCELED",
+
+		liberr.CodeUnsupportedMediaType: "UNSUPPORTED_MEDIA_TYPE",
+		liberr.CodePreconditionFailed:   "PRECONDITION_FAILED",
+		liberr.CodeRateLimited:          "RATE_LIMITED",
+		liberr.CodeUnauthenticated:      "UNAUTHENTICATED",
+	}
+
+	for code, expected := range codes {
+		assert.Equal(t, expected, string(code))
+	}
+}
@@ -0,0 +1,16 @@
+package liberr
+
+// FieldViolation NAMES THE INPUT FIELD THAT FAILED VALIDATION SO A CLIENT CAN POINT AT IT
+type FieldViolation struct {
+	Field   string
+	Code    Code
+	Message string
+}
+
+func NewFieldViolation(field string, code Code, message string) FieldViolation {
+	return FieldViolation{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}
+}
@@ -7,4 +7,13 @@ const (
 	InternalError    Kind = "internalError"
 	ResourceNotFound Kind = "resourceNotFound"
 	ValidationError  Kind = "validationError"
+	Unavailable      Kind = "unavailable"
+
+	Conflict            Kind = "conflict"
+	ConstraintViolation Kind = "constraintViolation"
+
+	UnsupportedMediaType Kind = "unsupportedMediaType"
+	PreconditionFailed   Kind = "preconditionFailed"
+	RateLimited          Kind = "rateLimited"
+	Unauthenticated      Kind = "unauthenticated"
 )
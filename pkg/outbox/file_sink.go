@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"os"
+	"sync"
+)
+
+// fileSink APPENDS ONE JSON EVENT PER LINE, EVERY WRITE IS SYNCED BEFORE THE EVENT IS MARKED PUBLISHED
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (fs *fileSink) Publish(_ context.Context, event model.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("FileSink.Publish.json.Marshal"), liberr.SeverityError, err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, err := fs.file.Write(append(data, '\n')); err != nil {
+		return liberr.WithArgs(liberr.Operation("FileSink.Publish.file.Write"), liberr.SeverityError, err)
+	}
+
+	if err := fs.file.Sync(); err != nil {
+		return liberr.WithArgs(liberr.Operation("FileSink.Publish.file.Sync"), liberr.SeverityError, err)
+	}
+
+	return nil
+}
+
+func NewFileSink(path string) (Sink, error) {
+	if path == "" {
+		return nil, liberr.WithArgs(liberr.Operation("NewFileSink"), liberr.ValidationError, errors.New("file path is empty"))
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("NewFileSink.os.OpenFile"), liberr.SeverityError, err)
+	}
+
+	return &fileSink{
+		file: file,
+	}, nil
+}
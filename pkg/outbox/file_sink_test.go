@@ -0,0 +1,41 @@
+package outbox_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/nsnikhil/stories/pkg/outbox"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSinkAppendsOneEventPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := outbox.NewFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Publish(context.Background(), model.NewStoryDeletedEvent("one")))
+	require.NoError(t, sink.Publish(context.Background(), model.NewStoryDeletedEvent("two")))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	var ids []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+		ids = append(ids, event.GetStoryID())
+	}
+
+	assert.Equal(t, []string{"one", "two"}, ids)
+}
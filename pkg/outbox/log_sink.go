@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"go.uber.org/zap"
+)
+
+type logSink struct {
+	lgr *zap.Logger
+}
+
+func (ls *logSink) Publish(_ context.Context, event model.Event) error {
+	ls.lgr.Info("story event",
+		zap.Int64("id", event.GetID()),
+		zap.String("storyId", event.GetStoryID()),
+		zap.String("type", string(event.GetType())),
+		zap.ByteString("payload", event.Payload),
+	)
+
+	return nil
+}
+
+func NewLogSink(lgr *zap.Logger) Sink {
+	return &logSink{
+		lgr: lgr,
+	}
+}
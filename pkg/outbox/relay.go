@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Relay PUBLISHES THE EVENTS WRITTEN TO THE OUTBOX. AN EVENT IS MARKED PUBLISHED ONLY ONCE EVERY SINK
+// ACCEPTED IT, SO A FAILURE OR A CRASH IN BETWEEN SENDS IT AGAIN (AT LEAST ONCE). WHEN AN EVENT FAILS THE
+// LATER EVENTS OF THE SAME STORY ARE HELD BACK UNTIL IT GOES THROUGH, THE OTHER STORIES CARRY ON: THE STORY
+// STAYS LEASED AND IS LEFT OUT OF THE CLAIMS UNTIL THE LEASE RUNS OUT.
+type Relay interface {
+	// Run RETURNS ONCE ctx IS DONE
+	Run(ctx context.Context)
+}
+
+type storeRelay struct {
+	store store.StoriesStore
+	sinks []Sink
+	lgr   *zap.Logger
+
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+}
+
+func (sr *storeRelay) Run(ctx context.Context) {
+	for {
+		n, err := sr.relay(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			sr.lgr.Error(err.Error())
+		}
+
+		// A FULL BATCH MEANS MORE EVENTS ARE LIKELY WAITING, THE STORIES THAT FAILED ARE LEFT OUT OF THE NEXT CLAIM
+		if n == sr.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sr.pollInterval):
+		}
+	}
+}
+
+// relay RETURNS THE NUMBER OF EVENTS IT CLAIMED. NOTHING IS LOCKED WHILE THEY ARE PUBLISHED, THE LEASE KEEPS THE
+// OTHER RELAYS AWAY FROM THEIR STORIES SO THE EVENTS OF A STORY ARE NEVER SENT OUT OF ORDER
+func (sr *storeRelay) relay(ctx context.Context) (int, error) {
+	events, err := sr.store.ClaimEvents(ctx, sr.batchSize, sr.lease)
+	if err != nil {
+		return 0, liberr.WithArgs(liberr.Operation("Relay.relay"), err)
+	}
+
+	var publishErr error
+
+	held := make(map[string]bool)
+	var published []int64
+
+	for _, event := range events {
+		if held[event.GetStoryID()] {
+			continue
+		}
+
+		if err := sr.publish(ctx, event); err != nil {
+			held[event.GetStoryID()] = true
+			if publishErr == nil {
+				publishErr = err
+			}
+
+			continue
+		}
+
+		published = append(published, event.GetID())
+	}
+
+	// AN EVENT THAT WENT OUT BUT IS NOT MARKED IS SENT AGAIN ONCE ITS LEASE RUNS OUT
+	if err := sr.store.MarkEventsPublished(ctx, published...); err != nil {
+		return len(events), liberr.WithArgs(liberr.Operation("Relay.relay"), err)
+	}
+
+	if publishErr != nil {
+		return len(events), liberr.WithArgs(liberr.Operation("Relay.publish"), publishErr)
+	}
+
+	return len(events), nil
+}
+
+func (sr *storeRelay) publish(ctx context.Context, event model.Event) error {
+	for _, sink := range sr.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewRelay(str store.StoriesStore, sinks []Sink, cfg config.OutboxConfig, lgr *zap.Logger) Relay {
+	return newRelay(str, sinks, cfg.BatchSize(), cfg.PollInterval(), cfg.Lease(), lgr)
+}
+
+func newRelay(str store.StoriesStore, sinks []Sink, batchSize int, pollInterval, lease time.Duration, lgr *zap.Logger) *storeRelay {
+	return &storeRelay{
+		store:        str,
+		sinks:        sinks,
+		lgr:          lgr,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		lease:        lease,
+	}
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	events []model.Event
+
+	// fail HOLDS THE STORIES WHOSE EVENTS ARE REJECTED
+	fail map[string]bool
+}
+
+func (rs *recordingSink) Publish(_ context.Context, event model.Event) error {
+	if rs.fail[event.GetStoryID()] {
+		return errors.New("sink is down")
+	}
+
+	rs.events = append(rs.events, event)
+	return nil
+}
+
+func TestRelayPublishesPendingEventsInOrder(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	one := addStory(t, str, "one")
+	two := addStory(t, str, "two")
+	require.NoError(t, str.IncrementCounters(context.Background(), model.CounterDelta{StoryID: one, UpVotes: 1}))
+
+	sink := &recordingSink{}
+	rl := newRelay(str, []Sink{sink}, 2, time.Millisecond, 0, zap.NewNop())
+
+	n, err := rl.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = rl.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Equal(t, 3, len(sink.events))
+	assert.Equal(t, []string{one, two, one}, storyIDs(sink.events))
+	assert.Equal(t, model.EventStoryVoted, sink.events[2].GetType())
+
+	assert.Empty(t, unpublished(t, str))
+}
+
+func TestRelayHoldsBackTheEventsOfAStoryThatFailed(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	one := addStory(t, str, "one")
+	two := addStory(t, str, "two")
+	require.NoError(t, str.IncrementCounters(context.Background(),
+		model.CounterDelta{StoryID: one, UpVotes: 1},
+		model.CounterDelta{StoryID: two, DownVotes: 1},
+	))
+
+	sink := &recordingSink{fail: map[string]bool{one: true}}
+	rl := newRelay(str, []Sink{sink}, 10, time.Millisecond, 0, zap.NewNop())
+
+	_, err := rl.relay(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{two, two}, storyIDs(sink.events))
+
+	assert.Equal(t, []string{one, one}, storyIDs(unpublished(t, str)))
+
+	sink.fail = nil
+
+	_, err = rl.relay(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{two, two, one, one}, storyIDs(sink.events))
+	assert.Equal(t, model.EventStoryCreated, sink.events[2].GetType())
+	assert.Equal(t, model.EventStoryVoted, sink.events[3].GetType())
+}
+
+func TestRelayCarriesOnWithTheOtherStoriesWhileAFailedStoryIsLeased(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	one := addStory(t, str, "one")
+	require.NoError(t, str.IncrementCounters(context.Background(), model.CounterDelta{StoryID: one, UpVotes: 1}))
+	two := addStory(t, str, "two")
+
+	sink := &recordingSink{fail: map[string]bool{one: true}}
+	rl := newRelay(str, []Sink{sink}, 2, time.Millisecond, time.Minute, zap.NewNop())
+
+	n, err := rl.relay(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 2, n)
+
+	sink.fail = nil
+
+	n, err = rl.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{two}, storyIDs(sink.events))
+
+	n, err = rl.relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestRelayKeepsTheEventWhenOneOfTheSinksFails(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	one := addStory(t, str, "one")
+
+	ok, failing := &recordingSink{}, &recordingSink{fail: map[string]bool{one: true}}
+	rl := newRelay(str, []Sink{ok, failing}, 10, time.Millisecond, 0, zap.NewNop())
+
+	_, err := rl.relay(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, 1, len(unpublished(t, str)))
+}
+
+func TestRelayRunStopsWhenTheContextIsDone(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+	addStory(t, str, "one")
+
+	sink := &recordingSink{}
+	rl := newRelay(str, []Sink{sink}, 10, time.Millisecond, 0, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		rl.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		pending, err := str.ClaimEvents(context.Background(), 10, 0)
+		return err == nil && len(pending) == 0
+	}, time.Second, time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
+
+func addStory(t *testing.T, str store.StoriesStore, title string) string {
+	st, err := model.NewStoryBuilder().SetTitle(100, title).SetBody(100, "body").Build()
+	require.NoError(t, err)
+
+	id, err := str.AddStory(context.Background(), st)
+	require.NoError(t, err)
+
+	return id
+}
+
+// unpublished CLAIMS WITH A ZERO LEASE, WHICH RUNS OUT RIGHT AWAY, SO IT HOLDS NONE OF THE EVENTS BACK FROM THE RELAY
+func unpublished(t *testing.T, str store.StoriesStore) []model.Event {
+	events, err := str.ClaimEvents(context.Background(), 10, 0)
+	require.NoError(t, err)
+	return events
+}
+
+func storyIDs(events []model.Event) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.GetStoryID())
+	}
+
+	return ids
+}
@@ -0,0 +1,18 @@
+package outbox
+
+import (
+	"context"
+	"github.com/nsnikhil/stories/pkg/story/model"
+)
+
+const (
+	LogSinkName     = "log"
+	WebhookSinkName = "webhook"
+	FileSinkName    = "file"
+)
+
+// Sink DELIVERS AN EVENT DOWNSTREAM, AN EVENT CAN BE DELIVERED MORE THAN ONCE SO CONSUMERS HAVE TO
+// DEDUPLICATE ON ITS ID
+type Sink interface {
+	Publish(ctx context.Context, event model.Event) error
+}
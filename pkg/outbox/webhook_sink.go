@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const eventIDHeader = "X-Event-ID"
+
+// webhookSink POSTS THE EVENT AS JSON, ANY RESPONSE OUTSIDE 2XX IS A FAILURE AND THE EVENT IS SENT AGAIN
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (ws *webhookSink) Publish(ctx context.Context, event model.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("WebhookSink.Publish.json.Marshal"), liberr.SeverityError, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.url, bytes.NewReader(body))
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("WebhookSink.Publish.http.NewRequest"), liberr.SeverityError, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(eventIDHeader, strconv.FormatInt(event.GetID(), 10))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("WebhookSink.Publish.client.Do"), liberr.Unavailable, liberr.SeverityError, err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return liberr.WithArgs(liberr.Operation("WebhookSink.Publish"), liberr.Unavailable, liberr.SeverityError, fmt.Errorf("webhook responded with %d", resp.StatusCode))
+	}
+
+	return nil
+}
+
+func NewWebhookSink(url string, timeout time.Duration) (Sink, error) {
+	if url == "" {
+		return nil, liberr.WithArgs(liberr.Operation("NewWebhookSink"), liberr.ValidationError, errors.New("webhook url is empty"))
+	}
+
+	return &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
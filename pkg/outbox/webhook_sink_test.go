@@ -0,0 +1,66 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nsnikhil/stories/pkg/outbox"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSinkPublish(t *testing.T) {
+	testCases := map[string]struct {
+		status        int
+		expectedError bool
+	}{
+		"test publish succeeds on 2xx": {
+			status: http.StatusNoContent,
+		},
+		"test publish fails on 5xx": {
+			status:        http.StatusServiceUnavailable,
+			expectedError: true,
+		},
+		"test publish fails on 4xx": {
+			status:        http.StatusBadRequest,
+			expectedError: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var received model.Event
+			var eventID string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				eventID = r.Header.Get("X-Event-ID")
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(testCase.status)
+			}))
+
+			defer server.Close()
+
+			sink, err := outbox.NewWebhookSink(server.URL, time.Second)
+			require.NoError(t, err)
+
+			event := model.NewStoryDeletedEvent("one")
+			event.ID = 42
+
+			err = sink.Publish(context.Background(), event)
+
+			assert.Equal(t, testCase.expectedError, err != nil)
+			assert.Equal(t, "42", eventID)
+			assert.Equal(t, model.EventStoryDeleted, received.GetType())
+			assert.JSONEq(t, `{"id":"one"}`, string(received.Payload))
+		})
+	}
+}
+
+func TestNewWebhookSinkRequiresAURL(t *testing.T) {
+	_, err := outbox.NewWebhookSink("", time.Second)
+	assert.Error(t, err)
+}
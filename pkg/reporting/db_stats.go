@@ -0,0 +1,70 @@
+package reporters
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+// dbStatsCollector READS THE POOL STATS OF EVERY REGISTERED DATABASE WHEN PROMETHEUS SCRAPES,
+// THE DATABASES ARE TOLD APART BY THE db LABEL
+type dbStatsCollector struct {
+	mu      sync.RWMutex
+	sources map[string]func() sql.DBStats
+
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func (dsc *dbStatsCollector) add(db string, stats func() sql.DBStats) {
+	dsc.mu.Lock()
+	defer dsc.mu.Unlock()
+
+	dsc.sources[db] = stats
+}
+
+func (dsc *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dsc.maxOpen
+	ch <- dsc.open
+	ch <- dsc.inUse
+	ch <- dsc.idle
+	ch <- dsc.waitCount
+	ch <- dsc.waitDuration
+}
+
+func (dsc *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	dsc.mu.RLock()
+	defer dsc.mu.RUnlock()
+
+	for db, source := range dsc.sources {
+		stats := source()
+
+		ch <- prometheus.MustNewConstMetric(dsc.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), db)
+		ch <- prometheus.MustNewConstMetric(dsc.open, prometheus.GaugeValue, float64(stats.OpenConnections), db)
+		ch <- prometheus.MustNewConstMetric(dsc.inUse, prometheus.GaugeValue, float64(stats.InUse), db)
+		ch <- prometheus.MustNewConstMetric(dsc.idle, prometheus.GaugeValue, float64(stats.Idle), db)
+		ch <- prometheus.MustNewConstMetric(dsc.waitCount, prometheus.CounterValue, float64(stats.WaitCount), db)
+		ch <- prometheus.MustNewConstMetric(dsc.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), db)
+	}
+}
+
+func newDBStatsCollector() *dbStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{"db"}, nil)
+	}
+
+	return &dbStatsCollector{
+		sources: make(map[string]func() sql.DBStats),
+
+		maxOpen:      desc("stories_db_max_open_connections", "maximum number of open connections to the database"),
+		open:         desc("stories_db_open_connections", "number of open connections, in use and idle"),
+		inUse:        desc("stories_db_in_use_connections", "number of connections currently in use"),
+		idle:         desc("stories_db_idle_connections", "number of idle connections"),
+		waitCount:    desc("stories_db_wait_count_total", "total number of times a connection was waited for"),
+		waitDuration: desc("stories_db_wait_duration_seconds_total", "total time spent waiting for a connection"),
+	}
+}
@@ -1,6 +1,10 @@
 package reporters
 
-import "github.com/stretchr/testify/mock"
+import (
+	"database/sql"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
 
 type MockPrometheus struct {
 	mock.Mock
@@ -21,3 +25,43 @@ func (mp *MockPrometheus) ReportFailure(bucket string) {
 func (mp *MockPrometheus) Observe(bucket string, value float64) {
 	mp.Called(bucket, value)
 }
+
+func (mp *MockPrometheus) ReportCacheHit(cache string) {
+	mp.Called(cache)
+}
+
+func (mp *MockPrometheus) ReportCacheMiss(cache string) {
+	mp.Called(cache)
+}
+
+func (mp *MockPrometheus) ReportCircuitBreakerState(breaker string, state int) {
+	mp.Called(breaker, state)
+}
+
+func (mp *MockPrometheus) ObserveStoreOperation(operation string, duration time.Duration) {
+	mp.Called(operation, duration)
+}
+
+func (mp *MockPrometheus) ReportStoreError(operation, kind string) {
+	mp.Called(operation, kind)
+}
+
+func (mp *MockPrometheus) ObserveCompression(transport, encoding string, uncompressed, compressed int) {
+	mp.Called(transport, encoding, uncompressed, compressed)
+}
+
+func (mp *MockPrometheus) ReportRateLimitAllowed(api string) {
+	mp.Called(api)
+}
+
+func (mp *MockPrometheus) ReportRateLimitRejected(api string) {
+	mp.Called(api)
+}
+
+func (mp *MockPrometheus) ReportDroppedCounters(stories int) {
+	mp.Called(stories)
+}
+
+func (mp *MockPrometheus) RegisterDBStats(db string, stats func() sql.DBStats) {
+	mp.Called(db, stats)
+}
@@ -1,6 +1,10 @@
 package reporters
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
 
 const (
 	attempt = "attempt"
@@ -12,6 +16,33 @@ const (
 
 	apiResponseTimeName = "stories_api_response_time"
 	apiResponseTimeHelp = "total time taken by the api"
+
+	hit  = "hit"
+	miss = "miss"
+
+	cacheCounterName = "stories_cache_lookup"
+	cacheCounterHelp = "total number of cache lookups"
+
+	breakerGaugeName = "stories_circuit_breaker_state"
+	breakerGaugeHelp = "state of the circuit breaker, 0 closed, 1 half open, 2 open"
+
+	storeHistogramName = "stories_store_operation_time"
+	storeHistogramHelp = "total time taken by the store operations in seconds"
+
+	storeErrorCounterName = "stories_store_operation_error"
+	storeErrorCounterHelp = "total number of failed store operations"
+
+	compressionHistogramName = "stories_compression_ratio"
+	compressionHistogramHelp = "compressed size of a response divided by its uncompressed size"
+
+	allowed = "allowed"
+	limited = "limited"
+
+	rateLimitCounterName = "stories_rate_limit"
+	rateLimitCounterHelp = "total number of calls checked against a rate limit"
+
+	droppedCountersName = "stories_counter_dropped_total"
+	droppedCountersHelp = "total number of stories whose pending increments were dropped after failing every flush"
 )
 
 type Prometheus interface {
@@ -19,12 +50,42 @@ type Prometheus interface {
 	ReportSuccess(bucket string)
 	ReportFailure(bucket string)
 	Observe(bucket string, value float64)
+
+	ReportCacheHit(cache string)
+	ReportCacheMiss(cache string)
+
+	ReportCircuitBreakerState(breaker string, state int)
+
+	ObserveStoreOperation(operation string, duration time.Duration)
+	ReportStoreError(operation, kind string)
+
+	// ObserveCompression RECORDS HOW MUCH A RESPONSE SHRANK, transport IS http OR grpc
+	ObserveCompression(transport, encoding string, uncompressed, compressed int)
+
+	ReportRateLimitAllowed(api string)
+	ReportRateLimitRejected(api string)
+
+	// ReportDroppedCounters COUNTS THE STORIES WHOSE PENDING INCREMENTS WERE GIVEN UP ON
+	ReportDroppedCounters(stories int)
+
+	// RegisterDBStats EXPORTS THE POOL STATS OF A DATABASE, stats IS CALLED ON EVERY SCRAPE
+	RegisterDBStats(db string, stats func() sql.DBStats)
 }
 
 //TODO: REMOVE (REMOVE DEFAULT)
 type defaultPrometheus struct {
 	apiCounter        *prometheus.CounterVec
 	responseHistogram *prometheus.HistogramVec
+	cacheCounter      *prometheus.CounterVec
+	breakerGauge      *prometheus.GaugeVec
+
+	storeHistogram    *prometheus.HistogramVec
+	storeErrorCounter *prometheus.CounterVec
+	dbStats           *dbStatsCollector
+
+	compressionHistogram *prometheus.HistogramVec
+	rateLimitCounter     *prometheus.CounterVec
+	droppedCounters      prometheus.Counter
 }
 
 func (dp *defaultPrometheus) ReportAttempt(bucket string) {
@@ -61,14 +122,125 @@ func newHistogram() *prometheus.HistogramVec {
 	}, []string{"api"})
 }
 
+func (dp *defaultPrometheus) ReportCacheHit(cache string) {
+	dp.cacheCounter.WithLabelValues(hit, cache).Inc()
+}
+
+func (dp *defaultPrometheus) ReportCacheMiss(cache string) {
+	dp.cacheCounter.WithLabelValues(miss, cache).Inc()
+}
+
+func newCacheCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: cacheCounterName,
+		Help: cacheCounterHelp,
+	}, []string{"result", "cache"})
+}
+
+func (dp *defaultPrometheus) ReportCircuitBreakerState(breaker string, state int) {
+	dp.breakerGauge.WithLabelValues(breaker).Set(float64(state))
+}
+
+func newBreakerGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: breakerGaugeName,
+		Help: breakerGaugeHelp,
+	}, []string{"breaker"})
+}
+
+func (dp *defaultPrometheus) ObserveStoreOperation(operation string, duration time.Duration) {
+	dp.storeHistogram.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+func newStoreHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: storeHistogramName,
+		Help: storeHistogramHelp,
+	}, []string{"operation"})
+}
+
+func (dp *defaultPrometheus) ReportStoreError(operation, kind string) {
+	dp.storeErrorCounter.WithLabelValues(operation, kind).Inc()
+}
+
+func newStoreErrorCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: storeErrorCounterName,
+		Help: storeErrorCounterHelp,
+	}, []string{"operation", "kind"})
+}
+
+func (dp *defaultPrometheus) ObserveCompression(transport, encoding string, uncompressed, compressed int) {
+	if uncompressed == 0 {
+		return
+	}
+
+	dp.compressionHistogram.WithLabelValues(transport, encoding).Observe(float64(compressed) / float64(uncompressed))
+}
+
+func newCompressionHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    compressionHistogramName,
+		Help:    compressionHistogramHelp,
+		Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+	}, []string{"transport", "encoding"})
+}
+
+func (dp *defaultPrometheus) ReportRateLimitAllowed(api string) {
+	dp.rateLimitCounter.WithLabelValues(allowed, api).Inc()
+}
+
+func (dp *defaultPrometheus) ReportRateLimitRejected(api string) {
+	dp.rateLimitCounter.WithLabelValues(limited, api).Inc()
+}
+
+func newRateLimitCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: rateLimitCounterName,
+		Help: rateLimitCounterHelp,
+	}, []string{"result", "api"})
+}
+
+func (dp *defaultPrometheus) ReportDroppedCounters(stories int) {
+	dp.droppedCounters.Add(float64(stories))
+}
+
+func newDroppedCounters() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: droppedCountersName,
+		Help: droppedCountersHelp,
+	})
+}
+
+func (dp *defaultPrometheus) RegisterDBStats(db string, stats func() sql.DBStats) {
+	dp.dbStats.add(db, stats)
+}
+
 func NewPrometheus() Prometheus {
 	ct := newCounter()
 	ht := newHistogram()
+	cc := newCacheCounter()
+	bg := newBreakerGauge()
+	sh := newStoreHistogram()
+	sc := newStoreErrorCounter()
+	ds := newDBStatsCollector()
+	ch := newCompressionHistogram()
+	rc := newRateLimitCounter()
+	dc := newDroppedCounters()
 
-	prometheus.MustRegister(ct, ht)
+	prometheus.MustRegister(ct, ht, cc, bg, sh, sc, ds, ch, rc, dc)
 
 	return &defaultPrometheus{
 		apiCounter:        ct,
 		responseHistogram: ht,
+		cacheCounter:      cc,
+		breakerGauge:      bg,
+		storeHistogram:    sh,
+		storeErrorCounter: sc,
+		dbStats:           ds,
+
+		compressionHistogram: ch,
+		rateLimitCounter:     rc,
+		droppedCounters:      dc,
 	}
 }
@@ -0,0 +1,154 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State IS EXPORTED AS THE VALUE OF THE BREAKER GAUGE
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+type CircuitBreaker interface {
+	// Execute RUNS fn UNLESS THE BREAKER IS OPEN, IN WHICH CASE IT RETURNS ErrCircuitOpen WITHOUT CALLING IT
+	Execute(fn func() error) error
+	State() State
+}
+
+// circuitBreaker OPENS AFTER threshold CONSECUTIVE FAILURES, ONCE openTimeout HAS PASSED A SINGLE PROBE
+// IS LET THROUGH AND ITS OUTCOME DECIDES WHETHER THE BREAKER CLOSES OR OPENS AGAIN
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	threshold     int
+	openTimeout   time.Duration
+	isFailure     func(err error) bool
+	onStateChange func(state State)
+	now           func() time.Time
+}
+
+func (cb *circuitBreaker) Execute(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	cb.record(err)
+
+	return err
+}
+
+func (cb *circuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.state
+}
+
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case StateOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openTimeout {
+			return ErrCircuitOpen
+		}
+
+		cb.setState(StateHalfOpen)
+		cb.probing = true
+	case StateHalfOpen:
+		if cb.probing {
+			return ErrCircuitOpen
+		}
+
+		cb.probing = true
+	}
+
+	return nil
+}
+
+func (cb *circuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	probe := cb.probing
+	cb.probing = false
+
+	switch {
+	// A CALLER GIVING UP SAYS NOTHING ABOUT THE HEALTH OF THE DEPENDENCY
+	case errors.Is(err, context.Canceled):
+		return
+	case err != nil && cb.isFailure(err):
+		cb.failures++
+		if probe || (cb.threshold > 0 && cb.failures >= cb.threshold) {
+			cb.trip()
+		}
+	default:
+		cb.failures = 0
+		if cb.state != StateClosed {
+			cb.setState(StateClosed)
+		}
+	}
+}
+
+func (cb *circuitBreaker) trip() {
+	cb.openedAt = cb.now()
+	cb.setState(StateOpen)
+}
+
+func (cb *circuitBreaker) setState(state State) {
+	if cb.state == state {
+		return
+	}
+
+	cb.state = state
+	cb.onStateChange(state)
+}
+
+// NewCircuitBreaker ONLY COUNTS ERRORS FOR WHICH isFailure RETURNS TRUE, A threshold OF ZERO DISABLES THE BREAKER
+func NewCircuitBreaker(threshold int, openTimeout time.Duration, isFailure func(err error) bool, onStateChange func(state State)) CircuitBreaker {
+	return newCircuitBreaker(threshold, openTimeout, isFailure, onStateChange, time.Now)
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration, isFailure func(err error) bool, onStateChange func(state State), now func() time.Time) *circuitBreaker {
+	if onStateChange == nil {
+		onStateChange = func(state State) {}
+	}
+
+	onStateChange(StateClosed)
+
+	return &circuitBreaker{
+		state:         StateClosed,
+		threshold:     threshold,
+		openTimeout:   openTimeout,
+		isFailure:     isFailure,
+		onStateChange: onStateChange,
+		now:           now,
+	}
+}
@@ -0,0 +1,127 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(threshold int, states *[]State) (*circuitBreaker, *clock) {
+	c := &clock{now: time.Now()}
+
+	isFailure := func(err error) bool { return err.Error() != "not a failure" }
+	onStateChange := func(state State) { *states = append(*states, state) }
+
+	return newCircuitBreaker(threshold, time.Minute, isFailure, onStateChange, c.Now), c
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	var states []State
+	cb, _ := newTestBreaker(3, &states)
+
+	calls := 0
+	fail := func() error { calls++; return errors.New("connection reset") }
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "connection reset", cb.Execute(fail).Error())
+	}
+
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, ErrCircuitOpen, cb.Execute(fail))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []State{StateClosed, StateOpen}, states)
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	var states []State
+	cb, _ := newTestBreaker(2, &states)
+
+	for i := 0; i < 5; i++ {
+		_ = cb.Execute(func() error { return errors.New("connection reset") })
+		_ = cb.Execute(func() error { return nil })
+	}
+
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestCircuitBreakerIgnoresNonFailures(t *testing.T) {
+	testCases := map[string]error{
+		"test error the classifier rejects": errors.New("not a failure"),
+		"test canceled context":             context.Canceled,
+		"test success":                      nil,
+	}
+
+	for name, err := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var states []State
+			cb, _ := newTestBreaker(2, &states)
+
+			for i := 0; i < 5; i++ {
+				_ = cb.Execute(func() error { return err })
+			}
+
+			assert.Equal(t, StateClosed, cb.State())
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	testCases := map[string]struct {
+		probe         error
+		expectedState State
+		states        []State
+	}{
+		"test successful probe closes the breaker": {
+			expectedState: StateClosed,
+			states:        []State{StateClosed, StateOpen, StateHalfOpen, StateClosed},
+		},
+		"test failed probe opens the breaker again": {
+			probe:         errors.New("connection reset"),
+			expectedState: StateOpen,
+			states:        []State{StateClosed, StateOpen, StateHalfOpen, StateOpen},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var states []State
+			cb, c := newTestBreaker(1, &states)
+
+			_ = cb.Execute(func() error { return errors.New("connection reset") })
+			assert.Equal(t, StateOpen, cb.State())
+
+			c.now = c.now.Add(time.Minute)
+
+			err := cb.Execute(func() error {
+				assert.Equal(t, StateHalfOpen, cb.State())
+				assert.Equal(t, ErrCircuitOpen, cb.Execute(func() error { return nil }))
+				return testCase.probe
+			})
+
+			assert.Equal(t, testCase.probe, err)
+			assert.Equal(t, testCase.expectedState, cb.State())
+			assert.Equal(t, testCase.states, states)
+		})
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var states []State
+	cb, _ := newTestBreaker(0, &states)
+
+	for i := 0; i < 10; i++ {
+		_ = cb.Execute(func() error { return errors.New("connection reset") })
+	}
+
+	assert.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,93 @@
+package resilience
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type RateLimiter interface {
+	// Allow TAKES A TOKEN FROM THE BUCKET OF key, WHEN IT IS EMPTY IT RETURNS FALSE AND HOW LONG UNTIL THE NEXT TOKEN
+	Allow(key string) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// tokenBucketLimiter KEEPS A BUCKET OF burst TOKENS PER KEY THAT REFILLS AT rate TOKENS PER SECOND, A FULL BUCKET
+// IS THE SAME AS NO BUCKET SO THE ONES THAT HAVE REFILLED ARE DROPPED TO KEEP THE MAP FROM GROWING WITH EVERY CLIENT
+type tokenBucketLimiter struct {
+	mu sync.Mutex
+
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	rate  float64
+	burst float64
+	now   func() time.Time
+}
+
+func (tl *tokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	now := tl.now()
+	tl.sweep(now)
+
+	b, ok := tl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: tl.burst, updatedAt: now}
+		tl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(tl.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*tl.rate)
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / tl.rate * float64(time.Second))
+}
+
+func (tl *tokenBucketLimiter) sweep(now time.Time) {
+	refill := time.Duration(tl.burst / tl.rate * float64(time.Second))
+	if now.Sub(tl.lastSweep) < refill {
+		return
+	}
+
+	tl.lastSweep = now
+
+	for key, b := range tl.buckets {
+		if now.Sub(b.updatedAt) >= refill {
+			delete(tl.buckets, key)
+		}
+	}
+}
+
+// NewRateLimiter LETS limit CALLS PER KEY THROUGH EVERY window, UP TO burst OF THEM AT ONCE
+func NewRateLimiter(limit int, window time.Duration, burst int) RateLimiter {
+	return newTokenBucketLimiter(limit, window, burst, time.Now)
+}
+
+func newTokenBucketLimiter(limit int, window time.Duration, burst int, now func() time.Time) *tokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucketLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: now(),
+		rate:      float64(limit) / window.Seconds(),
+		burst:     float64(burst),
+		now:       now,
+	}
+}
+
+// RetryAfter ROUNDS wait UP TO WHOLE SECONDS, THE UNIT OF THE Retry-After HEADER
+func RetryAfter(wait time.Duration) int {
+	return int(math.Ceil(wait.Seconds()))
+}
@@ -0,0 +1,70 @@
+package resilience
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(limit, burst int) (*tokenBucketLimiter, *clock) {
+	c := &clock{now: time.Now()}
+	return newTokenBucketLimiter(limit, time.Minute, burst, c.Now), c
+}
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	rl, _ := newTestRateLimiter(60, 3)
+
+	for i := 0; i < 3; i++ {
+		allowed, wait := rl.Allow("client")
+		assert.True(t, allowed)
+		assert.Zero(t, wait)
+	}
+
+	allowed, wait := rl.Allow("client")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, wait)
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	rl, c := newTestRateLimiter(60, 1)
+
+	allowed, _ := rl.Allow("client")
+	assert.True(t, allowed)
+
+	c.now = c.now.Add(time.Millisecond * 400)
+
+	allowed, wait := rl.Allow("client")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Millisecond*600, wait)
+
+	c.now = c.now.Add(time.Millisecond * 600)
+
+	allowed, _ = rl.Allow("client")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterKeysAreIndependent(t *testing.T) {
+	rl, _ := newTestRateLimiter(60, 1)
+
+	allowed, _ := rl.Allow("one")
+	assert.True(t, allowed)
+
+	allowed, _ = rl.Allow("one")
+	assert.False(t, allowed)
+
+	allowed, _ = rl.Allow("two")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterDropsRefilledBuckets(t *testing.T) {
+	rl, c := newTestRateLimiter(60, 2)
+
+	rl.Allow("one")
+	rl.Allow("two")
+	assert.Len(t, rl.buckets, 2)
+
+	c.now = c.now.Add(time.Second * 2)
+
+	rl.Allow("three")
+	assert.Len(t, rl.buckets, 1)
+}
@@ -0,0 +1,44 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// delay USES FULL JITTER, A RANDOM WAIT BETWEEN ZERO AND THE EXPONENTIAL CAP, SO CALLERS THAT FAILED
+// TOGETHER DO NOT COME BACK TOGETHER
+func (b Backoff) delay(attempt int) time.Duration {
+	if b.Initial <= 0 {
+		return 0
+	}
+
+	limit := b.Initial << uint(attempt)
+	if limit <= 0 || (b.Max > 0 && limit > b.Max) {
+		limit = b.Max
+	}
+
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+// Retry CALLS fn UP TO maxAttempts TIMES, IT STOPS EARLY ON SUCCESS, ON AN ERROR isRetryable REJECTS
+// OR WHEN ctx IS DONE. THE LAST ERROR FROM fn IS RETURNED AS IS
+func Retry(ctx context.Context, maxAttempts int, backoff Backoff, isRetryable func(err error) bool, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.delay(attempt - 1)):
+		}
+	}
+}
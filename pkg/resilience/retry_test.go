@@ -0,0 +1,72 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	transient := errors.New("connection reset")
+	permanent := errors.New("invalid uuid")
+
+	isRetryable := func(err error) bool { return err == transient }
+	backoff := resilience.Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+	testCases := map[string]struct {
+		errs          []error
+		expectedCalls int
+		expectedError error
+	}{
+		"test success on first attempt": {
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		"test success after transient failures": {
+			errs:          []error{transient, transient, nil},
+			expectedCalls: 3,
+		},
+		"test give up after max attempts": {
+			errs:          []error{transient, transient, transient, nil},
+			expectedCalls: 3,
+			expectedError: transient,
+		},
+		"test do not retry permanent failure": {
+			errs:          []error{permanent, nil},
+			expectedCalls: 1,
+			expectedError: permanent,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+
+			err := resilience.Retry(context.Background(), 3, backoff, isRetryable, func() error {
+				err := testCase.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedCalls, calls)
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := resilience.Retry(ctx, 5, resilience.Backoff{Initial: time.Second, Max: time.Second}, func(err error) bool { return true }, func() error {
+		calls++
+		return errors.New("connection reset")
+	})
+
+	assert.Equal(t, "connection reset", err.Error())
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,177 @@
+package seed
+
+import (
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	minTitleWords = 3
+	maxTitleWords = 14
+
+	// BODIES FOLLOW A LOG NORMAL DISTRIBUTION, MOST ARE A FEW PARAGRAPHS AND A FEW ARE VERY LONG
+	medianBodyLength = 1200
+	bodyLengthSigma  = 0.9
+	minBodyLength    = 80
+
+	// VIEWS AND VOTES FOLLOW A ZIPF DISTRIBUTION, MOST STORIES ARE BARELY SEEN AND A FEW TAKE MOST OF THE TRAFFIC
+	maxViews = 1000000
+	maxVotes = 100000
+
+	// THE SHARE OF STORIES EDITED AFTER THEY WERE CREATED
+	editedRatio = 0.2
+)
+
+var words = []string{
+	"the", "a", "of", "and", "to", "in", "is", "was", "it", "for", "on", "with", "as", "at", "by", "from",
+	"story", "night", "city", "river", "house", "garden", "letter", "train", "winter", "summer", "morning",
+	"stranger", "friend", "mother", "father", "child", "village", "forest", "ocean", "mountain", "road",
+	"light", "shadow", "voice", "memory", "secret", "promise", "journey", "silence", "storm", "window",
+	"old", "quiet", "broken", "golden", "last", "first", "long", "small", "strange", "forgotten", "distant",
+	"walked", "waited", "remembered", "found", "lost", "opened", "whispered", "returned", "watched", "left",
+	"never", "always", "again", "slowly", "suddenly", "together", "alone", "before", "after", "until",
+}
+
+// Generator IS DETERMINISTIC, TWO GENERATORS WITH THE SAME SEED AND WINDOW RETURN THE SAME STORIES IN THE SAME ORDER
+type Generator interface {
+	Next() *model.Story
+}
+
+type zipfGenerator struct {
+	rnd   *rand.Rand
+	views *rand.Zipf
+	votes *rand.Zipf
+
+	from time.Time
+	span time.Duration
+
+	titleMaxLength int
+	bodyMaxLength  int
+}
+
+func (zg *zipfGenerator) Next() *model.Story {
+	id := zg.uuid()
+	title := zg.title()
+	body := zg.body()
+
+	views := int64(zg.views.Uint64())
+	upVotes := min(int64(zg.votes.Uint64()), views)
+	downVotes := min(int64(zg.votes.Uint64()), views-upVotes)
+
+	createdAt := zg.from.Add(time.Duration(zg.rnd.Int63n(int64(zg.span)))).Truncate(time.Second)
+	updatedAt := createdAt
+
+	if zg.rnd.Float64() < editedRatio {
+		remaining := zg.from.Add(zg.span).Sub(createdAt)
+		updatedAt = createdAt.Add(time.Duration(zg.rnd.Int63n(int64(remaining)))).Truncate(time.Second)
+	}
+
+	return &model.Story{
+		ID:        id,
+		Title:     title,
+		Body:      body,
+		ViewCount: views,
+		UpVotes:   upVotes,
+		DownVotes: downVotes,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// THE IDS COME FROM THE SEEDED SOURCE AS WELL SO THE SAME SEED ALWAYS PRODUCES THE SAME STORIES
+func (zg *zipfGenerator) uuid() string {
+	b := make([]byte, 16)
+	_, _ = zg.rnd.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (zg *zipfGenerator) title() string {
+	n := minTitleWords + zg.rnd.Intn(maxTitleWords-minTitleWords+1)
+	return truncate(capitalize(zg.words(n)), zg.titleMaxLength)
+}
+
+func (zg *zipfGenerator) body() string {
+	length := int(math.Exp(math.Log(medianBodyLength) + zg.rnd.NormFloat64()*bodyLengthSigma))
+	if length < minBodyLength {
+		length = minBodyLength
+	}
+
+	var sb strings.Builder
+	for sentences := 0; sb.Len() < length; sentences++ {
+		if sentences > 0 {
+			sb.WriteString(zg.separator())
+		}
+
+		sb.WriteString(capitalize(zg.words(6+zg.rnd.Intn(15))) + ".")
+	}
+
+	return truncate(sb.String(), zg.bodyMaxLength)
+}
+
+// ROUGHLY ONE SENTENCE IN FIVE STARTS A NEW PARAGRAPH
+func (zg *zipfGenerator) separator() string {
+	if zg.rnd.Intn(5) == 0 {
+		return "\n\n"
+	}
+
+	return " "
+}
+
+func (zg *zipfGenerator) words(n int) string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = words[zg.rnd.Intn(len(words))]
+	}
+
+	return strings.Join(res, " ")
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// THE WORDS ARE ASCII, CUTTING ON BYTES NEVER SPLITS A CHARACTER
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	s = s[:max]
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+
+	return s
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// NewGenerator SPREADS THE STORIES OVER [from, to), to MUST BE AFTER from
+func NewGenerator(seed int64, from, to time.Time, cfg config.StoryConfig) Generator {
+	rnd := rand.New(rand.NewSource(seed))
+
+	return &zipfGenerator{
+		rnd:            rnd,
+		views:          rand.NewZipf(rnd, 1.5, 1, maxViews),
+		votes:          rand.NewZipf(rnd, 1.8, 1, maxVotes),
+		from:           from.UTC(),
+		span:           to.Sub(from),
+		titleMaxLength: cfg.TitleMaxLength(),
+		bodyMaxLength:  cfg.BodyMaxLength(),
+	}
+}
@@ -0,0 +1,84 @@
+package seed_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/seed"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var (
+	from = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").StoryConfig()
+
+	testCases := map[string]struct {
+		otherSeed    int64
+		expectedSame bool
+	}{
+		"test same seed generates the same stories":       {otherSeed: 42, expectedSame: true},
+		"test different seed generates different stories": {otherSeed: 43, expectedSame: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			one := generate(seed.NewGenerator(42, from, to, cfg), 50)
+			other := generate(seed.NewGenerator(testCase.otherSeed, from, to, cfg), 50)
+
+			assert.Equal(t, testCase.expectedSame, assert.ObjectsAreEqual(one, other))
+		})
+	}
+}
+
+func TestGeneratorStaysWithinBounds(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").StoryConfig()
+
+	stories := generate(seed.NewGenerator(7, from, to, cfg), 2000)
+
+	ids := make(map[string]bool)
+	var zeroViews, largeViews int
+
+	for _, st := range stories {
+		require.NotEmpty(t, st.GetTitle())
+		require.LessOrEqual(t, len(st.GetTitle()), cfg.TitleMaxLength())
+		require.NotEmpty(t, st.GetBody())
+		require.LessOrEqual(t, len(st.GetBody()), cfg.BodyMaxLength())
+
+		require.False(t, st.GetCreatedAt().Before(from))
+		require.True(t, st.GetCreatedAt().Before(to))
+		require.False(t, st.GetUpdatedAt().Before(st.GetCreatedAt()))
+
+		require.LessOrEqual(t, st.GetUpVotes()+st.GetDownVotes(), st.GetViewCount())
+
+		ids[st.GetID()] = true
+
+		switch {
+		case st.GetViewCount() == 0:
+			zeroViews++
+		case st.GetViewCount() > 1000:
+			largeViews++
+		}
+	}
+
+	assert.Equal(t, len(stories), len(ids))
+
+	// A LONG TAIL, MOST STORIES ARE BARELY SEEN WHILE A FEW ARE POPULAR
+	assert.Greater(t, zeroViews, len(stories)/4)
+	assert.Greater(t, largeViews, 0)
+	assert.Less(t, largeViews, len(stories)/10)
+}
+
+func generate(gen seed.Generator, n int) []model.Story {
+	stories := make([]model.Story, n)
+	for i := range stories {
+		stories[i] = *gen.Next()
+	}
+
+	return stories
+}
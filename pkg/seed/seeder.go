@@ -0,0 +1,61 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"go.uber.org/zap"
+)
+
+// Seeder INSERTS GENERATED STORIES THROUGH THE STORE IN BATCHES, EACH BATCH IS ALL OR NOTHING
+type Seeder interface {
+	// Seed RETURNS THE NUMBER OF STORIES INSERTED BEFORE IT STOPPED, A FAILED BATCH STOPS THE SEEDING
+	Seed(ctx context.Context, count int) (int, error)
+}
+
+type storeSeeder struct {
+	store     store.StoriesStore
+	gen       Generator
+	batchSize int
+	lgr       *zap.Logger
+}
+
+func (ss *storeSeeder) Seed(ctx context.Context, count int) (int, error) {
+	if ss.batchSize < 1 {
+		return 0, liberr.WithArgs(liberr.Operation("Seeder.Seed"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid batch size %d", ss.batchSize))
+	}
+
+	var seeded int
+
+	for seeded < count {
+		n := ss.batchSize
+		if count-seeded < n {
+			n = count - seeded
+		}
+
+		batch := make([]*model.Story, n)
+		for i := range batch {
+			batch[i] = ss.gen.Next()
+		}
+
+		if _, err := ss.store.AddStories(ctx, batch...); err != nil {
+			return seeded, liberr.WithArgs(liberr.Operation("Seeder.Seed"), err)
+		}
+
+		seeded += n
+		ss.lgr.Info("seeded stories", zap.Int("seeded", seeded), zap.Int("count", count))
+	}
+
+	return seeded, nil
+}
+
+func NewSeeder(str store.StoriesStore, gen Generator, batchSize int, lgr *zap.Logger) Seeder {
+	return &storeSeeder{
+		store:     str,
+		gen:       gen,
+		batchSize: batchSize,
+		lgr:       lgr,
+	}
+}
@@ -0,0 +1,91 @@
+package seed_test
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/seed"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestSeederInsertsInBatches(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").StoryConfig()
+
+	testCases := map[string]struct {
+		count           int
+		batchSize       int
+		expectedBatches []int
+	}{
+		"test count is a multiple of the batch size": {count: 6, batchSize: 3, expectedBatches: []int{3, 3}},
+		"test last batch holds the remainder":        {count: 7, batchSize: 3, expectedBatches: []int{3, 3, 1}},
+		"test batch larger than the count":           {count: 2, batchSize: 10, expectedBatches: []int{2}},
+		"test nothing to seed":                       {count: 0, batchSize: 10},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var batches []int
+
+			mockStore := &store.MockStoriesStore{}
+			mockStore.On("AddStories", mock.Anything, mock.AnythingOfType("[]*model.Story")).
+				Run(func(args mock.Arguments) { batches = append(batches, len(args.Get(1).([]*model.Story))) }).
+				Return([]string{}, nil)
+
+			sd := seed.NewSeeder(mockStore, seed.NewGenerator(1, from, to, cfg), testCase.batchSize, zap.NewNop())
+
+			n, err := sd.Seed(context.Background(), testCase.count)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.count, n)
+			assert.Equal(t, testCase.expectedBatches, batches)
+		})
+	}
+}
+
+func TestSeederStopsOnAFailedBatch(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").StoryConfig()
+
+	mockStore := &store.MockStoriesStore{}
+	mockStore.On("AddStories", mock.Anything, mock.AnythingOfType("[]*model.Story")).Return([]string{}, nil).Once()
+	mockStore.On("AddStories", mock.Anything, mock.AnythingOfType("[]*model.Story")).Return([]string{}, errors.New("failed to add stories")).Once()
+
+	sd := seed.NewSeeder(mockStore, seed.NewGenerator(1, from, to, cfg), 2, zap.NewNop())
+
+	n, err := sd.Seed(context.Background(), 10)
+	require.Error(t, err)
+
+	assert.Equal(t, 2, n)
+	mockStore.AssertNumberOfCalls(t, "AddStories", 2)
+}
+
+func TestSeederWritesToTheStore(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").StoryConfig()
+	str := store.NewInMemoryStoriesStore()
+
+	sd := seed.NewSeeder(str, seed.NewGenerator(1, from, to, cfg), 40, zap.NewNop())
+
+	n, err := sd.Seed(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 100, n)
+
+	stories, err := str.GetMostViewsStories(context.Background(), 0, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 100, len(stories))
+
+	expected := seed.NewGenerator(1, from, to, cfg).Next()
+
+	res, err := str.GetStories(context.Background(), expected.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Story{*expected}, res)
+
+	_, err = seed.NewSeeder(str, seed.NewGenerator(1, from, to, cfg), 40, zap.NewNop()).Seed(context.Background(), 1)
+	require.Error(t, err)
+	assert.Equal(t, liberr.Conflict, err.(*liberr.Error).Kind())
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
+)
+
+const (
+	insertAPIKey    = `INSERT INTO api_keys (id, name, keyHash, createdAt) VALUES ($1, $2, $3, $4)`
+	getAPIKeyByHash = `SELECT id, name, keyHash, createdAt, revokedAt FROM api_keys WHERE keyHash=$1`
+	listAPIKeys     = `SELECT id, name, keyHash, createdAt, revokedAt FROM api_keys ORDER BY createdAt, id`
+	revokeAPIKey    = `UPDATE api_keys SET revokedAt=$1 WHERE id=$2 AND revokedAt IS NULL`
+
+	sqliteInsertAPIKey    = `INSERT INTO api_keys (id, name, keyHash, createdAt) VALUES (?1, ?2, ?3, ?4)`
+	sqliteGetAPIKeyByHash = `SELECT id, name, keyHash, createdAt, revokedAt FROM api_keys WHERE keyHash=?1`
+	sqliteListAPIKeys     = `SELECT id, name, keyHash, createdAt, revokedAt FROM api_keys ORDER BY createdAt, id`
+	sqliteRevokeAPIKey    = `UPDATE api_keys SET revokedAt=?1 WHERE id=?2 AND revokedAt IS NULL`
+)
+
+// APIKeyStore ONLY KEEPS THE HASH OF A KEY, A REVOKED KEY IS KEPT SO THE LIST SHOWS WHEN IT STOPPED WORKING
+type APIKeyStore interface {
+	AddAPIKey(ctx context.Context, name, hash string) (auth.APIKey, error)
+
+	// GetAPIKeyByHash ALSO RETURNS REVOKED KEYS, IT IS UP TO THE CALLER TO REJECT THEM
+	GetAPIKeyByHash(ctx context.Context, hash string) (auth.APIKey, error)
+
+	ListAPIKeys(ctx context.Context) ([]auth.APIKey, error)
+
+	// RevokeAPIKey FAILS WITH A ResourceNotFound ERROR WHEN NO ACTIVE KEY HAS THE ID
+	RevokeAPIKey(ctx context.Context, id string) (int64, error)
+}
+
+type apiKeyQueries struct {
+	insert    string
+	getByHash string
+	list      string
+	revoke    string
+}
+
+// sqlAPIKeyStore SERVES BOTH DIALECTS, THEY ONLY DIFFER IN THE PLACEHOLDERS OF THE QUERIES
+type sqlAPIKeyStore struct {
+	db      *sql.DB
+	queries apiKeyQueries
+	now     func() time.Time
+}
+
+func (ss *sqlAPIKeyStore) AddAPIKey(ctx context.Context, name, hash string) (auth.APIKey, error) {
+	id, err := newUUID()
+	if err != nil {
+		return auth.APIKey{}, liberr.WithArgs(liberr.Operation("APIKeyStore.AddAPIKey.newUUID"), liberr.InternalError, liberr.SeverityError, err)
+	}
+
+	ak := auth.APIKey{ID: id, Name: name, Hash: hash, CreatedAt: ss.now().UTC().Truncate(time.Microsecond)}
+
+	if _, err := ss.db.ExecContext(ctx, ss.queries.insert, ak.ID, ak.Name, ak.Hash, ak.CreatedAt); err != nil {
+		return auth.APIKey{}, translateError("APIKeyStore.AddAPIKey.db.Exec", err)
+	}
+
+	return ak, nil
+}
+
+func (ss *sqlAPIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (auth.APIKey, error) {
+	ak, err := scanAPIKey(ss.db.QueryRowContext(ctx, ss.queries.getByHash, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.APIKey{}, apiKeyNotFound("APIKeyStore.GetAPIKeyByHash", "api key not found")
+	}
+
+	if err != nil {
+		return auth.APIKey{}, translateError("APIKeyStore.GetAPIKeyByHash.db.QueryRow", err)
+	}
+
+	return ak, nil
+}
+
+func (ss *sqlAPIKeyStore) ListAPIKeys(ctx context.Context) ([]auth.APIKey, error) {
+	rows, err := ss.db.QueryContext(ctx, ss.queries.list)
+	if err != nil {
+		return nil, translateError("APIKeyStore.ListAPIKeys.db.Query", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var keys []auth.APIKey
+
+	for rows.Next() {
+		ak, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, translateError("APIKeyStore.ListAPIKeys.rows.Scan", err)
+		}
+
+		keys = append(keys, ak)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, translateError("APIKeyStore.ListAPIKeys.rows.Err", err)
+	}
+
+	return keys, nil
+}
+
+func (ss *sqlAPIKeyStore) RevokeAPIKey(ctx context.Context, id string) (int64, error) {
+	if !isValidUUID(id) {
+		return 0, apiKeyNotFound("APIKeyStore.RevokeAPIKey", "no active api key with id "+id)
+	}
+
+	c, err := execQuery(ctx, ss.db, ss.queries.revoke, ss.now().UTC().Truncate(time.Microsecond), id)
+	if err != nil {
+		return 0, liberr.WithArgs(liberr.Operation("APIKeyStore.RevokeAPIKey"), err)
+	}
+
+	if c == 0 {
+		return 0, apiKeyNotFound("APIKeyStore.RevokeAPIKey", "no active api key with id "+id)
+	}
+
+	return c, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (auth.APIKey, error) {
+	var ak auth.APIKey
+	var revokedAt sql.NullTime
+
+	if err := row.Scan(&ak.ID, &ak.Name, &ak.Hash, &ak.CreatedAt, &revokedAt); err != nil {
+		return auth.APIKey{}, err
+	}
+
+	ak.CreatedAt = ak.CreatedAt.UTC()
+	if revokedAt.Valid {
+		t := revokedAt.Time.UTC()
+		ak.RevokedAt = &t
+	}
+
+	return ak, nil
+}
+
+func apiKeyNotFound(op, msg string) error {
+	return liberr.WithArgs(liberr.Operation(op), liberr.ResourceNotFound, liberr.CodeResourceNotFound, liberr.SeverityInfo, errors.New(msg))
+}
+
+func NewAPIKeyStore(db *sql.DB) APIKeyStore {
+	return newSQLAPIKeyStore(db, apiKeyQueries{insert: insertAPIKey, getByHash: getAPIKeyByHash, list: listAPIKeys, revoke: revokeAPIKey})
+}
+
+func NewSQLiteAPIKeyStore(db *sql.DB) APIKeyStore {
+	return newSQLAPIKeyStore(db, apiKeyQueries{insert: sqliteInsertAPIKey, getByHash: sqliteGetAPIKeyByHash, list: sqliteListAPIKeys, revoke: sqliteRevokeAPIKey})
+}
+
+func newSQLAPIKeyStore(db *sql.DB, queries apiKeyQueries) *sqlAPIKeyStore {
+	return &sqlAPIKeyStore{
+		db:      db,
+		queries: queries,
+		now:     time.Now,
+	}
+}
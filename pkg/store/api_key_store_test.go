@@ -0,0 +1,133 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// EVERY APIKeyStore IMPLEMENTATION MUST PASS THIS SUITE, newStore MUST RETURN AN EMPTY STORE
+func testAPIKeyStoreConformance(t *testing.T, newStore func(t *testing.T) store.APIKeyStore) {
+	t.Run("test add and get api key", func(t *testing.T) {
+		str := newStore(t)
+
+		added, err := str.AddAPIKey(context.Background(), "ci", auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+		assert.True(t, isValidUUID(added.ID))
+		assert.False(t, added.CreatedAt.IsZero())
+		assert.False(t, added.Revoked())
+
+		res, err := str.GetAPIKeyByHash(context.Background(), auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+		assert.Equal(t, added.ID, res.ID)
+		assert.Equal(t, "ci", res.Name)
+		assert.True(t, added.CreatedAt.Equal(res.CreatedAt))
+	})
+
+	t.Run("test get unknown api key", func(t *testing.T) {
+		_, err := newStore(t).GetAPIKeyByHash(context.Background(), auth.HashAPIKey("sk_unknown"))
+		require.Error(t, err)
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test add api key fails on constraint violation", func(t *testing.T) {
+		str := newStore(t)
+
+		for _, name := range []string{"", strings.Repeat("a", 101)} {
+			_, err := str.AddAPIKey(context.Background(), name, auth.HashAPIKey("sk_"+name))
+			require.Error(t, err)
+			assert.Equal(t, liberr.ConstraintViolation, err.(*liberr.Error).Kind())
+		}
+	})
+
+	t.Run("test add api key fails on duplicate hash", func(t *testing.T) {
+		str := newStore(t)
+
+		_, err := str.AddAPIKey(context.Background(), "one", auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+
+		_, err = str.AddAPIKey(context.Background(), "two", auth.HashAPIKey("sk_one"))
+		require.Error(t, err)
+		assert.Equal(t, liberr.Conflict, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test list api keys", func(t *testing.T) {
+		str := newStore(t)
+
+		res, err := str.ListAPIKeys(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, res)
+
+		one, err := str.AddAPIKey(context.Background(), "one", auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+
+		two, err := str.AddAPIKey(context.Background(), "two", auth.HashAPIKey("sk_two"))
+		require.NoError(t, err)
+
+		res, err = str.ListAPIKeys(context.Background())
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		assert.ElementsMatch(t, []string{one.ID, two.ID}, []string{res[0].ID, res[1].ID})
+	})
+
+	t.Run("test revoke api key", func(t *testing.T) {
+		str := newStore(t)
+
+		added, err := str.AddAPIKey(context.Background(), "ci", auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+
+		c, err := str.RevokeAPIKey(context.Background(), added.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		res, err := str.GetAPIKeyByHash(context.Background(), auth.HashAPIKey("sk_one"))
+		require.NoError(t, err)
+		assert.True(t, res.Revoked())
+
+		_, err = str.RevokeAPIKey(context.Background(), added.ID)
+		require.Error(t, err)
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test revoke unknown api key", func(t *testing.T) {
+		str := newStore(t)
+
+		for _, id := range []string{"ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "not-an-id"} {
+			_, err := str.RevokeAPIKey(context.Background(), id)
+			require.Error(t, err)
+			assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+		}
+	})
+}
+
+func TestInMemoryAPIKeyStoreConformance(t *testing.T) {
+	testAPIKeyStoreConformance(t, func(t *testing.T) store.APIKeyStore {
+		return store.NewInMemoryAPIKeyStore()
+	})
+}
+
+func TestSQLiteAPIKeyStoreConformance(t *testing.T) {
+	testAPIKeyStoreConformance(t, func(t *testing.T) store.APIKeyStore {
+		return store.NewSQLiteAPIKeyStore(getSQLiteDB(t))
+	})
+}
+
+func TestPostgresAPIKeyStoreConformance(t *testing.T) {
+	db := getDB(t)
+
+	testAPIKeyStoreConformance(t, func(t *testing.T) store.APIKeyStore {
+		truncateAPIKeys(t, db)
+		return store.NewAPIKeyStore(db)
+	})
+}
+
+func truncateAPIKeys(t *testing.T, db *sql.DB) {
+	_, err := db.Exec(`TRUNCATE api_keys`)
+	require.NoError(t, err)
+}
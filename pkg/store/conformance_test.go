@@ -0,0 +1,672 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// EVERY StoriesStore IMPLEMENTATION MUST PASS THIS SUITE, newStore MUST RETURN AN EMPTY STORE
+func testStoriesStoreConformance(t *testing.T, newStore func(t *testing.T) store.StoriesStore) {
+	titles := func(stories []model.Story) []string {
+		var res []string
+		for _, st := range stories {
+			res = append(res, st.GetTitle())
+		}
+		return res
+	}
+
+	// A ZERO LEASE RUNS OUT RIGHT AWAY, SO THE CLAIM READS THE EVENTS NOT PUBLISHED YET WITHOUT HOLDING ANY BACK
+	unpublished := func(t *testing.T, str store.StoriesStore, limit int) []model.Event {
+		events, err := str.ClaimEvents(context.Background(), limit, 0)
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("test add and get stories", func(t *testing.T) {
+		str := newStore(t)
+
+		idOne, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 1, 2))
+		require.NoError(t, err)
+		assert.True(t, isValidUUID(idOne))
+
+		idTwo, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+		assert.True(t, isValidUUID(idTwo))
+
+		res, err := str.GetStories(context.Background(), idTwo, idOne)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"one", "two"}, titles(res))
+
+		for _, st := range res {
+			if st.GetID() == idOne {
+				assert.Equal(t, "this is story one", st.GetBody())
+				assert.Equal(t, int64(1), st.GetViewCount())
+				assert.Equal(t, int64(2), st.GetUpVotes())
+				assert.False(t, st.GetCreatedAt().IsZero())
+				assert.True(t, st.GetCreatedAt().Equal(st.GetUpdatedAt()))
+			}
+		}
+	})
+
+	t.Run("test add story fails on constraint violation", func(t *testing.T) {
+		str := newStore(t)
+
+		st := newMemoryStory(t, "one", 0, 0)
+		st.Title = ""
+
+		id, err := str.AddStory(context.Background(), st)
+		require.Error(t, err)
+		assert.Equal(t, liberr.ConstraintViolation, err.(*liberr.Error).Kind())
+		assert.Equal(t, "", id)
+	})
+
+	t.Run("test add stories", func(t *testing.T) {
+		str := newStore(t)
+
+		createdAt := time.Date(2020, 3, 14, 9, 30, 0, 0, time.UTC)
+
+		backdated := newMemoryStory(t, "one", 5, 2)
+		backdated.ID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+		backdated.CreatedAt = createdAt
+
+		ids, err := str.AddStories(context.Background(), backdated, newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(ids))
+		assert.Equal(t, backdated.ID, ids[0])
+		assert.True(t, isValidUUID(ids[1]))
+
+		res, err := str.GetStories(context.Background(), ids[0])
+		require.NoError(t, err)
+		assert.True(t, createdAt.Equal(res[0].GetCreatedAt()))
+		assert.True(t, createdAt.Equal(res[0].GetUpdatedAt()))
+
+		stats, err := str.GetDailyStats(context.Background(), ids[0], createdAt, createdAt)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stats))
+		assert.Equal(t, int64(5), stats[0].GetViews())
+
+		assert.Empty(t, unpublished(t, str, 10))
+
+		ids, err = str.AddStories(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("test add stories is all or nothing", func(t *testing.T) {
+		str := newStore(t)
+
+		invalid := newMemoryStory(t, "two", 0, 0)
+		invalid.Title = ""
+
+		_, err := str.AddStories(context.Background(), newMemoryStory(t, "one", 0, 0), invalid)
+		require.Error(t, err)
+		assert.Equal(t, liberr.ConstraintViolation, err.(*liberr.Error).Kind())
+
+		existing := newMemoryStory(t, "three", 0, 0)
+		existing.ID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+
+		_, err = str.AddStories(context.Background(), existing)
+		require.NoError(t, err)
+
+		_, err = str.AddStories(context.Background(), newMemoryStory(t, "four", 0, 0), existing)
+		require.Error(t, err)
+		assert.Equal(t, liberr.Conflict, err.(*liberr.Error).Kind())
+
+		res, err := str.GetMostViewsStories(context.Background(), 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"three"}, titles(res))
+	})
+
+	t.Run("test get stories failures", func(t *testing.T) {
+		str := newStore(t)
+
+		_, err := str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a")
+		assert.Equal(t, "no records found", err.Error())
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+
+		_, err = str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "abc")
+		assert.Equal(t, "invalid uuid abc", err.Error())
+		assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test update story", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		res, err := str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		st := &res[0]
+		st.Title = "updated"
+		st.AddView()
+		st.DownVote()
+
+		c, err := str.UpdateStory(context.Background(), st, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		res, err = str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		assert.Equal(t, "updated", res[0].GetTitle())
+		assert.Equal(t, int64(1), res[0].GetViewCount())
+		assert.Equal(t, int64(1), res[0].GetDownVotes())
+		assert.True(t, st.GetCreatedAt().Equal(res[0].GetCreatedAt()))
+
+		st.ID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+
+		c, err = str.UpdateStory(context.Background(), st, time.Time{})
+		assert.Equal(t, "failed to update story", err.Error())
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+		assert.Equal(t, int64(0), c)
+	})
+
+	t.Run("test patch story keeps the counters in the store", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		require.NoError(t, str.IncrementCounters(context.Background(), model.CounterDelta{StoryID: id, Views: 3, UpVotes: 1}))
+
+		title := "patched"
+
+		c, err := str.PatchStory(context.Background(), model.StoryPatch{ID: id, Title: &title}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		res, err := str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		assert.Equal(t, "patched", res[0].GetTitle())
+		assert.Equal(t, "this is story one", res[0].GetBody())
+		assert.Equal(t, int64(3), res[0].GetViewCount())
+		assert.Equal(t, int64(1), res[0].GetUpVotes())
+
+		events := unpublished(t, str, 10)
+		require.NotEmpty(t, events)
+		assert.Equal(t, model.EventStoryUpdated, events[len(events)-1].GetType())
+
+		empty := ""
+
+		_, err = str.PatchStory(context.Background(), model.StoryPatch{ID: id, Body: &empty}, time.Time{})
+		assert.Equal(t, liberr.ConstraintViolation, err.(*liberr.Error).Kind())
+
+		c, err = str.PatchStory(context.Background(), model.StoryPatch{ID: "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", Title: &title}, time.Time{})
+		assert.Equal(t, "failed to update story", err.Error())
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+		assert.Equal(t, int64(0), c)
+	})
+
+	t.Run("test conditional writes only change the version they expect", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		res, err := str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		read := res[0].GetUpdatedAt()
+		title := "first"
+
+		_, err = str.PatchStory(context.Background(), model.StoryPatch{ID: id, Title: &title}, read)
+		require.NoError(t, err)
+
+		title = "second"
+
+		_, err = str.PatchStory(context.Background(), model.StoryPatch{ID: id, Title: &title}, read)
+		assert.Equal(t, liberr.PreconditionFailed, err.(*liberr.Error).Kind())
+
+		_, err = str.UpdateStory(context.Background(), &res[0], read)
+		assert.Equal(t, liberr.PreconditionFailed, err.(*liberr.Error).Kind())
+
+		_, err = str.DeleteStory(context.Background(), id, read)
+		assert.Equal(t, liberr.PreconditionFailed, err.(*liberr.Error).Kind())
+
+		res, err = str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "first", res[0].GetTitle())
+
+		c, err := str.DeleteStory(context.Background(), id, res[0].GetUpdatedAt())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		_, err = str.DeleteStory(context.Background(), id, read)
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test delete story", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		c, err := str.DeleteStory(context.Background(), id, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), c)
+
+		_, err = str.GetStories(context.Background(), id)
+		assert.Equal(t, "no records found", err.Error())
+
+		c, err = str.DeleteStory(context.Background(), id, time.Time{})
+		assert.Equal(t, "failed to delete story", err.Error())
+		assert.Equal(t, liberr.ResourceNotFound, err.(*liberr.Error).Kind())
+		assert.Equal(t, int64(0), c)
+	})
+
+	t.Run("test writes reject a malformed id", func(t *testing.T) {
+		str := newStore(t)
+
+		title := "title"
+
+		testCases := map[string]func() (int64, error){
+			"update": func() (int64, error) {
+				return str.UpdateStory(context.Background(), &model.Story{ID: "not-a-uuid", Title: "title", Body: "body"}, time.Time{})
+			},
+			"patch": func() (int64, error) {
+				return str.PatchStory(context.Background(), model.StoryPatch{ID: "not-a-uuid", Title: &title}, time.Time{})
+			},
+			"delete": func() (int64, error) {
+				return str.DeleteStory(context.Background(), "not-a-uuid", time.Time{})
+			},
+		}
+
+		for name, write := range testCases {
+			t.Run(name, func(t *testing.T) {
+				c, err := write()
+				require.Error(t, err)
+				assert.Equal(t, "invalid uuid not-a-uuid", err.Error())
+				assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+				assert.Equal(t, liberr.CodeInvalidStoryID, err.(*liberr.Error).Code())
+				assert.Equal(t, int64(0), c)
+			})
+		}
+	})
+
+	t.Run("test sorted pages", func(t *testing.T) {
+		str := newStore(t)
+
+		for _, st := range []*model.Story{
+			newMemoryStory(t, "one", 1, 5),
+			newMemoryStory(t, "two", 10, 0),
+			newMemoryStory(t, "three", 12, 12),
+			newMemoryStory(t, "four", 3, 10),
+		} {
+			_, err := str.AddStory(context.Background(), st)
+			require.NoError(t, err)
+		}
+
+		res, err := str.GetMostViewsStories(context.Background(), 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"three", "two"}, titles(res))
+
+		res, err = str.GetMostViewsStories(context.Background(), 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"four", "one"}, titles(res))
+
+		res, err = str.GetTopRatedStories(context.Background(), 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"three", "four", "one", "two"}, titles(res))
+
+		_, err = str.GetTopRatedStories(context.Background(), 4, 2)
+		assert.Equal(t, "no records found", err.Error())
+
+		_, err = str.GetMostViewsStories(context.Background(), 0, -1)
+		assert.Error(t, err)
+
+		_, err = str.GetTopRatedStories(context.Background(), -1, 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("test analytics", func(t *testing.T) {
+		str := newStore(t)
+
+		today := model.ToDay(time.Now())
+		yesterday := today.AddDate(0, 0, -1)
+
+		idOne, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 5, 0))
+		require.NoError(t, err)
+
+		idTwo, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+
+		res, err := str.GetStories(context.Background(), idTwo)
+		require.NoError(t, err)
+
+		for i := 0; i < 8; i++ {
+			res[0].AddView()
+		}
+		res[0].DownVote()
+
+		_, err = str.UpdateStory(context.Background(), &res[0], time.Time{})
+		require.NoError(t, err)
+
+		stats, err := str.GetDailyStats(context.Background(), idTwo, yesterday, today)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stats))
+		assert.Equal(t, idTwo, stats[0].GetStoryID())
+		assert.True(t, today.Equal(stats[0].GetDay()))
+		assert.Equal(t, int64(8), stats[0].GetViews())
+		assert.Equal(t, int64(1), stats[0].GetDownVotes())
+
+		movers, err := str.GetTopMovers(context.Background(), model.MetricViews, yesterday, yesterday, today, today, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []model.Mover{
+			{StoryID: idTwo, Previous: 0, Current: 8},
+			{StoryID: idOne, Previous: 0, Current: 5},
+		}, movers)
+
+		_, err = str.DeleteStory(context.Background(), idTwo, time.Time{})
+		require.NoError(t, err)
+
+		stats, err = str.GetDailyStats(context.Background(), idTwo, yesterday, today)
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+
+		_, err = str.GetDailyStats(context.Background(), "abc", yesterday, today)
+		assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+
+		_, err = str.GetTopMovers(context.Background(), model.Metric("likes"), yesterday, yesterday, today, today, 10)
+		assert.Equal(t, "invalid metric likes", err.Error())
+	})
+
+	t.Run("test increment counters", func(t *testing.T) {
+		str := newStore(t)
+
+		today := model.ToDay(time.Now())
+
+		idOne, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 1, 2))
+		require.NoError(t, err)
+
+		idTwo, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+
+		err = str.IncrementCounters(context.Background(),
+			model.CounterDelta{StoryID: idOne, Views: 2},
+			model.CounterDelta{StoryID: idTwo, UpVotes: 1, DownVotes: 1},
+			model.CounterDelta{StoryID: idOne, Views: 1, UpVotes: 1},
+			model.CounterDelta{StoryID: "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", Views: 10},
+		)
+		require.NoError(t, err)
+
+		res, err := str.GetStories(context.Background(), idOne, idTwo)
+		require.NoError(t, err)
+
+		for _, st := range res {
+			switch st.GetID() {
+			case idOne:
+				assert.Equal(t, int64(4), st.GetViewCount())
+				assert.Equal(t, int64(3), st.GetUpVotes())
+			case idTwo:
+				assert.Equal(t, int64(1), st.GetUpVotes())
+				assert.Equal(t, int64(1), st.GetDownVotes())
+			}
+		}
+
+		stats, err := str.GetDailyStats(context.Background(), idOne, today, today)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stats))
+		assert.Equal(t, int64(4), stats[0].GetViews())
+		assert.Equal(t, int64(3), stats[0].GetUpVotes())
+
+		require.NoError(t, str.IncrementCounters(context.Background()))
+
+		err = str.IncrementCounters(context.Background(), model.CounterDelta{StoryID: "abc", Views: 1})
+		assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+	})
+
+	t.Run("test mutations write outbox events", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		res, err := str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		res[0].Title = "updated"
+		_, err = str.UpdateStory(context.Background(), &res[0], time.Time{})
+		require.NoError(t, err)
+
+		err = str.IncrementCounters(context.Background(),
+			model.CounterDelta{StoryID: id, Views: 1},
+			model.CounterDelta{StoryID: id, UpVotes: 1},
+			model.CounterDelta{StoryID: "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", UpVotes: 1},
+		)
+		require.NoError(t, err)
+
+		_, err = str.DeleteStory(context.Background(), id, time.Time{})
+		require.NoError(t, err)
+
+		_, err = str.DeleteStory(context.Background(), id, time.Time{})
+		require.Error(t, err)
+
+		events := unpublished(t, str, 10)
+		require.Equal(t, 4, len(events))
+
+		expectedTypes := []model.EventType{model.EventStoryCreated, model.EventStoryUpdated, model.EventStoryVoted, model.EventStoryDeleted}
+
+		for i, e := range events {
+			assert.Equal(t, expectedTypes[i], e.GetType())
+			assert.Equal(t, id, e.GetStoryID())
+			assert.False(t, e.CreatedAt.IsZero())
+
+			if i > 0 {
+				assert.True(t, e.GetID() > events[i-1].GetID())
+			}
+		}
+
+		assert.JSONEq(t, fmt.Sprintf(`{"id":"%s","title":"updated","body":"this is story one","viewCount":0,"upVotes":0,"downVotes":0}`, id), string(events[1].Payload))
+		assert.JSONEq(t, fmt.Sprintf(`{"id":"%s","upVotes":1,"downVotes":0}`, id), string(events[2].Payload))
+	})
+
+	t.Run("test mark events published", func(t *testing.T) {
+		str := newStore(t)
+
+		_, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		_, err = str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+
+		events := unpublished(t, str, 1)
+		require.Equal(t, 1, len(events))
+
+		require.NoError(t, str.MarkEventsPublished(context.Background(), events[0].GetID()))
+		require.NoError(t, str.MarkEventsPublished(context.Background()))
+
+		pending := unpublished(t, str, 10)
+		require.Equal(t, 1, len(pending))
+		assert.True(t, pending[0].GetID() > events[0].GetID())
+	})
+
+	t.Run("test claim events skips the stories under lease", func(t *testing.T) {
+		str := newStore(t)
+
+		one, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		two, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+		require.NoError(t, err)
+
+		require.NoError(t, str.IncrementCounters(context.Background(), model.CounterDelta{StoryID: one, UpVotes: 1}))
+
+		first, err := str.ClaimEvents(context.Background(), 1, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(first))
+		assert.Equal(t, one, first[0].GetStoryID())
+
+		second, err := str.ClaimEvents(context.Background(), 10, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(second))
+		assert.Equal(t, two, second[0].GetStoryID())
+
+		none, err := str.ClaimEvents(context.Background(), 10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+
+		require.NoError(t, str.MarkEventsPublished(context.Background(), first[0].GetID()))
+
+		third, err := str.ClaimEvents(context.Background(), 10, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(third))
+		assert.Equal(t, one, third[0].GetStoryID())
+		assert.Equal(t, model.EventStoryVoted, third[0].GetType())
+	})
+
+	t.Run("test claim events takes back the events of an expired lease", func(t *testing.T) {
+		str := newStore(t)
+
+		_, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		claimed, err := str.ClaimEvents(context.Background(), 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(claimed))
+
+		time.Sleep(10 * time.Millisecond)
+
+		again, err := str.ClaimEvents(context.Background(), 10, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(again))
+		assert.Equal(t, claimed[0].GetID(), again[0].GetID())
+	})
+
+	t.Run("test canceled context aborts the operation", func(t *testing.T) {
+		str := newStore(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := str.AddStory(ctx, newMemoryStory(t, "one", 0, 0))
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		_, err = str.GetMostViewsStories(ctx, 0, 10)
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		err = str.WithTx(ctx, func(tx store.StoriesStore) error { return nil })
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		_, err = str.GetMostViewsStories(context.Background(), 0, 10)
+		assert.Equal(t, "no records found", err.Error())
+	})
+
+	t.Run("test with tx commits when the callback succeeds", func(t *testing.T) {
+		str := newStore(t)
+
+		var ids []string
+		err := str.WithTx(context.Background(), func(tx store.StoriesStore) error {
+			for _, title := range []string{"one", "two"} {
+				id, err := tx.AddStory(context.Background(), newMemoryStory(t, title, 0, 0))
+				if err != nil {
+					return err
+				}
+
+				ids = append(ids, id)
+			}
+
+			res, err := tx.GetStories(context.Background(), ids...)
+			if err != nil {
+				return err
+			}
+
+			res[0].UpVote()
+
+			_, err = tx.UpdateStory(context.Background(), &res[0], time.Time{})
+			return err
+		})
+
+		require.NoError(t, err)
+
+		res, err := str.GetTopRatedStories(context.Background(), 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(res))
+		assert.Equal(t, int64(1), res[0].GetUpVotes())
+	})
+
+	t.Run("test with tx rolls back when the callback fails", func(t *testing.T) {
+		str := newStore(t)
+
+		id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+		require.NoError(t, err)
+
+		err = str.WithTx(context.Background(), func(tx store.StoriesStore) error {
+			if _, err := tx.DeleteStory(context.Background(), id, time.Time{}); err != nil {
+				return err
+			}
+
+			return tx.WithTx(context.Background(), func(inner store.StoriesStore) error {
+				if _, err := inner.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0)); err != nil {
+					return err
+				}
+
+				return errors.New("abort")
+			})
+		})
+
+		assert.Equal(t, "abort", err.Error())
+
+		res, err := str.GetMostViewsStories(context.Background(), 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one"}, titles(res))
+	})
+}
+
+func TestInMemoryStoriesStoreConformance(t *testing.T) {
+	testStoriesStoreConformance(t, func(t *testing.T) store.StoriesStore {
+		return store.NewInMemoryStoriesStore()
+	})
+}
+
+func TestSQLiteStoriesStoreConformance(t *testing.T) {
+	testStoriesStoreConformance(t, func(t *testing.T) store.StoriesStore {
+		return store.NewSQLiteStoriesStore(getSQLiteDB(t))
+	})
+}
+
+func TestPostgresStoriesStoreConformance(t *testing.T) {
+	db := getDB(t)
+
+	testStoriesStoreConformance(t, func(t *testing.T) store.StoriesStore {
+		truncate(t, db)
+		return store.NewStoriesStore(db)
+	})
+}
+
+func getSQLiteDB(t *testing.T) *sql.DB {
+	db, err := sql.Open(store.SQLiteDriverName, fmt.Sprintf("file:%s?_foreign_keys=on", filepath.Join(t.TempDir(), "stories.db")))
+	require.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	files, err := filepath.Glob("migrations/sqlite/*.up.sql")
+	require.NoError(t, err)
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+
+		_, err = db.Exec(string(data))
+		require.NoError(t, err)
+	}
+
+	return db
+}
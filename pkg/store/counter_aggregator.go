@@ -0,0 +1,266 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CounterAggregator IS A WRITE BEHIND StoriesStore, IncrementCounters ONLY BUFFERS THE DELTAS PER STORY AND
+// THEY ARE WRITTEN IN BATCHES EVERY FLUSH INTERVAL OR AS SOON AS THE FLUSH THRESHOLD IS REACHED. EVERY OTHER
+// CALL GOES STRAIGHT TO THE WRAPPED STORE, SO READS DO NOT SEE THE INCREMENTS THAT ARE STILL PENDING.
+type CounterAggregator interface {
+	StoriesStore
+
+	Start()
+
+	// Stop WAITS FOR A FLUSH IN PROGRESS AND WRITES WHATEVER IS STILL BUFFERED, IT MUST ONLY BE
+	// CALLED ONCE NOTHING CAN INCREMENT A COUNTER ANYMORE
+	Stop() error
+}
+
+type counterAggregator struct {
+	StoriesStore
+
+	pr  reporters.Prometheus
+	lgr *zap.Logger
+
+	flushInterval    time.Duration
+	flushThreshold   int
+	batchSize        int
+	maxFlushAttempts int
+	shutdownTimeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[string]model.CounterDelta
+
+	// attempts COUNTS THE FAILED FLUSHES OF THE STORIES WHOSE INCREMENTS ARE BACK IN pending
+	attempts map[string]int
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func (ca *counterAggregator) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	if err := checkContext(ctx, "CounterAggregator.IncrementCounters"); err != nil {
+		return err
+	}
+
+	for _, d := range deltas {
+		if !isValidUUID(d.StoryID) {
+			return liberr.WithArgs(liberr.Operation("CounterAggregator.IncrementCounters.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", d.StoryID))
+		}
+	}
+
+	// WITHOUT AN INTERVAL THERE IS NOTHING TO FLUSH THE BUFFER, WRITE THROUGH INSTEAD
+	if ca.flushInterval <= 0 {
+		return ca.StoriesStore.IncrementCounters(ctx, deltas...)
+	}
+
+	n := ca.add(deltas...)
+
+	if ca.flushThreshold > 0 && n >= ca.flushThreshold {
+		select {
+		case ca.flush <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// add RETURNS THE NUMBER OF STORIES WITH PENDING INCREMENTS
+func (ca *counterAggregator) add(deltas ...model.CounterDelta) int {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	for _, d := range deltas {
+		p, ok := ca.pending[d.StoryID]
+		if !ok {
+			p = model.CounterDelta{StoryID: d.StoryID}
+		}
+
+		p.Merge(d)
+		ca.pending[d.StoryID] = p
+	}
+
+	return len(ca.pending)
+}
+
+func (ca *counterAggregator) Start() {
+	if ca.flushInterval <= 0 {
+		return
+	}
+
+	ca.startOnce.Do(func() { go ca.run() })
+}
+
+func (ca *counterAggregator) run() {
+	defer close(ca.done)
+
+	ticker := time.NewTicker(ca.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ca.stop:
+			return
+		case <-ticker.C:
+		case <-ca.flush:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), ca.shutdownTimeout)
+		_ = ca.flushPending(ctx)
+		cancel()
+	}
+}
+
+func (ca *counterAggregator) Stop() error {
+	var err error
+
+	ca.stopOnce.Do(func() {
+		// WHEN THE LOOP WAS NEVER STARTED THERE IS NOTHING TO WAIT FOR
+		ca.startOnce.Do(func() { close(ca.done) })
+
+		close(ca.stop)
+		<-ca.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), ca.shutdownTimeout)
+		defer cancel()
+
+		err = ca.flushPending(ctx)
+	})
+
+	return err
+}
+
+// flushPending WRITES THE BUFFER IN BATCHES SORTED BY STORY SO CONCURRENT FLUSHES FROM SEVERAL INSTANCES
+// LOCK THE ROWS IN THE SAME ORDER, A BATCH THAT FAILS IS PUT BACK TO BE RETRIED ON THE NEXT FLUSH UNTIL
+// ITS STORIES HAVE FAILED maxFlushAttempts TIMES, THEN THEIR INCREMENTS ARE DROPPED SO THE BUFFER CANNOT
+// GROW FOR AS LONG AS THE STORE STAYS DOWN
+func (ca *counterAggregator) flushPending(ctx context.Context) error {
+	ca.mu.Lock()
+	pending := ca.pending
+	ca.pending = make(map[string]model.CounterDelta)
+	ca.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	deltas := make([]model.CounterDelta, 0, len(pending))
+	for _, d := range pending {
+		deltas = append(deltas, d)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].StoryID < deltas[j].StoryID })
+
+	size := ca.batchSize
+	if size <= 0 {
+		size = len(deltas)
+	}
+
+	var failed []model.CounterDelta
+	var flushErr error
+
+	for start := 0; start < len(deltas); start += size {
+		end := start + size
+		if end > len(deltas) {
+			end = len(deltas)
+		}
+
+		err := ca.StoriesStore.IncrementCounters(ctx, deltas[start:end]...)
+		if err == nil {
+			ca.forget(deltas[start:end]...)
+			continue
+		}
+
+		failed = append(failed, deltas[start:end]...)
+		if flushErr == nil {
+			flushErr = err
+		}
+	}
+
+	if flushErr == nil {
+		return nil
+	}
+
+	ca.lgr.Sugar().Errorf("failed to flush counters of %d stories: %s", len(failed), flushErr.Error())
+
+	if dropped := ca.requeue(failed...); len(dropped) != 0 {
+		ca.pr.ReportDroppedCounters(len(dropped))
+		ca.lgr.Sugar().Errorf("dropped the counters of %d stories after %d failed flushes: %s", len(dropped), ca.maxFlushAttempts, strings.Join(dropped, ", "))
+	}
+
+	return liberr.WithArgs(liberr.Operation("CounterAggregator.flushPending"), flushErr)
+}
+
+// requeue PUTS THE FAILED DELTAS BACK IN THE BUFFER AND RETURNS THE STORIES THAT RAN OUT OF ATTEMPTS INSTEAD
+func (ca *counterAggregator) requeue(failed ...model.CounterDelta) []string {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	var dropped []string
+	for _, d := range failed {
+		ca.attempts[d.StoryID]++
+
+		if ca.attempts[d.StoryID] >= ca.maxFlushAttempts {
+			delete(ca.attempts, d.StoryID)
+			dropped = append(dropped, d.StoryID)
+			continue
+		}
+
+		p, ok := ca.pending[d.StoryID]
+		if !ok {
+			p = model.CounterDelta{StoryID: d.StoryID}
+		}
+
+		p.Merge(d)
+		ca.pending[d.StoryID] = p
+	}
+
+	return dropped
+}
+
+func (ca *counterAggregator) forget(flushed ...model.CounterDelta) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	for _, d := range flushed {
+		delete(ca.attempts, d.StoryID)
+	}
+}
+
+func NewCounterAggregator(store StoriesStore, cfg config.CounterConfig, pr reporters.Prometheus, lgr *zap.Logger) CounterAggregator {
+	return newCounterAggregator(store, cfg.FlushInterval(), cfg.FlushThreshold(), cfg.BatchSize(), cfg.MaxFlushAttempts(), cfg.ShutdownTimeout(), pr, lgr)
+}
+
+func newCounterAggregator(store StoriesStore, flushInterval time.Duration, flushThreshold, batchSize, maxFlushAttempts int, shutdownTimeout time.Duration, pr reporters.Prometheus, lgr *zap.Logger) *counterAggregator {
+	return &counterAggregator{
+		StoriesStore:     store,
+		pr:               pr,
+		lgr:              lgr,
+		flushInterval:    flushInterval,
+		flushThreshold:   flushThreshold,
+		batchSize:        batchSize,
+		maxFlushAttempts: maxFlushAttempts,
+		shutdownTimeout:  shutdownTimeout,
+		pending:          make(map[string]model.CounterDelta),
+		attempts:         make(map[string]int),
+		flush:            make(chan struct{}, 1),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
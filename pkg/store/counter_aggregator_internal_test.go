@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+const (
+	aggregatedStoryOne = "0ec1eb19-b4b4-4bd0-9d5b-7b9b2c4b0c3b"
+	aggregatedStoryTwo = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+)
+
+func TestCounterAggregatorFlushesMergedDeltasOnStop(t *testing.T) {
+	ms := &MockStoriesStore{}
+	ms.On("IncrementCounters", mock.Anything, []model.CounterDelta{
+		{StoryID: aggregatedStoryOne, Views: 2, UpVotes: 1},
+	}).Return(nil).Once()
+	ms.On("IncrementCounters", mock.Anything, []model.CounterDelta{
+		{StoryID: aggregatedStoryTwo, DownVotes: 1},
+	}).Return(nil).Once()
+
+	ca := newCounterAggregator(ms, time.Hour, 0, 1, 5, time.Second, &reporters.MockPrometheus{}, zap.NewNop())
+	ca.Start()
+
+	for _, d := range []model.CounterDelta{
+		{StoryID: aggregatedStoryTwo, DownVotes: 1},
+		{StoryID: aggregatedStoryOne, Views: 1},
+		{StoryID: aggregatedStoryOne, Views: 1, UpVotes: 1},
+	} {
+		require.NoError(t, ca.IncrementCounters(context.Background(), d))
+	}
+
+	ms.AssertNotCalled(t, "IncrementCounters", mock.Anything, mock.Anything)
+
+	require.NoError(t, ca.Stop())
+	require.NoError(t, ca.Stop())
+
+	ms.AssertExpectations(t)
+}
+
+func TestCounterAggregatorFlushesOnThreshold(t *testing.T) {
+	str := NewInMemoryStoriesStore()
+
+	st, err := model.NewStoryBuilder().SetTitle(100, "title").SetBody(100, "body").Build()
+	require.NoError(t, err)
+
+	id, err := str.AddStory(context.Background(), st)
+	require.NoError(t, err)
+
+	ca := newCounterAggregator(str, time.Hour, 1, 500, 5, time.Second, &reporters.MockPrometheus{}, zap.NewNop())
+	ca.Start()
+	defer func() { _ = ca.Stop() }()
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: id, Views: 1}))
+
+	assert.Eventually(t, func() bool {
+		res, err := str.GetStories(context.Background(), id)
+		return err == nil && res[0].GetViewCount() == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCounterAggregatorRequeuesFailedBatch(t *testing.T) {
+	ms := &MockStoriesStore{}
+	ms.On("IncrementCounters", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Once()
+	ms.On("IncrementCounters", mock.Anything, []model.CounterDelta{
+		{StoryID: aggregatedStoryOne, Views: 3},
+	}).Return(nil).Once()
+
+	ca := newCounterAggregator(ms, time.Hour, 0, 500, 5, time.Second, &reporters.MockPrometheus{}, zap.NewNop())
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: aggregatedStoryOne, Views: 1}))
+
+	err := ca.flushPending(context.Background())
+	assert.Equal(t, "connection reset", err.Error())
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: aggregatedStoryOne, Views: 2}))
+	require.NoError(t, ca.Stop())
+
+	ms.AssertExpectations(t)
+}
+
+func TestCounterAggregatorDropsAStoryThatKeepsFailing(t *testing.T) {
+	ms := &MockStoriesStore{}
+	ms.On("IncrementCounters", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Times(2)
+	ms.On("IncrementCounters", mock.Anything, []model.CounterDelta{
+		{StoryID: aggregatedStoryTwo, Views: 1},
+	}).Return(nil).Once()
+
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportDroppedCounters", 1).Once()
+
+	ca := newCounterAggregator(ms, time.Hour, 0, 500, 2, time.Second, pr, zap.NewNop())
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: aggregatedStoryOne, Views: 1}))
+
+	require.Error(t, ca.flushPending(context.Background()))
+	assert.Equal(t, 1, len(ca.pending))
+
+	require.Error(t, ca.flushPending(context.Background()))
+	assert.Empty(t, ca.pending)
+	assert.Empty(t, ca.attempts)
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: aggregatedStoryTwo, Views: 1}))
+	require.NoError(t, ca.Stop())
+
+	ms.AssertExpectations(t)
+	pr.AssertExpectations(t)
+}
+
+func TestCounterAggregatorValidatesStoryID(t *testing.T) {
+	ca := newCounterAggregator(&MockStoriesStore{}, time.Hour, 0, 500, 5, time.Second, &reporters.MockPrometheus{}, zap.NewNop())
+
+	err := ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: "abc", Views: 1})
+	assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+}
+
+func TestCounterAggregatorWritesThroughWithoutInterval(t *testing.T) {
+	ms := &MockStoriesStore{}
+	ms.On("IncrementCounters", mock.Anything, []model.CounterDelta{{StoryID: aggregatedStoryOne, Views: 1}}).Return(nil).Once()
+
+	ca := newCounterAggregator(ms, 0, 0, 500, 5, time.Second, &reporters.MockPrometheus{}, zap.NewNop())
+	ca.Start()
+
+	require.NoError(t, ca.IncrementCounters(context.Background(), model.CounterDelta{StoryID: aggregatedStoryOne, Views: 1}))
+	require.NoError(t, ca.Stop())
+
+	ms.AssertExpectations(t)
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/nsnikhil/stories/pkg/cache"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DBCluster SPLITS TRAFFIC BETWEEN THE PRIMARY, WHICH TAKES EVERY WRITE, AND THE READ REPLICAS
+type DBCluster interface {
+	Writer() *sql.DB
+	Reader(ctx context.Context) *sql.DB
+
+	// MarkWrite PINS THE READS OF THE SESSION ON ctx TO THE PRIMARY FOR THE READ YOUR WRITES WINDOW SO A
+	// CLIENT NEVER READS A REPLICA THAT HAS NOT CAUGHT UP WITH WHAT IT JUST WROTE. A WRITE WITHOUT A
+	// SESSION, LIKE THE COUNTER FLUSHES AND THE OUTBOX RELAY, PINS NOTHING.
+	MarkWrite(ctx context.Context)
+
+	Close() error
+}
+
+// pinnedSessionsCapacity BOUNDS THE SESSIONS PINNED AT ONCE, PAST IT THE LEAST RECENT WRITER MAY READ A REPLICA EARLY
+const pinnedSessionsCapacity = 10000
+
+type sessionKey struct{}
+
+// WithSession TAGS THE CALLS OF ONE CLIENT SO ITS READS FOLLOW ITS OWN WRITES
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+func SessionFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(sessionKey{}).(string)
+	return id, ok && len(id) != 0
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+
+	atomic.StoreInt32(&r.healthy, v)
+}
+
+type replicatedDBCluster struct {
+	primary  *sql.DB
+	replicas []*replica
+
+	next uint64
+
+	// pins HOLDS THE SESSIONS THAT WROTE WITHIN THE READ YOUR WRITES WINDOW, IT IS NIL WHEN THE WINDOW IS OFF
+	pins                 cache.Cache
+	readYourWritesWindow time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (rc *replicatedDBCluster) Writer() *sql.DB {
+	return rc.primary
+}
+
+// ROUND ROBIN OVER THE HEALTHY REPLICAS, FALLS BACK TO THE PRIMARY WHEN NONE IS AVAILABLE
+func (rc *replicatedDBCluster) Reader(ctx context.Context) *sql.DB {
+	if rc.pinnedToPrimary(ctx) {
+		return rc.primary
+	}
+
+	n := len(rc.replicas)
+	start := atomic.AddUint64(&rc.next, 1)
+
+	for i := 0; i < n; i++ {
+		r := rc.replicas[(start+uint64(i))%uint64(n)]
+		if r.isHealthy() {
+			return r.db
+		}
+	}
+
+	return rc.primary
+}
+
+func (rc *replicatedDBCluster) MarkWrite(ctx context.Context) {
+	if rc.pins == nil {
+		return
+	}
+
+	if session, ok := SessionFrom(ctx); ok {
+		rc.pins.Set(session, struct{}{}, rc.readYourWritesWindow)
+	}
+}
+
+func (rc *replicatedDBCluster) pinnedToPrimary(ctx context.Context) bool {
+	if rc.pins == nil {
+		return false
+	}
+
+	session, ok := SessionFrom(ctx)
+	if !ok {
+		return false
+	}
+
+	_, pinned := rc.pins.Get(session)
+	return pinned
+}
+
+func (rc *replicatedDBCluster) healthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rc.stop:
+			return
+		case <-ticker.C:
+			rc.checkReplicas(interval)
+		}
+	}
+}
+
+func (rc *replicatedDBCluster) checkReplicas(timeout time.Duration) {
+	for _, r := range rc.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		r.setHealthy(r.db.PingContext(ctx) == nil)
+		cancel()
+	}
+}
+
+func (rc *replicatedDBCluster) Close() error {
+	rc.stopOnce.Do(func() { close(rc.stop) })
+
+	var err error
+	for _, r := range rc.replicas {
+		if cerr := r.db.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	if cerr := rc.primary.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// NewDBCluster PINGS EVERY REPLICA ONCE BEFORE RETURNING AND THEN ON EVERY healthCheckInterval,
+// A REPLICA THAT FAILS A PING IS TAKEN OUT OF ROTATION UNTIL IT ANSWERS AGAIN
+func NewDBCluster(primary *sql.DB, replicas []*sql.DB, healthCheckInterval, readYourWritesWindow time.Duration) DBCluster {
+	rc := &replicatedDBCluster{
+		primary:              primary,
+		readYourWritesWindow: readYourWritesWindow,
+		stop:                 make(chan struct{}),
+	}
+
+	for _, db := range replicas {
+		rc.replicas = append(rc.replicas, &replica{db: db})
+	}
+
+	if len(rc.replicas) == 0 {
+		return rc
+	}
+
+	if readYourWritesWindow > 0 {
+		rc.pins = cache.NewLRUCache(pinnedSessionsCapacity)
+	}
+
+	// WITHOUT AN INTERVAL EVERY REPLICA STAYS IN ROTATION
+	if healthCheckInterval <= 0 {
+		for _, r := range rc.replicas {
+			r.setHealthy(true)
+		}
+
+		return rc
+	}
+
+	rc.checkReplicas(healthCheckInterval)
+	go rc.healthCheck(healthCheckInterval)
+
+	return rc
+}
+
+func newSingleDBCluster(db *sql.DB) DBCluster {
+	return NewDBCluster(db, nil, 0, 0)
+}
@@ -0,0 +1,143 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDBClusterRoundRobinsAcrossReplicas(t *testing.T) {
+	primary, replicaOne, replicaTwo := getSQLiteDB(t), getSQLiteDB(t), getSQLiteDB(t)
+
+	cluster := store.NewDBCluster(primary, []*sql.DB{replicaOne, replicaTwo}, time.Minute, 0)
+	defer func() { _ = cluster.Close() }()
+
+	assert.Equal(t, primary, cluster.Writer())
+
+	seen := map[*sql.DB]int{}
+	for i := 0; i < 4; i++ {
+		seen[cluster.Reader(context.Background())]++
+	}
+
+	assert.Equal(t, map[*sql.DB]int{replicaOne: 2, replicaTwo: 2}, seen)
+}
+
+func TestDBClusterSkipsUnhealthyReplicas(t *testing.T) {
+	primary, replicaOne, replicaTwo := getSQLiteDB(t), getSQLiteDB(t), getSQLiteDB(t)
+
+	cluster := store.NewDBCluster(primary, []*sql.DB{replicaOne, replicaTwo}, 10*time.Millisecond, 0)
+	defer func() { _ = cluster.Close() }()
+
+	require.NoError(t, replicaOne.Close())
+
+	assert.Eventually(t, func() bool {
+		for i := 0; i < 4; i++ {
+			if cluster.Reader(context.Background()) != replicaTwo {
+				return false
+			}
+		}
+
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, replicaTwo.Close())
+
+	assert.Eventually(t, func() bool {
+		return cluster.Reader(context.Background()) == primary
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDBClusterReadYourWrites(t *testing.T) {
+	alice := store.WithSession(context.Background(), "apiKey:alice")
+	bob := store.WithSession(context.Background(), "apiKey:bob")
+
+	testCases := map[string]struct {
+		window         time.Duration
+		writer         context.Context
+		reader         context.Context
+		pinnedToWriter bool
+	}{
+		"test reads of the writing session go to the primary within the window": {
+			window:         time.Minute,
+			writer:         alice,
+			reader:         alice,
+			pinnedToWriter: true,
+		},
+		"test reads of another session stay on the replicas": {
+			window:         time.Minute,
+			writer:         alice,
+			reader:         bob,
+			pinnedToWriter: false,
+		},
+		"test reads without a session stay on the replicas": {
+			window:         time.Minute,
+			writer:         alice,
+			reader:         context.Background(),
+			pinnedToWriter: false,
+		},
+		"test writes without a session pin nothing": {
+			window:         time.Minute,
+			writer:         context.Background(),
+			reader:         alice,
+			pinnedToWriter: false,
+		},
+		"test reads stay on the replicas when the option is off": {
+			window:         0,
+			writer:         alice,
+			reader:         alice,
+			pinnedToWriter: false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			primary, replica := getSQLiteDB(t), getSQLiteDB(t)
+
+			cluster := store.NewDBCluster(primary, []*sql.DB{replica}, time.Minute, testCase.window)
+			defer func() { _ = cluster.Close() }()
+
+			assert.Equal(t, replica, cluster.Reader(testCase.reader))
+
+			cluster.MarkWrite(testCase.writer)
+
+			assert.Equal(t, testCase.pinnedToWriter, cluster.Reader(testCase.reader) == primary)
+		})
+	}
+}
+
+func TestDBClusterReadYourWritesExpires(t *testing.T) {
+	primary, replica := getSQLiteDB(t), getSQLiteDB(t)
+
+	cluster := store.NewDBCluster(primary, []*sql.DB{replica}, time.Minute, 50*time.Millisecond)
+	defer func() { _ = cluster.Close() }()
+
+	ctx := store.WithSession(context.Background(), "apiKey:alice")
+
+	cluster.MarkWrite(ctx)
+	assert.Equal(t, primary, cluster.Reader(ctx))
+
+	assert.Eventually(t, func() bool {
+		return cluster.Reader(ctx) == replica
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReplicatedStoriesStoreReadsFromReplica(t *testing.T) {
+	primary, replica := getSQLiteDB(t), getSQLiteDB(t)
+
+	cluster := store.NewDBCluster(primary, []*sql.DB{replica}, time.Minute, 0)
+
+	_, err := replica.Exec(`INSERT INTO stories (id, title, body, createdAt, updatedAt) VALUES ('ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a', 'replica', 'body', datetime('now'), datetime('now'))`)
+	require.NoError(t, err)
+
+	str, err := store.NewReplicatedStoriesStore(cluster, config.DatabaseConfig{})
+	require.NoError(t, err)
+
+	res, err := str.GetMostViewsStories(context.Background(), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "replica", res[0].GetTitle())
+}
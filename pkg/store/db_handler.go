@@ -9,6 +9,7 @@ import (
 
 type DBHandler interface {
 	GetDB() (*sql.DB, error)
+	GetReplicaDBs() ([]*sql.DB, error)
 }
 
 type sqlDBHandler struct {
@@ -16,14 +17,37 @@ type sqlDBHandler struct {
 }
 
 func (dbh *sqlDBHandler) GetDB() (*sql.DB, error) {
-	db, err := sql.Open(dbh.cfg.DriverName(), dbh.cfg.Source())
+	if dbh.cfg.DriverName() == SQLiteDriverName {
+		return dbh.open(sqliteSource(dbh.cfg.Name()))
+	}
+
+	return dbh.open(dbh.cfg.Source())
+}
+
+// UNLIKE THE PRIMARY A REPLICA THAT IS DOWN AT STARTUP IS NOT FATAL, THE CLUSTER HEALTH CHECK TAKES IT OUT OF ROTATION
+func (dbh *sqlDBHandler) GetReplicaDBs() ([]*sql.DB, error) {
+	var dbs []*sql.DB
+
+	for _, source := range dbh.cfg.ReplicaSources() {
+		db, err := sql.Open(dbh.cfg.DriverName(), source)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("DBHandler.GetReplicaDBs.sql.Open"), liberr.SeverityError, err)
+		}
+
+		dbh.configure(db)
+		dbs = append(dbs, db)
+	}
+
+	return dbs, nil
+}
+
+func (dbh *sqlDBHandler) open(source string) (*sql.DB, error) {
+	db, err := sql.Open(dbh.cfg.DriverName(), source)
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("DBHandler.GetDB.sql.Open"), liberr.SeverityError, err)
 	}
 
-	db.SetMaxOpenConns(dbh.cfg.MaxOpenConnections())
-	db.SetMaxIdleConns(dbh.cfg.IdleConnections())
-	db.SetConnMaxLifetime(time.Minute * time.Duration(dbh.cfg.ConnectionMaxLifetime()))
+	dbh.configure(db)
 
 	if err := db.Ping(); err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("DBHandler.GetDB.db.Ping"), liberr.SeverityError, err)
@@ -32,6 +56,17 @@ func (dbh *sqlDBHandler) GetDB() (*sql.DB, error) {
 	return db, nil
 }
 
+func (dbh *sqlDBHandler) configure(db *sql.DB) {
+	db.SetMaxOpenConns(dbh.cfg.MaxOpenConnections())
+	db.SetMaxIdleConns(dbh.cfg.IdleConnections())
+
+	// SQLITE ALLOWS A SINGLE WRITER, SERIALIZING ON ONE CONNECTION AVOIDS "DATABASE IS LOCKED" ERRORS
+	if dbh.cfg.DriverName() == SQLiteDriverName {
+		db.SetMaxOpenConns(1)
+	}
+	db.SetConnMaxLifetime(time.Minute * time.Duration(dbh.cfg.ConnectionMaxLifetime()))
+}
+
 func NewDBHandler(cfg config.DatabaseConfig) DBHandler {
 	return &sqlDBHandler{
 		cfg: cfg,
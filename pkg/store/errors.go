@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nsnikhil/stories/pkg/liberr"
+)
+
+// POSTGRES ERROR CODES, SEE https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	connectionException  = "08"
+	dataException        = "22"
+	insufficientResource = "53"
+
+	stringDataRightTruncation = "22001"
+	notNullViolation          = "23502"
+	foreignKeyViolation       = "23503"
+	uniqueViolation           = "23505"
+	checkViolation            = "23514"
+	serializationFailure      = "40001"
+	deadlockDetected          = "40P01"
+	tooManyConnections        = "53300"
+	adminShutdown             = "57P01"
+	crashShutdown             = "57P02"
+	cannotConnectNow          = "57P03"
+)
+
+// translateError WRAPS A DRIVER ERROR WITH THE KIND THE TRANSPORTS NEED TO PICK A STATUS,
+// ERRORS THAT DO NOT MATCH ANY KNOWN CASE ARE INTERNAL ERRORS
+func translateError(op string, err error) error {
+	args := []interface{}{liberr.Operation(op), errorKind(err), liberr.SeverityError, err}
+	if code := errorCode(err); len(code) != 0 {
+		args = append(args, code)
+	}
+
+	return liberr.WithArgs(args...)
+}
+
+func errorKind(err error) liberr.Kind {
+	if errors.Is(err, sql.ErrNoRows) {
+		return liberr.ResourceNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErrorKind(pqErr)
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErrorKind(sqliteErr)
+	}
+
+	return liberr.InternalError
+}
+
+func pqErrorKind(err *pq.Error) liberr.Kind {
+	switch err.Code {
+	case uniqueViolation, serializationFailure, deadlockDetected:
+		return liberr.Conflict
+	case checkViolation, notNullViolation, foreignKeyViolation, stringDataRightTruncation:
+		return liberr.ConstraintViolation
+	case adminShutdown, crashShutdown, cannotConnectNow:
+		return liberr.Unavailable
+	}
+
+	switch err.Code.Class() {
+	case connectionException, insufficientResource:
+		return liberr.Unavailable
+	case dataException:
+		return liberr.ValidationError
+	default:
+		return liberr.InternalError
+	}
+}
+
+// errorCode NARROWS THE CODE OF THE KIND DOWN WHERE THE DRIVER TELLS US MORE, AN EMPTY CODE KEEPS THE CODE OF THE KIND
+func errorCode(err error) liberr.Code {
+	if errors.Is(err, sql.ErrNoRows) {
+		return liberr.CodeStoryNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case uniqueViolation:
+			return liberr.CodeStoryAlreadyExists
+		case serializationFailure, deadlockDetected:
+			return liberr.CodeVersionConflict
+		}
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey) {
+		return liberr.CodeStoryAlreadyExists
+	}
+
+	return ""
+}
+
+func sqliteErrorKind(err sqlite3.Error) liberr.Kind {
+	switch err.Code {
+	case sqlite3.ErrConstraint:
+		if err.ExtendedCode == sqlite3.ErrConstraintUnique || err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return liberr.Conflict
+		}
+
+		return liberr.ConstraintViolation
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return liberr.Unavailable
+	default:
+		return liberr.InternalError
+	}
+}
+
+func preconditionFailed(op string) error {
+	return liberr.WithArgs(liberr.Operation(op), liberr.PreconditionFailed, liberr.SeverityError, errors.New("story has changed since it was read"))
+}
+
+// invalidStoryID KEEPS A MALFORMED ID AWAY FROM THE uuid COLUMNS OF POSTGRES, WHICH WOULD FAIL THE QUERY ON IT
+func invalidStoryID(op, storyID string) error {
+	return liberr.WithArgs(liberr.Operation(op), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", storyID))
+}
+
+func notFound(op, msg string) error {
+	return liberr.WithArgs(liberr.Operation(op), liberr.ResourceNotFound, liberr.CodeStoryNotFound, liberr.SeverityError, errors.New(msg))
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTranslateError(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		expectedKind liberr.Kind
+		expectedCode liberr.Code
+	}{
+		"test no rows is not found": {
+			err:          sql.ErrNoRows,
+			expectedKind: liberr.ResourceNotFound,
+			expectedCode: liberr.CodeStoryNotFound,
+		},
+		"test unique violation is conflict": {
+			err:          &pq.Error{Code: "23505"},
+			expectedKind: liberr.Conflict,
+			expectedCode: liberr.CodeStoryAlreadyExists,
+		},
+		"test serialization failure is conflict": {
+			err:          &pq.Error{Code: "40001"},
+			expectedKind: liberr.Conflict,
+			expectedCode: liberr.CodeVersionConflict,
+		},
+		"test check violation is constraint violation": {
+			err:          &pq.Error{Code: "23514"},
+			expectedKind: liberr.ConstraintViolation,
+			expectedCode: liberr.CodeConstraintViolation,
+		},
+		"test value too long is constraint violation": {
+			err:          &pq.Error{Code: "22001"},
+			expectedKind: liberr.ConstraintViolation,
+			expectedCode: liberr.CodeConstraintViolation,
+		},
+		"test invalid text representation is validation error": {
+			err:          &pq.Error{Code: "22P02"},
+			expectedKind: liberr.ValidationError,
+			expectedCode: liberr.CodeInvalidRequest,
+		},
+		"test connection failure is unavailable": {
+			err:          &pq.Error{Code: "08006"},
+			expectedKind: liberr.Unavailable,
+			expectedCode: liberr.CodeUnavailable,
+		},
+		"test too many connections is unavailable": {
+			err:          &pq.Error{Code: "53300"},
+			expectedKind: liberr.Unavailable,
+			expectedCode: liberr.CodeUnavailable,
+		},
+		"test shutdown is unavailable": {
+			err:          &pq.Error{Code: "57P01"},
+			expectedKind: liberr.Unavailable,
+			expectedCode: liberr.CodeUnavailable,
+		},
+		"test syntax error is internal": {
+			err:          &pq.Error{Code: "42601"},
+			expectedKind: liberr.InternalError,
+			expectedCode: liberr.CodeInternal,
+		},
+		"test sqlite unique constraint is conflict": {
+			err:          sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique},
+			expectedKind: liberr.Conflict,
+			expectedCode: liberr.CodeStoryAlreadyExists,
+		},
+		"test sqlite check constraint is constraint violation": {
+			err:          sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintCheck},
+			expectedKind: liberr.ConstraintViolation,
+			expectedCode: liberr.CodeConstraintViolation,
+		},
+		"test sqlite busy is unavailable": {
+			err:          sqlite3.Error{Code: sqlite3.ErrBusy},
+			expectedKind: liberr.Unavailable,
+			expectedCode: liberr.CodeUnavailable,
+		},
+		"test unknown error is internal": {
+			err:          errors.New("some error"),
+			expectedKind: liberr.InternalError,
+			expectedCode: liberr.CodeInternal,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := translateError("op", testCase.err)
+
+			assert.Equal(t, testCase.expectedKind, err.(*liberr.Error).Kind())
+			assert.Equal(t, testCase.expectedCode, err.(*liberr.Error).Code())
+			assert.True(t, errors.Is(err, testCase.err))
+		})
+	}
+}
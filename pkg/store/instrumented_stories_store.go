@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"go.uber.org/zap"
+	"time"
+)
+
+// instrumentedStoriesStore REPORTS THE LATENCY AND THE ERRORS OF EVERY CALL BY OPERATION AND LOGS THE CALLS
+// SLOWER THAN THE THRESHOLD. A TRANSACTION IS REPORTED AS A WHOLE AND SO ARE THE CALLS MADE INSIDE IT.
+type instrumentedStoriesStore struct {
+	store StoriesStore
+	pr    reporters.Prometheus
+	lgr   *zap.Logger
+
+	slowQueryThreshold time.Duration
+}
+
+func (is *instrumentedStoriesStore) AddStory(ctx context.Context, story *model.Story) (id string, err error) {
+	defer is.observe("AddStory", time.Now(), &err)
+	return is.store.AddStory(ctx, story)
+}
+
+func (is *instrumentedStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) (ids []string, err error) {
+	defer is.observe("AddStories", time.Now(), &err)
+	return is.store.AddStories(ctx, stories...)
+}
+
+func (is *instrumentedStoriesStore) GetStories(ctx context.Context, storyIDs ...string) (res []model.Story, err error) {
+	defer is.observe("GetStories", time.Now(), &err)
+	return is.store.GetStories(ctx, storyIDs...)
+}
+
+func (is *instrumentedStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (c int64, err error) {
+	defer is.observe("UpdateStory", time.Now(), &err)
+	return is.store.UpdateStory(ctx, story, ifUpdatedAt)
+}
+
+func (is *instrumentedStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (c int64, err error) {
+	defer is.observe("PatchStory", time.Now(), &err)
+	return is.store.PatchStory(ctx, patch, ifUpdatedAt)
+}
+
+func (is *instrumentedStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (c int64, err error) {
+	defer is.observe("DeleteStory", time.Now(), &err)
+	return is.store.DeleteStory(ctx, storyID, ifUpdatedAt)
+}
+
+func (is *instrumentedStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) (res []model.Story, err error) {
+	defer is.observe("GetMostViewsStories", time.Now(), &err)
+	return is.store.GetMostViewsStories(ctx, offset, limit)
+}
+
+func (is *instrumentedStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) (res []model.Story, err error) {
+	defer is.observe("GetTopRatedStories", time.Now(), &err)
+	return is.store.GetTopRatedStories(ctx, offset, limit)
+}
+
+func (is *instrumentedStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) (err error) {
+	defer is.observe("IncrementCounters", time.Now(), &err)
+	return is.store.IncrementCounters(ctx, deltas...)
+}
+
+func (is *instrumentedStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) (res []model.DailyStats, err error) {
+	defer is.observe("GetDailyStats", time.Now(), &err)
+	return is.store.GetDailyStats(ctx, storyID, from, to)
+}
+
+func (is *instrumentedStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) (res []model.Mover, err error) {
+	defer is.observe("GetTopMovers", time.Now(), &err)
+	return is.store.GetTopMovers(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+}
+
+func (is *instrumentedStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) (res []model.Event, err error) {
+	defer is.observe("ClaimEvents", time.Now(), &err)
+	return is.store.ClaimEvents(ctx, limit, lease)
+}
+
+func (is *instrumentedStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) (err error) {
+	defer is.observe("MarkEventsPublished", time.Now(), &err)
+	return is.store.MarkEventsPublished(ctx, eventIDs...)
+}
+
+func (is *instrumentedStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) (err error) {
+	defer is.observe("WithTx", time.Now(), &err)
+
+	return is.store.WithTx(ctx, func(tx StoriesStore) error {
+		return fn(&instrumentedStoriesStore{store: tx, pr: is.pr, lgr: is.lgr, slowQueryThreshold: is.slowQueryThreshold})
+	})
+}
+
+func (is *instrumentedStoriesStore) observe(operation string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+
+	is.pr.ObserveStoreOperation(operation, elapsed)
+
+	if *err != nil {
+		is.pr.ReportStoreError(operation, string(reportedKind(*err)))
+	}
+
+	if is.slowQueryThreshold > 0 && elapsed >= is.slowQueryThreshold {
+		is.lgr.Warn("slow store operation", zap.String("operation", operation), zap.Duration("duration", elapsed), zap.Error(*err))
+	}
+}
+
+// AN ERROR WITHOUT A KIND IS REPORTED AS AN INTERNAL ERROR, THE TRANSPORTS ANSWER IT THE SAME WAY
+func reportedKind(err error) liberr.Kind {
+	var libErr *liberr.Error
+	if errors.As(err, &libErr) && libErr.Kind() != "" {
+		return libErr.Kind()
+	}
+
+	return liberr.InternalError
+}
+
+func NewInstrumentedStoriesStore(str StoriesStore, cfg config.DatabaseConfig, pr reporters.Prometheus, lgr *zap.Logger) StoriesStore {
+	return &instrumentedStoriesStore{
+		store:              str,
+		pr:                 pr,
+		lgr:                lgr,
+		slowQueryThreshold: cfg.SlowQueryThreshold(),
+	}
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+const instrumentedStoryID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+
+func TestInstrumentedStoriesStoreReportsErrorsByKind(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		expectedKind string
+	}{
+		"test no error is reported on success": {
+			err: nil,
+		},
+		"test error is reported with its kind": {
+			err:          liberr.WithArgs(liberr.ResourceNotFound, errors.New("no records found")),
+			expectedKind: "resourceNotFound",
+		},
+		"test error without a kind is reported as internal": {
+			err:          errors.New("connection refused"),
+			expectedKind: "internalError",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mst := &MockStoriesStore{}
+			mst.On("GetStories", mock.Anything, []string{instrumentedStoryID}).Return([]model.Story{}, testCase.err)
+
+			pr := &reporters.MockPrometheus{}
+			pr.On("ObserveStoreOperation", "GetStories", mock.AnythingOfType("time.Duration"))
+			pr.On("ReportStoreError", "GetStories", testCase.expectedKind)
+
+			is := &instrumentedStoriesStore{store: mst, pr: pr, lgr: zap.NewNop()}
+
+			_, err := is.GetStories(context.Background(), instrumentedStoryID)
+			assert.Equal(t, testCase.err, err)
+
+			pr.AssertCalled(t, "ObserveStoreOperation", "GetStories", mock.AnythingOfType("time.Duration"))
+
+			if testCase.err == nil {
+				pr.AssertNotCalled(t, "ReportStoreError", mock.Anything, mock.Anything)
+				return
+			}
+
+			pr.AssertCalled(t, "ReportStoreError", "GetStories", testCase.expectedKind)
+		})
+	}
+}
+
+func TestInstrumentedStoriesStoreLogsSlowOperations(t *testing.T) {
+	testCases := map[string]struct {
+		threshold    time.Duration
+		expectedLogs int
+	}{
+		"test slow operation is logged":           {threshold: time.Millisecond, expectedLogs: 1},
+		"test operation under the threshold":      {threshold: time.Minute, expectedLogs: 0},
+		"test slow operation log is switched off": {threshold: 0, expectedLogs: 0},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mst := &MockStoriesStore{}
+			mst.On("DeleteStory", mock.Anything, instrumentedStoryID, time.Time{}).Return(int64(1), nil).After(5 * time.Millisecond)
+
+			pr := &reporters.MockPrometheus{}
+			pr.On("ObserveStoreOperation", "DeleteStory", mock.AnythingOfType("time.Duration"))
+
+			core, logs := observer.New(zapcore.WarnLevel)
+			is := &instrumentedStoriesStore{store: mst, pr: pr, lgr: zap.New(core), slowQueryThreshold: testCase.threshold}
+
+			_, err := is.DeleteStory(context.Background(), instrumentedStoryID, time.Time{})
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.expectedLogs, logs.Len())
+
+			if testCase.expectedLogs > 0 {
+				assert.Equal(t, "DeleteStory", logs.All()[0].ContextMap()["operation"])
+			}
+		})
+	}
+}
+
+func TestInstrumentedStoriesStoreReportsCallsInsideATransaction(t *testing.T) {
+	pr := &reporters.MockPrometheus{}
+	pr.On("ObserveStoreOperation", mock.Anything, mock.AnythingOfType("time.Duration"))
+
+	is := &instrumentedStoriesStore{store: NewInMemoryStoriesStore(), pr: pr, lgr: zap.NewNop()}
+
+	err := is.WithTx(context.Background(), func(tx StoriesStore) error {
+		st, err := model.NewStoryBuilder().SetTitle(100, "title").SetBody(100, "body").Build()
+		require.NoError(t, err)
+
+		_, err = tx.AddStory(context.Background(), st)
+		return err
+	})
+
+	require.NoError(t, err)
+
+	pr.AssertCalled(t, "ObserveStoreOperation", "AddStory", mock.AnythingOfType("time.Duration"))
+	pr.AssertCalled(t, "ObserveStoreOperation", "WithTx", mock.AnythingOfType("time.Duration"))
+}
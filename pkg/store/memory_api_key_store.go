@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/auth"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const apiKeyNameMaxLength = 100
+
+// inMemoryAPIKeyStore IS THE API KEY COUNTERPART OF inMemoryStoriesStore, THE KEYS ARE GONE ONCE THE PROCESS EXITS
+type inMemoryAPIKeyStore struct {
+	mu sync.RWMutex
+
+	// ids KEEPS THE ORDER THE KEYS WERE CREATED IN
+	ids    []string
+	keys   map[string]auth.APIKey
+	hashes map[string]string
+
+	now func() time.Time
+}
+
+func (ims *inMemoryAPIKeyStore) AddAPIKey(ctx context.Context, name, hash string) (auth.APIKey, error) {
+	if err := checkContext(ctx, "APIKeyStore.AddAPIKey"); err != nil {
+		return auth.APIKey{}, err
+	}
+
+	if err := checkAPIKeyName(name); err != nil {
+		return auth.APIKey{}, liberr.WithArgs(liberr.Operation("APIKeyStore.AddAPIKey.checkAPIKeyName"), liberr.ConstraintViolation, liberr.SeverityError, err)
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return auth.APIKey{}, liberr.WithArgs(liberr.Operation("APIKeyStore.AddAPIKey.newUUID"), liberr.InternalError, liberr.SeverityError, err)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	if _, ok := ims.hashes[hash]; ok {
+		return auth.APIKey{}, liberr.WithArgs(liberr.Operation("APIKeyStore.AddAPIKey"), liberr.Conflict, liberr.SeverityError, errors.New("api key already exists"))
+	}
+
+	ak := auth.APIKey{ID: id, Name: name, Hash: hash, CreatedAt: ims.now().UTC().Truncate(time.Microsecond)}
+
+	ims.ids = append(ims.ids, id)
+	ims.keys[id] = ak
+	ims.hashes[hash] = id
+
+	return ak, nil
+}
+
+func (ims *inMemoryAPIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (auth.APIKey, error) {
+	if err := checkContext(ctx, "APIKeyStore.GetAPIKeyByHash"); err != nil {
+		return auth.APIKey{}, err
+	}
+
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	id, ok := ims.hashes[hash]
+	if !ok {
+		return auth.APIKey{}, apiKeyNotFound("APIKeyStore.GetAPIKeyByHash", "api key not found")
+	}
+
+	return ims.keys[id], nil
+}
+
+func (ims *inMemoryAPIKeyStore) ListAPIKeys(ctx context.Context) ([]auth.APIKey, error) {
+	if err := checkContext(ctx, "APIKeyStore.ListAPIKeys"); err != nil {
+		return nil, err
+	}
+
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	var keys []auth.APIKey
+	for _, id := range ims.ids {
+		keys = append(keys, ims.keys[id])
+	}
+
+	return keys, nil
+}
+
+func (ims *inMemoryAPIKeyStore) RevokeAPIKey(ctx context.Context, id string) (int64, error) {
+	if err := checkContext(ctx, "APIKeyStore.RevokeAPIKey"); err != nil {
+		return 0, err
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	ak, ok := ims.keys[id]
+	if !ok || ak.Revoked() {
+		return 0, apiKeyNotFound("APIKeyStore.RevokeAPIKey", "no active api key with id "+id)
+	}
+
+	revokedAt := ims.now().UTC().Truncate(time.Microsecond)
+	ak.RevokedAt = &revokedAt
+	ims.keys[id] = ak
+
+	return 1, nil
+}
+
+// SAME CHECKS AS THE CONSTRAINTS ON THE API KEYS TABLE
+func checkAPIKeyName(name string) error {
+	switch n := utf8.RuneCountInString(name); {
+	case n == 0:
+		return errors.New("name cannot be empty")
+	case n > apiKeyNameMaxLength:
+		return fmt.Errorf("name cannot be longer than %d characters", apiKeyNameMaxLength)
+	default:
+		return nil
+	}
+}
+
+func NewInMemoryAPIKeyStore() APIKeyStore {
+	return &inMemoryAPIKeyStore{
+		keys:   make(map[string]auth.APIKey),
+		hashes: make(map[string]string),
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,655 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MemoryDriverName = "memory"
+
+	titleMaxLength = 100
+	bodyMaxLength  = 100000
+
+	dayLayout = "2006-01-02"
+)
+
+// inMemoryStoriesStore MIRRORS THE SEMANTICS OF THE SQL STORE (ORDERING, PAGINATION,
+// VALIDATION AND ERRORS) WITHOUT NEEDING A DATABASE, IT IS MEANT FOR DEMOS AND TESTS.
+type inMemoryStoriesStore struct {
+	mu sync.RWMutex
+
+	// ids KEEPS THE INSERTION ORDER, IT IS USED TO BREAK TIES WHILE SORTING
+	ids     []string
+	stories map[string]model.Story
+	stats   map[string]map[string]model.DailyStats
+
+	// events ONLY HOLDS THE EVENTS NOT PUBLISHED YET, claims THE LEASE OF THOSE THAT WERE CLAIMED
+	events      []model.Event
+	claims      map[int64]time.Time
+	lastEventID int64
+
+	now func() time.Time
+
+	// inTx IS ONLY SET ON THE COPY HANDED TO THE WithTx CALLBACK
+	inTx bool
+}
+
+func (ims *inMemoryStoriesStore) AddStory(ctx context.Context, st *model.Story) (string, error) {
+	if err := checkContext(ctx, "StoriesStore.AddStory"); err != nil {
+		return "", err
+	}
+
+	if err := checkConstraints(st); err != nil {
+		return "", liberr.WithArgs(liberr.Operation("StoriesStore.AddStory.checkConstraints"), liberr.ConstraintViolation, liberr.SeverityError, err)
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return "", liberr.WithArgs(liberr.Operation("StoriesStore.AddStory.newUUID"), liberr.InternalError, liberr.SeverityError, err)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	now := ims.now().UTC()
+
+	ims.ids = append(ims.ids, id)
+	ims.stories[id] = model.Story{
+		ID:        id,
+		Title:     st.GetTitle(),
+		Body:      st.GetBody(),
+		ViewCount: st.GetViewCount(),
+		UpVotes:   st.GetUpVotes(),
+		DownVotes: st.GetDownVotes(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	ims.recordDailyStats(id, st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes())
+	ims.recordEvents(model.NewStoryCreatedEvent(ims.stories[id]))
+
+	return id, nil
+}
+
+func (ims *inMemoryStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) ([]string, error) {
+	if err := checkContext(ctx, "StoriesStore.AddStories"); err != nil {
+		return nil, err
+	}
+
+	for _, st := range stories {
+		if err := checkConstraints(st); err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.AddStories.checkConstraints"), liberr.ConstraintViolation, liberr.SeverityError, err)
+		}
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	prepared, err := prepareStories(ims.now(), stories...)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+	}
+
+	seen := make(map[string]bool, len(prepared))
+	for _, st := range prepared {
+		if _, ok := ims.stories[st.ID]; ok || seen[st.ID] {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), liberr.Conflict, liberr.CodeStoryAlreadyExists, liberr.SeverityError, fmt.Errorf("story %s already exists", st.ID))
+		}
+
+		seen[st.ID] = true
+	}
+
+	for _, st := range prepared {
+		ims.ids = append(ims.ids, st.ID)
+		ims.stories[st.ID] = st
+
+		ims.addDailyStats(st.ID, model.ToDay(st.CreatedAt), st.ViewCount, st.UpVotes, st.DownVotes)
+	}
+
+	return storyIDsOf(prepared...), nil
+}
+
+func (ims *inMemoryStoriesStore) GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error) {
+	if err := checkContext(ctx, "StoriesStore.GetStories"); err != nil {
+		return nil, err
+	}
+
+	for _, id := range storyIDs {
+		if !isValidUUID(id) {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetStories.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", id))
+		}
+	}
+
+	requested := make(map[string]bool, len(storyIDs))
+	for _, id := range storyIDs {
+		requested[id] = true
+	}
+
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	var stories []model.Story
+	for _, id := range ims.ids {
+		if requested[id] {
+			stories = append(stories, ims.stories[id])
+		}
+	}
+
+	if len(stories) == 0 {
+		return nil, notFound("StoriesStore.GetStories", "no records found")
+	}
+
+	return stories, nil
+}
+
+func (ims *inMemoryStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	if err := checkContext(ctx, "StoriesStore.UpdateStory"); err != nil {
+		return 0, err
+	}
+
+	if !isValidUUID(story.GetID()) {
+		return 0, invalidStoryID("StoriesStore.UpdateStory.isValidUUID", story.GetID())
+	}
+
+	if err := checkConstraints(story); err != nil {
+		return 0, liberr.WithArgs(liberr.Operation("StoriesStore.UpdateStory.checkConstraints"), liberr.ConstraintViolation, liberr.SeverityError, err)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	old, ok := ims.stories[story.GetID()]
+	if !ok {
+		return 0, notFound("StoriesStore.UpdateStory", "failed to update story")
+	}
+
+	if !isVersion(old, ifUpdatedAt) {
+		return 0, preconditionFailed("StoriesStore.UpdateStory")
+	}
+
+	ims.stories[old.GetID()] = model.Story{
+		ID:        old.GetID(),
+		Title:     story.GetTitle(),
+		Body:      story.GetBody(),
+		ViewCount: story.GetViewCount(),
+		UpVotes:   story.GetUpVotes(),
+		DownVotes: story.GetDownVotes(),
+		CreatedAt: old.GetCreatedAt(),
+		UpdatedAt: ims.now().UTC(),
+	}
+
+	ims.recordDailyStats(old.GetID(),
+		story.GetViewCount()-old.GetViewCount(),
+		story.GetUpVotes()-old.GetUpVotes(),
+		story.GetDownVotes()-old.GetDownVotes(),
+	)
+
+	ims.recordEvents(model.NewStoryUpdatedEvent(ims.stories[old.GetID()]))
+
+	return 1, nil
+}
+
+func (ims *inMemoryStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	if err := checkContext(ctx, "StoriesStore.PatchStory"); err != nil {
+		return 0, err
+	}
+
+	if !isValidUUID(patch.ID) {
+		return 0, invalidStoryID("StoriesStore.PatchStory.isValidUUID", patch.ID)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	old, ok := ims.stories[patch.ID]
+	if !ok {
+		return 0, notFound("StoriesStore.PatchStory", "failed to update story")
+	}
+
+	if !isVersion(old, ifUpdatedAt) {
+		return 0, preconditionFailed("StoriesStore.PatchStory")
+	}
+
+	st := patch.Apply(old)
+	if err := checkConstraints(&st); err != nil {
+		return 0, liberr.WithArgs(liberr.Operation("StoriesStore.PatchStory.checkConstraints"), liberr.ConstraintViolation, liberr.SeverityError, err)
+	}
+
+	st.UpdatedAt = ims.now().UTC()
+	ims.stories[st.GetID()] = st
+
+	ims.recordEvents(model.NewStoryUpdatedEvent(st))
+
+	return 1, nil
+}
+
+func (ims *inMemoryStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	if err := checkContext(ctx, "StoriesStore.DeleteStory"); err != nil {
+		return 0, err
+	}
+
+	if !isValidUUID(storyID) {
+		return 0, invalidStoryID("StoriesStore.DeleteStory.isValidUUID", storyID)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	old, ok := ims.stories[storyID]
+	if !ok {
+		return 0, notFound("StoriesStore.DeleteStory", "failed to delete story")
+	}
+
+	if !isVersion(old, ifUpdatedAt) {
+		return 0, preconditionFailed("StoriesStore.DeleteStory")
+	}
+
+	delete(ims.stories, storyID)
+	delete(ims.stats, storyID)
+
+	for i, id := range ims.ids {
+		if id == storyID {
+			ims.ids = append(ims.ids[:i], ims.ids[i+1:]...)
+			break
+		}
+	}
+
+	ims.recordEvents(model.NewStoryDeletedEvent(storyID))
+
+	return 1, nil
+}
+
+func (ims *inMemoryStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return ims.sortedPage(ctx, "StoriesStore.GetMostViewsStories", offset, limit, func(st model.Story) int64 {
+		return st.GetViewCount()
+	})
+}
+
+func (ims *inMemoryStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return ims.sortedPage(ctx, "StoriesStore.GetTopRatedStories", offset, limit, func(st model.Story) int64 {
+		return st.GetUpVotes()
+	})
+}
+
+func (ims *inMemoryStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	if err := checkContext(ctx, "StoriesStore.IncrementCounters"); err != nil {
+		return err
+	}
+
+	deltas = model.MergeDeltas(deltas...)
+
+	for _, d := range deltas {
+		if !isValidUUID(d.StoryID) {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", d.StoryID))
+		}
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	for _, d := range deltas {
+		st, ok := ims.stories[d.StoryID]
+		if !ok {
+			continue
+		}
+
+		st.ViewCount += d.Views
+		st.UpVotes += d.UpVotes
+		st.DownVotes += d.DownVotes
+
+		ims.stories[d.StoryID] = st
+		ims.recordDailyStats(d.StoryID, d.Views, d.UpVotes, d.DownVotes)
+		ims.recordEvents(model.NewStoryVotedEvents(d)...)
+	}
+
+	return nil
+}
+
+func (ims *inMemoryStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	if err := checkContext(ctx, "StoriesStore.GetDailyStats"); err != nil {
+		return nil, err
+	}
+
+	if !isValidUUID(storyID) {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", storyID))
+	}
+
+	from, to = model.ToDay(from), model.ToDay(to)
+
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	var stats []model.DailyStats
+	for _, ds := range ims.stats[storyID] {
+		if !ds.GetDay().Before(from) && !ds.GetDay().After(to) {
+			stats = append(stats, ds)
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].GetDay().Before(stats[j].GetDay())
+	})
+
+	return stats, nil
+}
+
+func (ims *inMemoryStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	if err := checkContext(ctx, "StoriesStore.GetTopMovers"); err != nil {
+		return nil, err
+	}
+
+	value, ok := metricValues[metric]
+	if !ok {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid metric %s", metric))
+	}
+
+	if limit < 0 {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers"), liberr.SeverityError, errors.New("LIMIT must not be negative"))
+	}
+
+	within := func(day, from, to time.Time) bool {
+		return !day.Before(model.ToDay(from)) && !day.After(model.ToDay(to))
+	}
+
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	var movers []model.Mover
+	for id, days := range ims.stats {
+		m := model.Mover{StoryID: id}
+		active := false
+
+		for _, ds := range days {
+			if within(ds.GetDay(), previousFrom, previousTo) {
+				m.Previous += value(ds)
+				active = true
+			}
+
+			if within(ds.GetDay(), currentFrom, currentTo) {
+				m.Current += value(ds)
+				active = true
+			}
+		}
+
+		if active {
+			movers = append(movers, m)
+		}
+	}
+
+	sort.Slice(movers, func(i, j int) bool {
+		if movers[i].GetDelta() != movers[j].GetDelta() {
+			return movers[i].GetDelta() > movers[j].GetDelta()
+		}
+
+		return movers[i].GetStoryID() < movers[j].GetStoryID()
+	})
+
+	if len(movers) > limit {
+		movers = movers[:limit]
+	}
+
+	return movers, nil
+}
+
+func (ims *inMemoryStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	if err := checkContext(ctx, "StoriesStore.ClaimEvents"); err != nil {
+		return nil, err
+	}
+
+	if limit < 0 {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.ClaimEvents"), liberr.SeverityError, errors.New("LIMIT must not be negative"))
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	now := ims.now()
+
+	leased := make(map[string]bool)
+	for _, e := range ims.events {
+		if until, ok := ims.claims[e.GetID()]; ok && until.After(now) {
+			leased[e.GetStoryID()] = true
+		}
+	}
+
+	var claimed []model.Event
+	for _, e := range ims.events {
+		if len(claimed) == limit {
+			break
+		}
+
+		if !leased[e.GetStoryID()] {
+			claimed = append(claimed, e)
+		}
+	}
+
+	if ims.claims == nil {
+		ims.claims = make(map[int64]time.Time)
+	}
+
+	for _, e := range claimed {
+		ims.claims[e.GetID()] = now.Add(lease)
+	}
+
+	return claimed, nil
+}
+
+func (ims *inMemoryStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) error {
+	if err := checkContext(ctx, "StoriesStore.MarkEventsPublished"); err != nil {
+		return err
+	}
+
+	published := make(map[int64]bool, len(eventIDs))
+	for _, id := range eventIDs {
+		published[id] = true
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	pending := ims.events[:0]
+	for _, e := range ims.events {
+		if !published[e.GetID()] {
+			pending = append(pending, e)
+			continue
+		}
+
+		delete(ims.claims, e.GetID())
+	}
+
+	ims.events = pending
+	return nil
+}
+
+// WithTx HOLDS THE WRITE LOCK FOR THE WHOLE CALLBACK AND RUNS IT AGAINST A COPY OF THE DATA, THE COPY
+// REPLACES THE DATA ONLY WHEN fn SUCCEEDS. fn MUST ONLY USE THE STORE IT IS GIVEN, NOT THE OUTER ONE.
+func (ims *inMemoryStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) error {
+	if ims.inTx {
+		return fn(ims)
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	if err := checkContext(ctx, "StoriesStore.WithTx"); err != nil {
+		return err
+	}
+
+	tx := ims.copy()
+	tx.inTx = true
+
+	if err := fn(tx); err != nil {
+		return liberr.WithArgs(liberr.Operation("StoriesStore.WithTx"), err)
+	}
+
+	ims.ids, ims.stories, ims.stats = tx.ids, tx.stories, tx.stats
+	ims.events, ims.claims, ims.lastEventID = tx.events, tx.claims, tx.lastEventID
+	return nil
+}
+
+// MUST BE CALLED WITH THE LOCK HELD
+func (ims *inMemoryStoriesStore) copy() *inMemoryStoriesStore {
+	res := &inMemoryStoriesStore{
+		ids:     append([]string(nil), ims.ids...),
+		stories: make(map[string]model.Story, len(ims.stories)),
+		stats:   make(map[string]map[string]model.DailyStats, len(ims.stats)),
+		now:     ims.now,
+
+		events:      append([]model.Event(nil), ims.events...),
+		claims:      make(map[int64]time.Time, len(ims.claims)),
+		lastEventID: ims.lastEventID,
+	}
+
+	for id, until := range ims.claims {
+		res.claims[id] = until
+	}
+
+	for id, st := range ims.stories {
+		res.stories[id] = st
+	}
+
+	for id, days := range ims.stats {
+		res.stats[id] = make(map[string]model.DailyStats, len(days))
+		for day, ds := range days {
+			res.stats[id][day] = ds
+		}
+	}
+
+	return res
+}
+
+var metricValues = map[model.Metric]func(ds model.DailyStats) int64{
+	model.MetricViews:     func(ds model.DailyStats) int64 { return ds.GetViews() },
+	model.MetricUpVotes:   func(ds model.DailyStats) int64 { return ds.GetUpVotes() },
+	model.MetricDownVotes: func(ds model.DailyStats) int64 { return ds.GetDownVotes() },
+}
+
+func (ims *inMemoryStoriesStore) sortedPage(ctx context.Context, op string, offset, limit int, key func(st model.Story) int64) ([]model.Story, error) {
+	if err := checkContext(ctx, op); err != nil {
+		return nil, err
+	}
+
+	if offset < 0 {
+		return nil, liberr.WithArgs(liberr.Operation(op), liberr.SeverityError, errors.New("OFFSET must not be negative"))
+	}
+
+	if limit < 0 {
+		return nil, liberr.WithArgs(liberr.Operation(op), liberr.SeverityError, errors.New("LIMIT must not be negative"))
+	}
+
+	ims.mu.RLock()
+
+	stories := make([]model.Story, len(ims.ids))
+	for i, id := range ims.ids {
+		stories[i] = ims.stories[id]
+	}
+
+	ims.mu.RUnlock()
+
+	sort.SliceStable(stories, func(i, j int) bool {
+		return key(stories[i]) > key(stories[j])
+	})
+
+	if offset > len(stories) {
+		offset = len(stories)
+	}
+
+	end := offset + limit
+	if end > len(stories) {
+		end = len(stories)
+	}
+
+	page := stories[offset:end]
+	if len(page) == 0 {
+		return nil, notFound(op, "no records found")
+	}
+
+	return page, nil
+}
+
+// MUST BE CALLED WITH THE WRITE LOCK HELD
+func (ims *inMemoryStoriesStore) recordEvents(events ...model.Event) {
+	for _, e := range events {
+		ims.lastEventID++
+
+		e.ID = ims.lastEventID
+		e.CreatedAt = ims.now().UTC()
+		ims.events = append(ims.events, e)
+	}
+}
+
+// MUST BE CALLED WITH THE WRITE LOCK HELD
+func (ims *inMemoryStoriesStore) recordDailyStats(storyID string, views, upVotes, downVotes int64) {
+	ims.addDailyStats(storyID, model.ToDay(ims.now()), views, upVotes, downVotes)
+}
+
+// MUST BE CALLED WITH THE WRITE LOCK HELD
+func (ims *inMemoryStoriesStore) addDailyStats(storyID string, day time.Time, views, upVotes, downVotes int64) {
+	if views == 0 && upVotes == 0 && downVotes == 0 {
+		return
+	}
+
+	days, ok := ims.stats[storyID]
+	if !ok {
+		days = make(map[string]model.DailyStats)
+		ims.stats[storyID] = days
+	}
+
+	ds, ok := days[day.Format(dayLayout)]
+	if !ok {
+		ds = model.DailyStats{StoryID: storyID, Day: day}
+	}
+
+	ds.Views += views
+	ds.UpVotes += upVotes
+	ds.DownVotes += downVotes
+
+	days[day.Format(dayLayout)] = ds
+}
+
+// NOTHING HERE BLOCKS, IT IS ENOUGH TO GIVE UP ON A CONTEXT THAT IS ALREADY DONE
+func checkContext(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return liberr.WithArgs(liberr.Operation(op), liberr.SeverityError, err)
+	}
+
+	return nil
+}
+
+// SAME CHECKS AS THE CONSTRAINTS ON THE STORIES TABLE
+func isVersion(st model.Story, ifUpdatedAt time.Time) bool {
+	return ifUpdatedAt.IsZero() || st.GetUpdatedAt().Equal(ifUpdatedAt)
+}
+
+func checkConstraints(st *model.Story) error {
+	title, body := utf8.RuneCountInString(st.GetTitle()), utf8.RuneCountInString(st.GetBody())
+
+	switch {
+	case title == 0:
+		return errors.New("title cannot be empty")
+	case title > titleMaxLength:
+		return fmt.Errorf("title cannot be longer than %d characters", titleMaxLength)
+	case body == 0:
+		return errors.New("body cannot be empty")
+	case body > bodyMaxLength:
+		return fmt.Errorf("body cannot be longer than %d characters", bodyMaxLength)
+	default:
+		return nil
+	}
+}
+
+func NewInMemoryStoriesStore() StoriesStore {
+	return &inMemoryStoriesStore{
+		stories: make(map[string]model.Story),
+		stats:   make(map[string]map[string]model.DailyStats),
+		now:     time.Now,
+	}
+}
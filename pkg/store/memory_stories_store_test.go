@@ -0,0 +1,293 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newMemoryStory(t *testing.T, title string, views, upVotes int64) *model.Story {
+	st, err := model.NewStoryBuilder().
+		SetTitle(100, title).
+		SetBody(100, fmt.Sprintf("this is story %s", title)).
+		SetViewCount(views).
+		SetUpVotes(upVotes).
+		Build()
+
+	require.NoError(t, err)
+	return st
+}
+
+func TestInMemoryStoriesStoreAddAndGetStories(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	idOne, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+	require.NoError(t, err)
+	assert.True(t, isValidUUID(idOne))
+
+	idTwo, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+	require.NoError(t, err)
+	assert.True(t, isValidUUID(idTwo))
+
+	res, err := str.GetStories(context.Background(), idTwo, idOne)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(res))
+	assert.Equal(t, "one", res[0].GetTitle())
+	assert.Equal(t, "two", res[1].GetTitle())
+	assert.False(t, res[0].GetCreatedAt().IsZero())
+	assert.Equal(t, res[0].GetCreatedAt(), res[0].GetUpdatedAt())
+}
+
+func TestInMemoryStoriesStoreAddStoryFailure(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	testCases := map[string]struct {
+		story         func() *model.Story
+		expectedError string
+	}{
+		"test insert story fails due to empty title": {
+			story: func() *model.Story {
+				st := newMemoryStory(t, "one", 0, 0)
+				st.Title = ""
+				return st
+			},
+			expectedError: "title cannot be empty",
+		},
+		"test insert story fails due to empty body": {
+			story: func() *model.Story {
+				st := newMemoryStory(t, "one", 0, 0)
+				st.Body = ""
+				return st
+			},
+			expectedError: "body cannot be empty",
+		},
+		"test insert story fails due to long title": {
+			story: func() *model.Story {
+				st := newMemoryStory(t, "one", 0, 0)
+				st.Title = strings.Repeat("a", 101)
+				return st
+			},
+			expectedError: "title cannot be longer than 100 characters",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			id, err := str.AddStory(context.Background(), testCase.story())
+
+			require.Error(t, err)
+			assert.Equal(t, testCase.expectedError, err.Error())
+			assert.Equal(t, liberr.ConstraintViolation, err.(*liberr.Error).Kind())
+			assert.Equal(t, "", id)
+		})
+	}
+}
+
+func TestInMemoryStoriesStoreGetStoriesFailure(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	_, err := str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "adbca278-7e5c-4831-bf90-15fadfda0dd1")
+	assert.Equal(t, errors.New("no records found").Error(), err.Error())
+
+	_, err = str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "abc")
+	assert.Equal(t, errors.New("invalid uuid abc").Error(), err.Error())
+	assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+}
+
+func TestInMemoryStoriesStoreUpdateStory(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+	require.NoError(t, err)
+
+	res, err := str.GetStories(context.Background(), id)
+	require.NoError(t, err)
+
+	st := &res[0]
+	st.Title = "updated"
+	st.AddView()
+	st.UpVote()
+
+	c, err := str.UpdateStory(context.Background(), st, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	res, err = str.GetStories(context.Background(), id)
+	require.NoError(t, err)
+
+	assert.Equal(t, "updated", res[0].GetTitle())
+	assert.Equal(t, int64(1), res[0].GetViewCount())
+	assert.Equal(t, int64(1), res[0].GetUpVotes())
+	assert.Equal(t, st.GetCreatedAt(), res[0].GetCreatedAt())
+
+	st.ID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+
+	c, err = str.UpdateStory(context.Background(), st, time.Time{})
+	assert.Equal(t, "failed to update story", err.Error())
+	assert.Equal(t, int64(0), c)
+}
+
+func TestInMemoryStoriesStoreDeleteStory(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	id, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 0, 0))
+	require.NoError(t, err)
+
+	c, err := str.DeleteStory(context.Background(), id, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	_, err = str.GetStories(context.Background(), id)
+	assert.Equal(t, "no records found", err.Error())
+
+	c, err = str.DeleteStory(context.Background(), id, time.Time{})
+	assert.Equal(t, "failed to delete story", err.Error())
+	assert.Equal(t, int64(0), c)
+}
+
+func TestInMemoryStoriesStoreSortedPages(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	for _, st := range []*model.Story{
+		newMemoryStory(t, "one", 0, 5),
+		newMemoryStory(t, "two", 10, 0),
+		newMemoryStory(t, "three", 12, 12),
+		newMemoryStory(t, "four", 0, 10),
+	} {
+		_, err := str.AddStory(context.Background(), st)
+		require.NoError(t, err)
+	}
+
+	titles := func(stories []model.Story) []string {
+		var res []string
+		for _, st := range stories {
+			res = append(res, st.GetTitle())
+		}
+		return res
+	}
+
+	testCases := map[string]struct {
+		actualResult   func() ([]model.Story, error)
+		expectedResult []string
+		expectedError  error
+	}{
+		"test most viewed first page": {
+			actualResult:   func() ([]model.Story, error) { return str.GetMostViewsStories(context.Background(), 0, 2) },
+			expectedResult: []string{"three", "two"},
+		},
+		"test most viewed second page keeps insertion order for ties": {
+			actualResult:   func() ([]model.Story, error) { return str.GetMostViewsStories(context.Background(), 2, 2) },
+			expectedResult: []string{"one", "four"},
+		},
+		"test top rated": {
+			actualResult:   func() ([]model.Story, error) { return str.GetTopRatedStories(context.Background(), 0, 10) },
+			expectedResult: []string{"three", "four", "one", "two"},
+		},
+		"test page past the end returns no records": {
+			actualResult:  func() ([]model.Story, error) { return str.GetTopRatedStories(context.Background(), 4, 2) },
+			expectedError: errors.New("no records found"),
+		},
+		"test negative limit returns error": {
+			actualResult:  func() ([]model.Story, error) { return str.GetTopRatedStories(context.Background(), 0, -1) },
+			expectedError: errors.New("LIMIT must not be negative"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			res, err := testCase.actualResult()
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, testCase.expectedResult, titles(res))
+		})
+	}
+}
+
+func TestInMemoryStoriesStoreAnalytics(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	today := model.ToDay(time.Now())
+
+	idOne, err := str.AddStory(context.Background(), newMemoryStory(t, "one", 5, 0))
+	require.NoError(t, err)
+
+	idTwo, err := str.AddStory(context.Background(), newMemoryStory(t, "two", 0, 0))
+	require.NoError(t, err)
+
+	res, err := str.GetStories(context.Background(), idTwo)
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		res[0].AddView()
+	}
+	res[0].DownVote()
+
+	_, err = str.UpdateStory(context.Background(), &res[0], time.Time{})
+	require.NoError(t, err)
+
+	stats, err := str.GetDailyStats(context.Background(), idTwo, today, today)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DailyStats{{StoryID: idTwo, Day: today, Views: 8, DownVotes: 1}}, stats)
+
+	yesterday := today.AddDate(0, 0, -1)
+
+	movers, err := str.GetTopMovers(context.Background(), model.MetricViews, yesterday, yesterday, today, today, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Mover{
+		{StoryID: idTwo, Previous: 0, Current: 8},
+		{StoryID: idOne, Previous: 0, Current: 5},
+	}, movers)
+
+	_, err = str.DeleteStory(context.Background(), idTwo, time.Time{})
+	require.NoError(t, err)
+
+	stats, err = str.GetDailyStats(context.Background(), idTwo, today, today)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	_, err = str.GetTopMovers(context.Background(), model.Metric("likes"), yesterday, yesterday, today, today, 10)
+	assert.Equal(t, "invalid metric likes", err.Error())
+}
+
+func TestInMemoryStoriesStoreConcurrentAccess(t *testing.T) {
+	str := store.NewInMemoryStoriesStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			id, err := str.AddStory(context.Background(), newMemoryStory(t, fmt.Sprintf("story %d", i), int64(i), 0))
+			assert.NoError(t, err)
+
+			_, err = str.GetStories(context.Background(), id)
+			assert.NoError(t, err)
+
+			_, err = str.GetMostViewsStories(context.Background(), 0, 10)
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	wg.Wait()
+
+	res, err := str.GetMostViewsStories(context.Background(), 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 50, len(res))
+	assert.Equal(t, int64(49), res[0].GetViewCount())
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
+)
+
+const (
+	// ANY CONSTANT WORKS AS LONG AS NOTHING ELSE TAKES AN ADVISORY LOCK WITH IT, POSTGRES SCOPES IT TO THE DATABASE
+	migrationLockKey = 7046191232715034
+
+	migrationLockPollInterval = 200 * time.Millisecond
+)
+
+// migrationLock IS HELD FOR THE WHOLE MIGRATE COMMAND SO TWO DEPLOYS CANNOT BOTH READ THE SAME VERSION AND
+// APPLY THE SAME MIGRATION, THE LOCK GOLANG-MIGRATE TAKES ITSELF DOES NOT SPAN PROCESSES ON SQLITE.
+// BOTH IMPLEMENTATIONS ARE RELEASED BY THE DATABASE WHEN THE PROCESS DIES SO A CRASH CANNOT LEAVE IT BEHIND.
+type migrationLock interface {
+	Lock(ctx context.Context) error
+	Unlock() error
+}
+
+type advisoryLock struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func (al *advisoryLock) Lock(ctx context.Context) error {
+	conn, err := al.db.Conn(ctx)
+	if err != nil {
+		return translateError("MigrationLock.Lock.db.Conn", err)
+	}
+
+	err = acquire(ctx, func() (bool, error) {
+		var ok bool
+		err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, migrationLockKey).Scan(&ok)
+		return ok, err
+	})
+
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	al.conn = conn
+	return nil
+}
+
+func (al *advisoryLock) Unlock() error {
+	if al.conn == nil {
+		return nil
+	}
+
+	defer func() { al.conn = nil }()
+
+	_, err := al.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	_ = al.conn.Close()
+
+	if err != nil {
+		return translateError("MigrationLock.Unlock", err)
+	}
+
+	return nil
+}
+
+// fileLock KEEPS AN EXCLUSIVE TRANSACTION OPEN ON A SEPARATE SQLITE FILE NEXT TO THE DATABASE, LOCKING
+// THE DATABASE ITSELF WOULD ALSO BLOCK THE MIGRATIONS RUN UNDER THE LOCK
+type fileLock struct {
+	path string
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func (fl *fileLock) Lock(ctx context.Context) error {
+	db, err := sql.Open(SQLiteDriverName, fmt.Sprintf("file:%s?_busy_timeout=0", fl.path))
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("MigrationLock.Lock.sql.Open"), liberr.SeverityError, err)
+	}
+
+	var conn *sql.Conn
+
+	// OPENING THE CONNECTION ALREADY FAILS WITH BUSY WHILE ANOTHER PROCESS HOLDS THE LOCK
+	err = acquire(ctx, func() (bool, error) {
+		if conn == nil {
+			c, err := db.Conn(ctx)
+			if err != nil {
+				return false, ignoreBusy(err)
+			}
+
+			conn = c
+		}
+
+		_, err := conn.ExecContext(ctx, `BEGIN EXCLUSIVE`)
+		return err == nil, ignoreBusy(err)
+	})
+
+	if err != nil {
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		_ = db.Close()
+		return err
+	}
+
+	fl.db, fl.conn = db, conn
+	return nil
+}
+
+func (fl *fileLock) Unlock() error {
+	if fl.conn == nil {
+		return nil
+	}
+
+	defer func() { fl.db, fl.conn = nil, nil }()
+
+	_, err := fl.conn.ExecContext(context.Background(), `ROLLBACK`)
+	_ = fl.conn.Close()
+	_ = fl.db.Close()
+
+	if err != nil {
+		return translateError("MigrationLock.Unlock", err)
+	}
+
+	return nil
+}
+
+// acquire POLLS tryLock UNTIL IT GETS THE LOCK OR THE CONTEXT IS DONE
+func acquire(ctx context.Context, tryLock func() (bool, error)) error {
+	ticker := time.NewTicker(migrationLockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := tryLock()
+		if err != nil && ctx.Err() == nil {
+			return translateError("MigrationLock.Lock", err)
+		}
+
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return lockHeld()
+		case <-ticker.C:
+		}
+	}
+}
+
+func ignoreBusy(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrBusy {
+		return nil
+	}
+
+	return err
+}
+
+func lockHeld() error {
+	return liberr.WithArgs(
+		liberr.Operation("MigrationLock.Lock"),
+		liberr.Conflict,
+		liberr.SeverityError,
+		errors.New("another migration is holding the lock"),
+	)
+}
+
+func newAdvisoryLock(db *sql.DB) migrationLock {
+	return &advisoryLock{db: db}
+}
+
+func newFileLock(path string) migrationLock {
+	return &fileLock{path: path}
+}
@@ -1,75 +1,310 @@
 package store
 
 import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"net/http"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 )
 
 const (
 	rollBackStep = -1
 	cutSet       = "file://"
 	databaseName = "postgres"
+
+	sqliteMigrationDir = "sqlite"
+	embeddedMigrations = "migrations"
+	embeddedSourceName = "embedded"
+	fileSourceName     = "file"
+	sqliteLockSuffix   = ".migrate.lock"
+
+	migrationVersionFormat = "20060102150405"
 )
 
-// TODO: SHOULD IT RETURN LIB ERROR
-func RunMigrations(configFile string) {
-	newMigrate, err := newMigrate(configFile)
+// THE BINARY CARRIES ITS MIGRATIONS SO IT CAN MIGRATE ANY ENVIRONMENT WITHOUT THE SOURCE TREE
+//
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+var invalidMigrationNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+	Dirty   bool
+}
+
+// Migrator HOLDS THE MIGRATION LOCK AROUND EVERY CALL THAT CHANGES THE SCHEMA, NO CHANGE IS NOT AN ERROR
+type Migrator interface {
+	Up() error
+
+	// Down ROLLS BACK THE LAST APPLIED MIGRATION ONLY
+	Down() error
+
+	// Goto MIGRATES UP OR DOWN TO THE GIVEN VERSION
+	Goto(version uint) error
+
+	// Force SETS THE VERSION WITHOUT RUNNING ANYTHING AND CLEARS THE DIRTY FLAG, IT IS HOW A FAILED
+	// MIGRATION IS RECOVERED FROM ONCE THE SCHEMA HAS BEEN FIXED BY HAND. -1 MEANS NO VERSION.
+	Force(version int) error
+
+	// applied IS false WHEN NO MIGRATION HAS BEEN APPLIED YET
+	Version() (version uint, applied bool, dirty bool, err error)
+
+	Status() ([]MigrationStatus, error)
+
+	Close() error
+}
+
+type sqlMigrator struct {
+	migrate     *migrate.Migrate
+	source      func() (source.Driver, error)
+	lock        migrationLock
+	lockTimeout time.Duration
+}
+
+func (sm *sqlMigrator) Up() error {
+	return sm.withLock("Migrator.Up", sm.migrate.Up)
+}
+
+func (sm *sqlMigrator) Down() error {
+	return sm.withLock("Migrator.Down", func() error { return sm.migrate.Steps(rollBackStep) })
+}
+
+func (sm *sqlMigrator) Goto(version uint) error {
+	return sm.withLock("Migrator.Goto", func() error {
+		err := sm.migrate.Migrate(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return liberr.WithArgs(liberr.ValidationError, fmt.Errorf("migration %d does not exist", version))
+		}
+
+		return err
+	})
+}
+
+func (sm *sqlMigrator) Force(version int) error {
+	return sm.withLock("Migrator.Force", func() error { return sm.migrate.Force(version) })
+}
+
+func (sm *sqlMigrator) Version() (uint, bool, bool, error) {
+	version, dirty, err := sm.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, false, nil
+	}
+
+	if err != nil {
+		return 0, false, false, liberr.WithArgs(liberr.Operation("Migrator.Version"), liberr.SeverityError, err)
+	}
+
+	return version, true, dirty, nil
+}
+
+// EVERY MIGRATION UP TO THE CURRENT VERSION IS APPLIED, GOLANG-MIGRATE ONLY EVER MOVES ALONG THE SORTED LIST
+func (sm *sqlMigrator) Status() ([]MigrationStatus, error) {
+	current, ok, dirty, err := sm.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := sm.source()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return nil, liberr.WithArgs(liberr.Operation("Migrator.Status.source.Open"), liberr.SeverityError, err)
 	}
 
-	if err := newMigrate.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			return
+	defer func() { _ = src.Close() }()
+
+	var res []MigrationStatus
+
+	version, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return nil, liberr.WithArgs(liberr.Operation("Migrator.Status.source.ReadUp"), liberr.SeverityError, readErr)
 		}
-		fmt.Println(err)
-		return
+
+		_ = r.Close()
+
+		res = append(res, MigrationStatus{
+			Version: version,
+			Name:    name,
+			Applied: ok && version <= current,
+			Dirty:   dirty && version == current,
+		})
+
+		version, err = src.Next(version)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, liberr.WithArgs(liberr.Operation("Migrator.Status.source.Next"), liberr.SeverityError, err)
+	}
+
+	return res, nil
+}
+
+func (sm *sqlMigrator) Close() error {
+	srcErr, dbErr := sm.migrate.Close()
+	if srcErr != nil {
+		return liberr.WithArgs(liberr.Operation("Migrator.Close"), liberr.SeverityError, srcErr)
 	}
+
+	if dbErr != nil {
+		return liberr.WithArgs(liberr.Operation("Migrator.Close"), liberr.SeverityError, dbErr)
+	}
+
+	return nil
+}
+
+func (sm *sqlMigrator) withLock(op string, fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sm.lockTimeout)
+	defer cancel()
+
+	if err := sm.lock.Lock(ctx); err != nil {
+		return liberr.WithArgs(liberr.Operation(op), err)
+	}
+
+	defer func() { _ = sm.lock.Unlock() }()
+
+	if err := fn(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return liberr.WithArgs(liberr.Operation(op), liberr.SeverityError, err)
+	}
+
+	return nil
 }
 
-// TODO: SHOULD IT RETURN LIB ERROR
-func RollBackMigrations(configFile string) {
-	newMigrate, err := newMigrate(configFile)
+func NewMigrator(cfg config.Config) (Migrator, error) {
+	dbCfg := cfg.DatabaseConfig()
+
+	if dbCfg.DriverName() == MemoryDriverName {
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator"), liberr.ValidationError, errors.New("memory driver does not support migrations"))
+	}
+
+	db, err := NewDBHandler(dbCfg).GetDB()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator"), err)
 	}
 
-	if err := newMigrate.Steps(rollBackStep); err != nil {
-		if err == migrate.ErrNoChange {
-			return
-		}
+	lock := newAdvisoryLock(db)
+	if dbCfg.DriverName() == SQLiteDriverName {
+		lock = newFileLock(dbCfg.Name() + sqliteLockSuffix)
 	}
+
+	return newMigrator(db, dbCfg.DriverName(), cfg.MigrationPath(), lock, cfg.MigrationLockTimeout())
 }
 
-func newMigrate(configFile string) (*migrate.Migrate, error) {
-	cfg := config.NewConfig(configFile)
+// AN EMPTY migrationPath USES THE MIGRATIONS EMBEDDED IN THE BINARY, A PATH OVERRIDES THEM WITH THE ONES ON DISK
+func newMigrator(db *sql.DB, driverName, migrationPath string, lock migrationLock, lockTimeout time.Duration) (*sqlMigrator, error) {
+	var driver database.Driver
+	var err error
+
+	name := databaseName
+
+	if driverName == SQLiteDriverName {
+		name = SQLiteDriverName
+		driver, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+	} else {
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	}
 
-	dbHandler := NewDBHandler(cfg.DatabaseConfig())
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator.WithInstance"), liberr.SeverityError, err)
+	}
 
-	db, err := dbHandler.GetDB()
+	sourceName, openSource, err := migrationSource(driverName, migrationPath)
 	if err != nil {
-		return nil, err
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator.migrationSource"), liberr.SeverityError, err)
 	}
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	src, err := openSource()
 	if err != nil {
-		return nil, err
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator.openSource"), liberr.SeverityError, err)
 	}
 
-	sourcePath, err := getSourcePath(cfg.MigrationPath())
+	m, err := migrate.NewWithInstance(sourceName, src, name, driver)
 	if err != nil {
-		return nil, err
+		return nil, liberr.WithArgs(liberr.Operation("NewMigrator.NewWithInstance"), liberr.SeverityError, err)
+	}
+
+	return &sqlMigrator{
+		migrate:     m,
+		source:      openSource,
+		lock:        lock,
+		lockTimeout: lockTimeout,
+	}, nil
+}
+
+// SQLITE HAS ITS OWN DIALECT, ITS MIGRATIONS LIVE IN A SUB DIRECTORY OF THE MIGRATION PATH
+func migrationSource(driverName, migrationPath string) (string, func() (source.Driver, error), error) {
+	if migrationPath == "" {
+		dir := embeddedMigrations
+		if driverName == SQLiteDriverName {
+			dir = embeddedMigrations + "/" + sqliteMigrationDir
+		}
+
+		return embeddedSourceName, func() (source.Driver, error) { return httpfs.New(http.FS(migrationFiles), dir) }, nil
+	}
+
+	if driverName == SQLiteDriverName {
+		migrationPath = filepath.Join(migrationPath, sqliteMigrationDir)
+	}
+
+	sourcePath, err := getSourcePath(migrationPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fileSourceName, func() (source.Driver, error) { return source.Open(sourcePath) }, nil
+}
+
+// CreateMigration ADDS EMPTY UP AND DOWN FILES FOR BOTH DIALECTS SO NEITHER OF THEM IS FORGOTTEN, IT RETURNS
+// THE PATHS OF THE CREATED FILES
+func CreateMigration(migrationPath, name string, now time.Time) ([]string, error) {
+	if migrationPath == "" {
+		return nil, liberr.WithArgs(liberr.Operation("CreateMigration"), liberr.ValidationError, errors.New("migrations are embedded, a migration path is required to create one"))
+	}
+
+	name = strings.Trim(invalidMigrationNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, liberr.WithArgs(liberr.Operation("CreateMigration"), liberr.ValidationError, errors.New("migration name is empty"))
+	}
+
+	base := fmt.Sprintf("%s_%s", now.UTC().Format(migrationVersionFormat), name)
+
+	var files []string
+
+	for _, dir := range []string{migrationPath, filepath.Join(migrationPath, sqliteMigrationDir)} {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, fmt.Sprintf("%s.%s.sql", base, direction))
+
+			f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+			if err != nil {
+				return files, liberr.WithArgs(liberr.Operation("CreateMigration.os.OpenFile"), liberr.SeverityError, err)
+			}
+
+			_ = f.Close()
+			files = append(files, file)
+		}
 	}
 
-	return migrate.NewWithDatabaseInstance(sourcePath, databaseName, driver)
+	return files, nil
 }
 
 func getSourcePath(directory string) (string, error) {
@@ -0,0 +1 @@
+drop table if exists story_daily_stats;
\ No newline at end of file
@@ -0,0 +1,10 @@
+create table if not exists outbox (
+    id bigserial primary key,
+    storyId uuid not null,
+    eventType varchar(64) not null,
+    payload jsonb not null,
+    createdAt timestamp without time zone not null default (now() at time zone 'utc'),
+    publishedAt timestamp without time zone
+);
+
+create index if not exists outbox_pending_idx on outbox (id) where publishedAt is null;
@@ -0,0 +1,8 @@
+create table if not exists api_keys (
+    id uuid primary key default gen_random_uuid(),
+    name varchar(100) not null,
+    keyHash char(64) not null unique,
+    createdAt timestamp without time zone not null default (now() at time zone 'utc'),
+    revokedAt timestamp without time zone,
+    CHECK (name <> '')
+);
@@ -0,0 +1,3 @@
+drop index if exists outbox_pending_story_idx;
+
+alter table outbox drop column if exists claimedUntil;
@@ -0,0 +1 @@
+drop table if exists stories;
\ No newline at end of file
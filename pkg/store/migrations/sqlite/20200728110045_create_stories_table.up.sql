@@ -0,0 +1,12 @@
+create table if not exists stories (
+    id text primary key,
+    title text not null,
+    body text not null,
+    viewCount integer not null default 0,
+    upVotes integer not null default 0,
+    downVotes integer not null default 0,
+    createdAt timestamp not null,
+    updatedAt timestamp not null,
+    CHECK (title <> '' AND length(title) <= 100),
+    CHECK (body <> '' AND length(body) <= 100000)
+);
@@ -0,0 +1,10 @@
+create table if not exists story_daily_stats (
+    storyId text not null references stories (id) on delete cascade,
+    day date not null,
+    views integer not null default 0,
+    upVotes integer not null default 0,
+    downVotes integer not null default 0,
+    primary key (storyId, day)
+);
+
+create index if not exists story_daily_stats_day_idx on story_daily_stats (day);
@@ -0,0 +1,10 @@
+create table if not exists outbox (
+    id integer primary key autoincrement,
+    storyId text not null,
+    eventType text not null,
+    payload text not null,
+    createdAt timestamp not null,
+    publishedAt timestamp
+);
+
+create index if not exists outbox_pending_idx on outbox (id) where publishedAt is null;
@@ -0,0 +1,8 @@
+create table if not exists api_keys (
+    id text primary key,
+    name text not null,
+    keyHash text not null unique,
+    createdAt timestamp not null,
+    revokedAt timestamp,
+    CHECK (name <> '' AND length(name) <= 100)
+);
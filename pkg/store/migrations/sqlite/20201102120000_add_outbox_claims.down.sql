@@ -0,0 +1,19 @@
+drop index if exists outbox_pending_story_idx;
+
+create table outbox_without_claims (
+    id integer primary key autoincrement,
+    storyId text not null,
+    eventType text not null,
+    payload text not null,
+    createdAt timestamp not null,
+    publishedAt timestamp
+);
+
+insert into outbox_without_claims (id, storyId, eventType, payload, createdAt, publishedAt)
+    select id, storyId, eventType, payload, createdAt, publishedAt from outbox;
+
+drop table outbox;
+
+alter table outbox_without_claims rename to outbox;
+
+create index if not exists outbox_pending_idx on outbox (id) where publishedAt is null;
@@ -0,0 +1,3 @@
+alter table outbox add column claimedUntil timestamp;
+
+create index if not exists outbox_pending_story_idx on outbox (storyId) where publishedAt is null;
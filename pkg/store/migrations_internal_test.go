@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	storiesMigration    = uint(20200728110045)
+	dailyStatsMigration = uint(20200905120000)
+	outboxMigration     = uint(20201010120000)
+	apiKeysMigration    = uint(20201101120000)
+
+	outboxClaimsMigration = uint(20201102120000)
+)
+
+func TestMigratorMovesBetweenVersions(t *testing.T) {
+	testCases := map[string]string{
+		"test embedded migrations": "",
+		"test migrations on disk":  "migrations",
+	}
+
+	for name, migrationPath := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testMigratorMovesBetweenVersions(t, newTestMigrator(t, migrationPath))
+		})
+	}
+}
+
+func testMigratorMovesBetweenVersions(t *testing.T, m *sqlMigrator) {
+
+	_, applied, _, err := m.Version()
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Up())
+	assertVersion(t, m, outboxClaimsMigration)
+
+	require.NoError(t, m.Down())
+	assertVersion(t, m, apiKeysMigration)
+
+	require.NoError(t, m.Goto(outboxClaimsMigration))
+	assertVersion(t, m, outboxClaimsMigration)
+
+	err = m.Goto(1)
+	require.Error(t, err)
+	assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+
+	require.NoError(t, m.Force(int(storiesMigration)))
+	assertVersion(t, m, storiesMigration)
+
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationStatus{
+		{Version: storiesMigration, Name: "create_stories_table", Applied: true},
+		{Version: dailyStatsMigration, Name: "create_story_daily_stats_table"},
+		{Version: outboxMigration, Name: "create_outbox_table"},
+		{Version: apiKeysMigration, Name: "create_api_keys_table"},
+		{Version: outboxClaimsMigration, Name: "add_outbox_claims"},
+	}, status)
+}
+
+func TestMigratorFailsWhenTheLockIsHeld(t *testing.T) {
+	m := newTestMigrator(t, "")
+
+	other := newFileLock(m.lock.(*fileLock).path)
+	require.NoError(t, other.Lock(context.Background()))
+
+	err := m.Up()
+	require.Error(t, err)
+	assert.Equal(t, liberr.Conflict, err.(*liberr.Error).Kind())
+
+	require.NoError(t, other.Unlock())
+	require.NoError(t, m.Up())
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, sqliteMigrationDir), 0755))
+
+	now := time.Date(2020, 9, 20, 10, 30, 0, 0, time.UTC)
+
+	files, err := CreateMigration(dir, " Add Story Tags! ", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "20200920103000_add_story_tags.up.sql"),
+		filepath.Join(dir, "20200920103000_add_story_tags.down.sql"),
+		filepath.Join(dir, sqliteMigrationDir, "20200920103000_add_story_tags.up.sql"),
+		filepath.Join(dir, sqliteMigrationDir, "20200920103000_add_story_tags.down.sql"),
+	}, files)
+
+	for _, file := range files {
+		_, err := os.Stat(file)
+		assert.NoError(t, err)
+	}
+
+	_, err = CreateMigration(dir, "add story tags", now)
+	assert.Error(t, err)
+
+	_, err = CreateMigration("", "add story tags", now)
+	assert.Error(t, err)
+
+	_, err = CreateMigration(dir, "!!", now)
+	require.Error(t, err)
+	assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+}
+
+func newTestMigrator(t *testing.T, migrationPath string) *sqlMigrator {
+	path := filepath.Join(t.TempDir(), "stories.db")
+
+	db, err := sql.Open(SQLiteDriverName, fmt.Sprintf("file:%s?_foreign_keys=on", path))
+	require.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	m, err := newMigrator(db, SQLiteDriverName, migrationPath, newFileLock(path+sqliteLockSuffix), 100*time.Millisecond)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = m.Close() })
+
+	return m
+}
+
+func assertVersion(t *testing.T, m Migrator, expected uint) {
+	version, applied, dirty, err := m.Version()
+	require.NoError(t, err)
+
+	assert.True(t, applied)
+	assert.False(t, dirty)
+	assert.Equal(t, expected, version)
+}
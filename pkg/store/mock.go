@@ -1,40 +1,87 @@
 package store
 
 import (
+	"context"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type MockStoriesStore struct {
 	mock.Mock
 }
 
-func (mock *MockStoriesStore) AddStory(story *model.Story) (string, error) {
-	args := mock.Called(story)
+func (mock *MockStoriesStore) AddStory(ctx context.Context, story *model.Story) (string, error) {
+	args := mock.Called(ctx, story)
 	return args.String(0), args.Error(1)
 }
 
-func (mock *MockStoriesStore) GetStories(storyIDs ...string) ([]model.Story, error) {
-	args := mock.Called(storyIDs)
+func (mock *MockStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) ([]string, error) {
+	args := mock.Called(ctx, stories)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (mock *MockStoriesStore) GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error) {
+	args := mock.Called(ctx, storyIDs)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
 
-func (mock *MockStoriesStore) UpdateStory(story *model.Story) (int64, error) {
-	args := mock.Called(story)
+func (mock *MockStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, story, ifUpdatedAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, patch, ifUpdatedAt)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (mock *MockStoriesStore) DeleteStory(storyID string) (int64, error) {
-	args := mock.Called(storyID)
+func (mock *MockStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, storyID, ifUpdatedAt)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (mock *MockStoriesStore) GetMostViewsStories(offset, limit int) ([]model.Story, error) {
-	args := mock.Called(offset, limit)
+func (mock *MockStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	args := mock.Called(ctx, offset, limit)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
 
-func (mock *MockStoriesStore) GetTopRatedStories(offset, limit int) ([]model.Story, error) {
-	args := mock.Called(offset, limit)
+func (mock *MockStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	args := mock.Called(ctx, offset, limit)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
+
+func (mock *MockStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	args := mock.Called(ctx, storyID, from, to)
+	return args.Get(0).([]model.DailyStats), args.Error(1)
+}
+
+func (mock *MockStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	args := mock.Called(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+	return args.Get(0).([]model.Mover), args.Error(1)
+}
+
+// WithTx RUNS fn AGAINST THE MOCK ITSELF UNLESS AN ERROR IS SET ON THE EXPECTATION
+func (mock *MockStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) error {
+	args := mock.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+
+	return fn(mock)
+}
+
+func (mock *MockStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	args := mock.Called(ctx, limit, lease)
+	return args.Get(0).([]model.Event), args.Error(1)
+}
+
+func (mock *MockStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) error {
+	args := mock.Called(ctx, eventIDs)
+	return args.Error(0)
+}
+
+func (mock *MockStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	args := mock.Called(ctx, deltas)
+	return args.Error(0)
+}
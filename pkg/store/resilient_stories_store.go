@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/resilience"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"io"
+	"net"
+	"time"
+)
+
+const databaseBreaker = "database"
+
+// resilientStoriesStore RETRIES READS THAT FAILED ON A TRANSIENT ERROR AND PUTS EVERY CALL BEHIND A
+// CIRCUIT BREAKER. WRITES ARE NOT RETRIED SINCE A WRITE THAT REACHED THE DATABASE MAY HAVE BEEN APPLIED,
+// database/sql ALREADY RETRIES THE ONES THAT FAILED BEFORE LEAVING THE CLIENT WITH driver.ErrBadConn
+type resilientStoriesStore struct {
+	store   StoriesStore
+	breaker resilience.CircuitBreaker
+
+	maxAttempts int
+	backoff     resilience.Backoff
+}
+
+func (rs *resilientStoriesStore) AddStory(ctx context.Context, story *model.Story) (string, error) {
+	var id string
+
+	err := rs.write("StoriesStore.AddStory", func() (err error) {
+		id, err = rs.store.AddStory(ctx, story)
+		return err
+	})
+
+	return id, err
+}
+
+func (rs *resilientStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) ([]string, error) {
+	var ids []string
+
+	err := rs.write("StoriesStore.AddStories", func() (err error) {
+		ids, err = rs.store.AddStories(ctx, stories...)
+		return err
+	})
+
+	return ids, err
+}
+
+func (rs *resilientStoriesStore) GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error) {
+	var res []model.Story
+
+	err := rs.read(ctx, "StoriesStore.GetStories", func() (err error) {
+		res, err = rs.store.GetStories(ctx, storyIDs...)
+		return err
+	})
+
+	return res, err
+}
+
+func (rs *resilientStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	var c int64
+
+	err := rs.write("StoriesStore.UpdateStory", func() (err error) {
+		c, err = rs.store.UpdateStory(ctx, story, ifUpdatedAt)
+		return err
+	})
+
+	return c, err
+}
+
+func (rs *resilientStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	var c int64
+
+	err := rs.write("StoriesStore.PatchStory", func() (err error) {
+		c, err = rs.store.PatchStory(ctx, patch, ifUpdatedAt)
+		return err
+	})
+
+	return c, err
+}
+
+func (rs *resilientStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	var c int64
+
+	err := rs.write("StoriesStore.DeleteStory", func() (err error) {
+		c, err = rs.store.DeleteStory(ctx, storyID, ifUpdatedAt)
+		return err
+	})
+
+	return c, err
+}
+
+func (rs *resilientStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	var res []model.Story
+
+	err := rs.read(ctx, "StoriesStore.GetMostViewsStories", func() (err error) {
+		res, err = rs.store.GetMostViewsStories(ctx, offset, limit)
+		return err
+	})
+
+	return res, err
+}
+
+func (rs *resilientStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	var res []model.Story
+
+	err := rs.read(ctx, "StoriesStore.GetTopRatedStories", func() (err error) {
+		res, err = rs.store.GetTopRatedStories(ctx, offset, limit)
+		return err
+	})
+
+	return res, err
+}
+
+// INCREMENTS ARE NOT IDEMPOTENT, THEY ARE TREATED LIKE ANY OTHER WRITE
+func (rs *resilientStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	return rs.write("StoriesStore.IncrementCounters", func() error {
+		return rs.store.IncrementCounters(ctx, deltas...)
+	})
+}
+
+func (rs *resilientStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	var res []model.DailyStats
+
+	err := rs.read(ctx, "StoriesStore.GetDailyStats", func() (err error) {
+		res, err = rs.store.GetDailyStats(ctx, storyID, from, to)
+		return err
+	})
+
+	return res, err
+}
+
+func (rs *resilientStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	var res []model.Mover
+
+	err := rs.read(ctx, "StoriesStore.GetTopMovers", func() (err error) {
+		res, err = rs.store.GetTopMovers(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+		return err
+	})
+
+	return res, err
+}
+
+func (rs *resilientStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	var res []model.Event
+
+	err := rs.write("StoriesStore.ClaimEvents", func() (err error) {
+		res, err = rs.store.ClaimEvents(ctx, limit, lease)
+		return err
+	})
+
+	return res, err
+}
+
+func (rs *resilientStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) error {
+	return rs.write("StoriesStore.MarkEventsPublished", func() error {
+		return rs.store.MarkEventsPublished(ctx, eventIDs...)
+	})
+}
+
+// THE TRANSACTION IS GUARDED AS A WHOLE, CALLS MADE ON THE STORE PASSED TO fn GO STRAIGHT TO THE TRANSACTION
+func (rs *resilientStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) error {
+	return rs.write("StoriesStore.WithTx", func() error {
+		return rs.store.WithTx(ctx, fn)
+	})
+}
+
+func (rs *resilientStoriesStore) read(ctx context.Context, op string, fn func() error) error {
+	err := resilience.Retry(ctx, rs.maxAttempts, rs.backoff, isTransient, func() error {
+		return rs.breaker.Execute(fn)
+	})
+
+	return unavailable(op, err)
+}
+
+func (rs *resilientStoriesStore) write(op string, fn func() error) error {
+	return unavailable(op, rs.breaker.Execute(fn))
+}
+
+func unavailable(op string, err error) error {
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		return err
+	}
+
+	return liberr.WithArgs(liberr.Operation(op), liberr.Unavailable, liberr.SeverityError, err)
+}
+
+// isTransient REPORTS ERRORS THAT ARE LIKELY TO GO AWAY ON THEIR OWN, LIKE A DROPPED CONNECTION OR
+// A FAILOVER IN PROGRESS, AS OPPOSED TO ERRORS CAUSED BY THE REQUEST ITSELF
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Code.Class() == connectionException {
+			return true
+		}
+
+		switch pqErr.Code {
+		case adminShutdown, crashShutdown, cannotConnectNow, tooManyConnections, serializationFailure, deadlockDetected:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// A DATABASE THAT DOES NOT ANSWER WITHIN THE DEADLINE IS AS UNHEALTHY AS ONE THAT DROPS THE CONNECTION
+func isBreakerFailure(err error) bool {
+	return isTransient(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func NewResilientStoriesStore(store StoriesStore, cfg config.ResilienceConfig, pr reporters.Prometheus) StoriesStore {
+	onStateChange := func(state resilience.State) {
+		pr.ReportCircuitBreakerState(databaseBreaker, int(state))
+	}
+
+	return &resilientStoriesStore{
+		store:       store,
+		breaker:     resilience.NewCircuitBreaker(cfg.BreakerFailureThreshold(), cfg.BreakerOpenTimeout(), isBreakerFailure, onStateChange),
+		maxAttempts: cfg.RetryMaxAttempts(),
+		backoff:     resilience.Backoff{Initial: cfg.RetryInitialBackoff(), Max: cfg.RetryMaxBackoff()},
+	}
+}
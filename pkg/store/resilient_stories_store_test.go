@@ -0,0 +1,108 @@
+package store_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/store"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const resilientStoryID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
+
+func newResilientStore(str store.StoriesStore) (store.StoriesStore, *reporters.MockPrometheus) {
+	cfg := config.NewConfig("../../local.env").ResilienceConfig()
+
+	pr := &reporters.MockPrometheus{}
+	pr.On("ReportCircuitBreakerState", "database", mock.Anything)
+
+	return store.NewResilientStoriesStore(str, cfg, pr), pr
+}
+
+func TestResilientStoriesStoreRetriesReads(t *testing.T) {
+	testCases := map[string]struct {
+		err           error
+		expectedCalls int
+	}{
+		"test retry on bad connection": {
+			err:           liberr.WithArgs(liberr.Operation("StoriesStore.GetStories"), driver.ErrBadConn),
+			expectedCalls: 2,
+		},
+		"test retry on connection exception": {
+			err:           &pq.Error{Code: "08006"},
+			expectedCalls: 2,
+		},
+		"test retry on admin shutdown": {
+			err:           &pq.Error{Code: "57P01"},
+			expectedCalls: 2,
+		},
+		"test do not retry constraint violation": {
+			err:           &pq.Error{Code: "23505"},
+			expectedCalls: 1,
+		},
+		"test do not retry validation error": {
+			err:           liberr.WithArgs(liberr.ValidationError, errors.New("invalid uuid abc")),
+			expectedCalls: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mst := &store.MockStoriesStore{}
+			mst.On("GetStories", mock.Anything, []string{resilientStoryID}).Return([]model.Story{}, testCase.err).Once()
+			mst.On("GetStories", mock.Anything, []string{resilientStoryID}).Return([]model.Story{{ID: resilientStoryID}}, nil).Once()
+
+			str, _ := newResilientStore(mst)
+
+			_, _ = str.GetStories(context.Background(), resilientStoryID)
+
+			mst.AssertNumberOfCalls(t, "GetStories", testCase.expectedCalls)
+		})
+	}
+}
+
+func TestResilientStoriesStoreDoesNotRetryWrites(t *testing.T) {
+	mst := &store.MockStoriesStore{}
+	mst.On("AddStory", mock.Anything, mock.Anything).Return("", driver.ErrBadConn)
+
+	str, _ := newResilientStore(mst)
+
+	_, err := str.AddStory(context.Background(), &model.Story{})
+	assert.Equal(t, driver.ErrBadConn, err)
+
+	mst.AssertNumberOfCalls(t, "AddStory", 1)
+}
+
+func TestResilientStoriesStoreOpensCircuit(t *testing.T) {
+	cfg := config.NewConfig("../../local.env").ResilienceConfig()
+
+	mst := &store.MockStoriesStore{}
+	mst.On("DeleteStory", mock.Anything, resilientStoryID, time.Time{}).Return(int64(0), &pq.Error{Code: "08006"})
+
+	str, pr := newResilientStore(mst)
+
+	for i := 0; i < cfg.BreakerFailureThreshold(); i++ {
+		_, err := str.DeleteStory(context.Background(), resilientStoryID, time.Time{})
+		require.Error(t, err)
+	}
+
+	_, err := str.GetMostViewsStories(context.Background(), 0, 10)
+	require.Error(t, err)
+	assert.Equal(t, liberr.Unavailable, err.(*liberr.Error).Kind())
+	assert.Equal(t, "circuit breaker is open", err.Error())
+
+	mst.AssertNumberOfCalls(t, "DeleteStory", cfg.BreakerFailureThreshold())
+	mst.AssertNotCalled(t, "GetMostViewsStories", mock.Anything, 0, 10)
+
+	pr.AssertCalled(t, "ReportCircuitBreakerState", "database", 0)
+	pr.AssertCalled(t, "ReportCircuitBreakerState", "database", 2)
+}
@@ -0,0 +1,553 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"strings"
+	"time"
+)
+
+const SQLiteDriverName = "sqlite3"
+
+// sqliteSource TURNS ON THE FOREIGN KEYS, SQLITE LEAVES THEM OFF BY DEFAULT, AND WAITS ON A LOCKED DATABASE
+func sqliteSource(name string) string {
+	return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", name)
+}
+
+const (
+	sqliteInsertStory   = `INSERT INTO stories (id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7)`
+	sqliteGetStories    = `SELECT id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt FROM stories WHERE id IN (%s) ORDER BY rowid`
+	sqliteGetCounters   = `SELECT viewCount, upVotes, downVotes FROM stories WHERE id=?1`
+	sqliteUpdateStory   = `UPDATE stories SET title=?1, body=?2, viewCount=?3, upVotes=?4, downVotes=?5, updatedAt=?6 WHERE id=?7 AND (?8 IS NULL OR updatedAt=?8)`
+	sqlitePatchStory    = `UPDATE stories SET title=COALESCE(?1, title), body=COALESCE(?2, body), updatedAt=?3 WHERE id=?4 AND (?5 IS NULL OR updatedAt=?5)`
+	sqliteGetStory      = `SELECT id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt FROM stories WHERE id=?1`
+	sqliteDeleteStory   = `DELETE FROM stories WHERE id=?1 AND (?2 IS NULL OR updatedAt=?2)`
+	sqliteStoryExists   = `SELECT EXISTS (SELECT 1 FROM stories WHERE id=?1)`
+	sqliteGetMostViewed = `SELECT id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt FROM stories ORDER BY viewCount DESC, rowid LIMIT ?1 OFFSET ?2`
+	sqliteGetTopRated   = `SELECT id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt FROM stories ORDER BY upVotes DESC, rowid LIMIT ?1 OFFSET ?2`
+
+	sqliteUpsertDailyStats = `INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes) VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (storyId, day) DO UPDATE SET views=views+excluded.views, upVotes=upVotes+excluded.upVotes, downVotes=downVotes+excluded.downVotes`
+	sqliteIncrementCounters = `WITH deltas (id, views, upVotes, downVotes) AS (VALUES %s)
+		UPDATE stories SET viewCount=stories.viewCount+deltas.views, upVotes=stories.upVotes+deltas.upVotes, downVotes=stories.downVotes+deltas.downVotes
+		FROM deltas WHERE stories.id=deltas.id`
+	sqliteIncrementDailyStats = `WITH deltas (id, views, upVotes, downVotes) AS (VALUES %s)
+		INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes)
+		SELECT id, ?%d, views, upVotes, downVotes FROM deltas WHERE id IN (SELECT id FROM stories)
+		ON CONFLICT (storyId, day) DO UPDATE SET views=story_daily_stats.views+excluded.views, upVotes=story_daily_stats.upVotes+excluded.upVotes, downVotes=story_daily_stats.downVotes+excluded.downVotes`
+	sqliteGetDailyStats = `SELECT storyId, day, views, upVotes, downVotes FROM story_daily_stats WHERE storyId=?1 AND day BETWEEN ?2 AND ?3 ORDER BY day`
+	sqliteGetTopMovers  = `SELECT storyId, previousCount, currentCount FROM (
+		SELECT storyId, SUM(CASE WHEN day BETWEEN ?1 AND ?2 THEN %[1]s ELSE 0 END) AS previousCount, SUM(CASE WHEN day BETWEEN ?3 AND ?4 THEN %[1]s ELSE 0 END) AS currentCount
+		FROM story_daily_stats WHERE day BETWEEN ?1 AND ?2 OR day BETWEEN ?3 AND ?4 GROUP BY storyId
+	) ORDER BY currentCount - previousCount DESC, storyId LIMIT ?5`
+
+	sqliteInsertEvents = `WITH events (storyId, eventType, payload) AS (VALUES %s)
+		INSERT INTO outbox (storyId, eventType, payload, createdAt) SELECT storyId, eventType, payload, ?%d FROM events`
+	sqliteInsertVotedEvents = `WITH events (storyId, eventType, payload) AS (VALUES %s)
+		INSERT INTO outbox (storyId, eventType, payload, createdAt) SELECT storyId, eventType, payload, ?%d FROM events WHERE storyId IN (SELECT id FROM stories)`
+	sqliteMarkEventsPublished = `UPDATE outbox SET publishedAt=? WHERE id IN (%s)`
+	sqliteClaimEvents         = `UPDATE outbox SET claimedUntil=?1 WHERE id IN (
+		SELECT o.id FROM outbox o WHERE o.publishedAt IS NULL AND NOT EXISTS (
+			SELECT 1 FROM outbox c WHERE c.storyId=o.storyId AND c.publishedAt IS NULL AND c.claimedUntil > ?2
+		) ORDER BY o.id LIMIT ?3
+	)`
+	sqliteGetClaimedEvents = `SELECT id, storyId, eventType, payload, createdAt FROM outbox WHERE publishedAt IS NULL AND claimedUntil=?1 ORDER BY id`
+)
+
+const (
+	sqliteInsertStories         = `INSERT INTO stories (id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt) VALUES %s`
+	sqliteInsertStoryDailyStats = `INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes) VALUES %s`
+)
+
+type sqliteStoriesStore struct {
+	db  *sql.DB
+	now func() time.Time
+
+	// tx IS ONLY SET ON THE STORE HANDED TO THE WithTx CALLBACK
+	tx *sql.Tx
+}
+
+func (sss *sqliteStoriesStore) AddStory(ctx context.Context, st *model.Story) (string, error) {
+	id, err := newUUID()
+	if err != nil {
+		return "", liberr.WithArgs(liberr.Operation("StoriesStore.AddStory.newUUID"), liberr.InternalError, liberr.SeverityError, err)
+	}
+
+	err = sss.inTx(ctx, "StoriesStore.AddStory", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, sqliteInsertStory, id, st.GetTitle(), st.GetBody(), st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes(), sss.now().UTC())
+		if err != nil {
+			return translateError("StoriesStore.AddStory.tx.Exec", err)
+		}
+
+		err = sss.recordDailyStats(ctx, tx, id, st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes())
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.AddStory"), err)
+		}
+
+		created := *st
+		created.ID = id
+
+		err = sss.recordEvents(ctx, tx, sqliteInsertEvents, model.NewStoryCreatedEvent(created))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.AddStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (sss *sqliteStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) ([]string, error) {
+	prepared, err := prepareStories(sss.now(), stories...)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+	}
+
+	if len(prepared) == 0 {
+		return nil, nil
+	}
+
+	err = sss.inTx(ctx, "StoriesStore.AddStories", func(tx *sql.Tx) error {
+		return inBatches(prepared, func(batch []model.Story) error {
+			values, args := buildStoryValues(func(n int) string {
+				return fmt.Sprintf("(?%d, ?%d, ?%d, ?%d, ?%d, ?%d, ?%d, ?%d)", n, n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			}, batch...)
+
+			if _, err := execQuery(ctx, tx, fmt.Sprintf(sqliteInsertStories, values), args...); err != nil {
+				return liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+			}
+
+			values, args = buildCreatedStatsValues(func(n int) string {
+				return fmt.Sprintf("(?%d, ?%d, ?%d, ?%d, ?%d)", n, n+1, n+2, n+3, n+4)
+			}, batch...)
+
+			if len(args) != 0 {
+				if _, err := execQuery(ctx, tx, fmt.Sprintf(sqliteInsertStoryDailyStats, values), args...); err != nil {
+					return liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+				}
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storyIDsOf(prepared...), nil
+}
+
+func (sss *sqliteStoriesStore) GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error) {
+	args := make([]interface{}, len(storyIDs))
+	placeholders := make([]string, len(storyIDs))
+
+	for i, id := range storyIDs {
+		if !isValidUUID(id) {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetStories.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", id))
+		}
+
+		args[i] = id
+		placeholders[i] = "?"
+	}
+
+	return getRecords(ctx, sss.querier(), fmt.Sprintf(sqliteGetStories, strings.Join(placeholders, ",")), args...)
+}
+
+func (sss *sqliteStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(story.GetID()) {
+		return 0, invalidStoryID("StoriesStore.UpdateStory.isValidUUID", story.GetID())
+	}
+
+	var c int64
+
+	err := sss.inTx(ctx, "StoriesStore.UpdateStory", func(tx *sql.Tx) error {
+		var views, upVotes, downVotes int64
+		err := tx.QueryRowContext(ctx, sqliteGetCounters, story.GetID()).Scan(&views, &upVotes, &downVotes)
+		if err == sql.ErrNoRows {
+			return notFound("StoriesStore.UpdateStory", "failed to update story")
+		}
+
+		if err != nil {
+			return translateError("StoriesStore.UpdateStory.tx.QueryRow", err)
+		}
+
+		c, err = execQuery(ctx, tx, sqliteUpdateStory,
+			story.GetTitle(), story.GetBody(),
+			story.GetViewCount(), story.GetUpVotes(),
+			story.GetDownVotes(), sss.now().UTC(), story.GetID(), versionArg(ifUpdatedAt))
+
+		if err != nil {
+			return err
+		}
+
+		// THE STORY WAS JUST READ IN THE SAME WRITE TRANSACTION SO IT CAN ONLY BE MISSED ON ITS VERSION
+		if c == 0 {
+			return preconditionFailed("StoriesStore.UpdateStory")
+		}
+
+		err = sss.recordDailyStats(ctx, tx, story.GetID(), story.GetViewCount()-views, story.GetUpVotes()-upVotes, story.GetDownVotes()-downVotes)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.UpdateStory"), err)
+		}
+
+		err = sss.recordEvents(ctx, tx, sqliteInsertEvents, model.NewStoryUpdatedEvent(*story))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.UpdateStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+// PatchStory READS THE STORY BACK IN THE SAME TRANSACTION FOR ITS EVENT, THE SQLITE BUNDLED WITH THE DRIVER HAS NO RETURNING
+func (sss *sqliteStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(patch.ID) {
+		return 0, invalidStoryID("StoriesStore.PatchStory.isValidUUID", patch.ID)
+	}
+
+	var c int64
+
+	err := sss.inTx(ctx, "StoriesStore.PatchStory", func(tx *sql.Tx) error {
+		var err error
+
+		c, err = execQuery(ctx, tx, sqlitePatchStory, patch.Title, patch.Body, sss.now().UTC(), patch.ID, versionArg(ifUpdatedAt))
+		if err != nil {
+			return err
+		}
+
+		if c == 0 {
+			return missedWrite(ctx, tx, sqliteStoryExists, "StoriesStore.PatchStory", "failed to update story", patch.ID, ifUpdatedAt)
+		}
+
+		stories, err := getRecords(ctx, tx, sqliteGetStory, patch.ID)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.PatchStory"), err)
+		}
+
+		err = sss.recordEvents(ctx, tx, sqliteInsertEvents, model.NewStoryUpdatedEvent(stories[0]))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.PatchStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+func (sss *sqliteStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(storyID) {
+		return 0, invalidStoryID("StoriesStore.DeleteStory.isValidUUID", storyID)
+	}
+
+	var c int64
+
+	err := sss.inTx(ctx, "StoriesStore.DeleteStory", func(tx *sql.Tx) error {
+		var err error
+
+		c, err = execQuery(ctx, tx, sqliteDeleteStory, storyID, versionArg(ifUpdatedAt))
+		if err != nil {
+			return err
+		}
+
+		if c == 0 {
+			return missedWrite(ctx, tx, sqliteStoryExists, "StoriesStore.DeleteStory", "failed to delete story", storyID, ifUpdatedAt)
+		}
+
+		err = sss.recordEvents(ctx, tx, sqliteInsertEvents, model.NewStoryDeletedEvent(storyID))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.DeleteStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+func (sss *sqliteStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	if err := checkPage(offset, limit); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetMostViewsStories.checkPage"), err)
+	}
+
+	return getRecords(ctx, sss.querier(), sqliteGetMostViewed, limit, offset)
+}
+
+func (sss *sqliteStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	if err := checkPage(offset, limit); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopRatedStories.checkPage"), err)
+	}
+
+	return getRecords(ctx, sss.querier(), sqliteGetTopRated, limit, offset)
+}
+
+func (sss *sqliteStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	values, args, err := buildCounterValues(func(n int) string {
+		return fmt.Sprintf("(?%d, ?%d, ?%d, ?%d)", n, n+1, n+2, n+3)
+	}, deltas...)
+
+	if err != nil || len(args) == 0 {
+		return err
+	}
+
+	return sss.inTx(ctx, "StoriesStore.IncrementCounters", func(tx *sql.Tx) error {
+		if _, err := execQuery(ctx, tx, fmt.Sprintf(sqliteIncrementCounters, values), args...); err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		day := model.ToDay(sss.now()).Format(dayLayout)
+
+		_, err := execQuery(ctx, tx, fmt.Sprintf(sqliteIncrementDailyStats, values, len(args)+1), append(args, day)...)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		err = sss.recordEvents(ctx, tx, sqliteInsertVotedEvents, model.NewStoryVotedEvents(model.MergeDeltas(deltas...)...)...)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		return nil
+	})
+}
+
+func (sss *sqliteStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	if !isValidUUID(storyID) {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", storyID))
+	}
+
+	rows, err := sss.querier().QueryContext(ctx, sqliteGetDailyStats, storyID, model.ToDay(from).Format(dayLayout), model.ToDay(to).Format(dayLayout))
+	if err != nil {
+		return nil, translateError("StoriesStore.GetDailyStats.db.Query", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var stats []model.DailyStats
+	for rows.Next() {
+		var ds model.DailyStats
+
+		err := rows.Scan(&ds.StoryID, &ds.Day, &ds.Views, &ds.UpVotes, &ds.DownVotes)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.rows.Scan"), liberr.SeverityError, err)
+		}
+
+		ds.Day = model.ToDay(ds.Day)
+		stats = append(stats, ds)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.rows.Err"), liberr.SeverityError, err)
+	}
+
+	return stats, nil
+}
+
+func (sss *sqliteStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	column, ok := metricColumns[metric]
+	if !ok {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid metric %s", metric))
+	}
+
+	if err := checkPage(0, limit); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers.checkPage"), err)
+	}
+
+	rows, err := sss.querier().QueryContext(ctx, fmt.Sprintf(sqliteGetTopMovers, column),
+		model.ToDay(previousFrom).Format(dayLayout), model.ToDay(previousTo).Format(dayLayout),
+		model.ToDay(currentFrom).Format(dayLayout), model.ToDay(currentTo).Format(dayLayout),
+		limit,
+	)
+
+	if err != nil {
+		return nil, translateError("StoriesStore.GetTopMovers.db.Query", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var movers []model.Mover
+	for rows.Next() {
+		var m model.Mover
+
+		err := rows.Scan(&m.StoryID, &m.Previous, &m.Current)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers.rows.Scan"), liberr.SeverityError, err)
+		}
+
+		movers = append(movers, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers.rows.Err"), liberr.SeverityError, err)
+	}
+
+	return movers, nil
+}
+
+func (sss *sqliteStoriesStore) recordDailyStats(ctx context.Context, ex executor, storyID string, views, upVotes, downVotes int64) error {
+	if views == 0 && upVotes == 0 && downVotes == 0 {
+		return nil
+	}
+
+	_, err := ex.ExecContext(ctx, sqliteUpsertDailyStats, storyID, model.ToDay(sss.now()).Format(dayLayout), views, upVotes, downVotes)
+	if err != nil {
+		return translateError("recordDailyStats.Exec", err)
+	}
+
+	return nil
+}
+
+// SQLITE HAS NO RETURNING YET, THE UPDATE TAKES THE WRITE LOCK FIRST SO NO OTHER CLAIM CAN SLIP IN BEFORE THE
+// CLAIMED EVENTS ARE READ BACK BY THEIR LEASE, WHICH IS UNIQUE TO THE NANOSECOND
+func (sss *sqliteStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	now := sss.now().UTC()
+	claimedUntil := now.Add(lease)
+
+	var events []model.Event
+
+	claim := func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, sqliteClaimEvents, claimedUntil, now, limit); err != nil {
+			return translateError("StoriesStore.ClaimEvents.db.Exec", err)
+		}
+
+		rows, err := tx.QueryContext(ctx, sqliteGetClaimedEvents, claimedUntil)
+		if err != nil {
+			return translateError("StoriesStore.ClaimEvents.db.Query", err)
+		}
+
+		events, err = scanEvents(rows)
+		return err
+	}
+
+	var err error
+	if sss.tx != nil {
+		err = claim(sss.tx)
+	} else {
+		err = runInTx(ctx, "StoriesStore.ClaimEvents", sss.db, nil, claim)
+	}
+
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.ClaimEvents"), err)
+	}
+
+	return events, nil
+}
+
+func (sss *sqliteStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(eventIDs)+1)
+	placeholders := make([]string, len(eventIDs))
+
+	args = append(args, sss.now().UTC())
+	for i, id := range eventIDs {
+		args = append(args, id)
+		placeholders[i] = "?"
+	}
+
+	_, err := execQuery(ctx, sss.querier(), fmt.Sprintf(sqliteMarkEventsPublished, strings.Join(placeholders, ",")), args...)
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoriesStore.MarkEventsPublished"), err)
+	}
+
+	return nil
+}
+
+func (sss *sqliteStoriesStore) recordEvents(ctx context.Context, ex executor, query string, events ...model.Event) error {
+	values, args := buildEventValues(func(n int) string {
+		return fmt.Sprintf("(?%d, ?%d, ?%d)", n, n+1, n+2)
+	}, events...)
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	_, err := ex.ExecContext(ctx, fmt.Sprintf(query, values, len(args)+1), append(args, sss.now().UTC())...)
+	if err != nil {
+		return translateError("recordEvents.Exec", err)
+	}
+
+	return nil
+}
+
+// SQLITE TREATS A NEGATIVE LIMIT AS NO LIMIT, POSTGRES REJECTS IT, KEEP THE POSTGRES BEHAVIOUR
+func checkPage(offset, limit int) error {
+	if offset < 0 {
+		return liberr.WithArgs(liberr.SeverityError, errors.New("OFFSET must not be negative"))
+	}
+
+	if limit < 0 {
+		return liberr.WithArgs(liberr.SeverityError, errors.New("LIMIT must not be negative"))
+	}
+
+	return nil
+}
+
+// SQLITE ONLY RUNS SERIALIZABLE TRANSACTIONS, A BUSY DATABASE IS RETRIED LIKE A SERIALIZATION FAILURE
+func (sss *sqliteStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) error {
+	if sss.tx != nil {
+		return fn(sss)
+	}
+
+	err := retryTx(ctx, defaultTxMaxRetries, func() error {
+		return runInTx(ctx, "StoriesStore.WithTx", sss.db, nil, func(tx *sql.Tx) error {
+			return fn(&sqliteStoriesStore{db: sss.db, now: sss.now, tx: tx})
+		})
+	})
+
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoriesStore.WithTx"), err)
+	}
+
+	return nil
+}
+
+// THE POOL HOLDS A SINGLE CONNECTION, ONCE IN A TRANSACTION EVERY QUERY MUST GO THROUGH IT
+func (sss *sqliteStoriesStore) inTx(ctx context.Context, op string, fn func(tx *sql.Tx) error) error {
+	if sss.tx != nil {
+		return fn(sss.tx)
+	}
+
+	return runInTx(ctx, op, sss.db, nil, fn)
+}
+
+func (sss *sqliteStoriesStore) querier() querier {
+	if sss.tx != nil {
+		return sss.tx
+	}
+
+	return sss.db
+}
+
+func NewSQLiteStoriesStore(db *sql.DB) StoriesStore {
+	return &sqliteStoriesStore{
+		db:  db,
+		now: time.Now,
+	}
+}
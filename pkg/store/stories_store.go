@@ -2,62 +2,304 @@ package store
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"github.com/lib/pq"
+	"github.com/nsnikhil/stories/pkg/config"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/story/model"
-	"regexp"
+	"strings"
+	"time"
 )
 
 //TODO: SWITCH TO ORM TO REMOVE COUPLING OF QUERY WITH STRUCTS
 const (
 	insertStory   = `INSERT INTO stories (title, body, viewcount, upvotes, downvotes) VALUES ($1, $2, $3, $4, $5) RETURNING id`
 	getStories    = `SELECT * FROM stories WHERE id IN (`
-	updateStory   = `UPDATE stories set title=$1, body=$2, viewCount=$3, upVotes=$4, downVotes=$5, updatedAt=now() WHERE id=$6`
-	deleteStory   = `DELETE FROM stories WHERE id=$1`
+	updateStory   = `UPDATE stories set title=$1, body=$2, viewCount=$3, upVotes=$4, downVotes=$5, updatedAt=now() WHERE id=$6 AND ($7::timestamp IS NULL OR updatedAt=$7::timestamp)`
+	patchStory    = `UPDATE stories SET title=COALESCE($1, title), body=COALESCE($2, body), updatedAt=now() WHERE id=$3 AND ($4::timestamp IS NULL OR updatedAt=$4::timestamp)
+		RETURNING id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt`
+	deleteStory   = `DELETE FROM stories WHERE id=$1 AND ($2::timestamp IS NULL OR updatedAt=$2::timestamp)`
+	storyExists   = `SELECT EXISTS (SELECT 1 FROM stories WHERE id=$1)`
 	getMostViewed = `SELECT * FROM stories ORDER BY viewCount DESC LIMIT $1 OFFSET $2`
 	getTopRated   = `SELECT * FROM stories ORDER BY upVotes DESC LIMIT $1 OFFSET $2`
+
+	getCounters      = `SELECT viewCount, upVotes, downVotes FROM stories WHERE id=$1 FOR UPDATE`
+	upsertDailyStats = `INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes) VALUES ($1, (now() at time zone 'utc')::date, $2, $3, $4)
+		ON CONFLICT (storyId, day) DO UPDATE SET views=story_daily_stats.views+EXCLUDED.views, upVotes=story_daily_stats.upVotes+EXCLUDED.upVotes, downVotes=story_daily_stats.downVotes+EXCLUDED.downVotes`
+	incrementCounters = `UPDATE stories SET viewCount=stories.viewCount+deltas.views, upVotes=stories.upVotes+deltas.upVotes, downVotes=stories.downVotes+deltas.downVotes
+		FROM (VALUES %s) AS deltas (id, views, upVotes, downVotes) WHERE stories.id=deltas.id`
+	incrementDailyStats = `INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes)
+		SELECT deltas.id, (now() at time zone 'utc')::date, deltas.views, deltas.upVotes, deltas.downVotes
+		FROM (VALUES %s) AS deltas (id, views, upVotes, downVotes) WHERE deltas.id IN (SELECT id FROM stories)
+		ON CONFLICT (storyId, day) DO UPDATE SET views=story_daily_stats.views+EXCLUDED.views, upVotes=story_daily_stats.upVotes+EXCLUDED.upVotes, downVotes=story_daily_stats.downVotes+EXCLUDED.downVotes`
+	getDailyStats = `SELECT storyId, day, views, upVotes, downVotes FROM story_daily_stats WHERE storyId=$1 AND day BETWEEN $2 AND $3 ORDER BY day`
+	getTopMovers  = `SELECT storyId, previousCount, currentCount FROM (
+		SELECT storyId, COALESCE(SUM(%[1]s) FILTER (WHERE day BETWEEN $1 AND $2), 0) AS previousCount, COALESCE(SUM(%[1]s) FILTER (WHERE day BETWEEN $3 AND $4), 0) AS currentCount
+		FROM story_daily_stats WHERE day BETWEEN $1 AND $2 OR day BETWEEN $3 AND $4 GROUP BY storyId
+	) AS movers ORDER BY currentCount - previousCount DESC, storyId LIMIT $5`
+
+	insertEvents      = `INSERT INTO outbox (storyId, eventType, payload) VALUES %s`
+	insertVotedEvents = `INSERT INTO outbox (storyId, eventType, payload)
+		SELECT events.storyId, events.eventType, events.payload FROM (VALUES %s) AS events (storyId, eventType, payload) WHERE events.storyId IN (SELECT id FROM stories)`
+	lockOutbox  = `SELECT pg_advisory_xact_lock($1)`
+	claimEvents = `WITH claimed AS (
+		UPDATE outbox SET claimedUntil=(now() at time zone 'utc') + $2 * interval '1 second' WHERE id IN (
+			SELECT o.id FROM outbox o WHERE o.publishedAt IS NULL AND NOT EXISTS (
+				SELECT 1 FROM outbox c WHERE c.storyId=o.storyId AND c.publishedAt IS NULL AND c.claimedUntil > (now() at time zone 'utc')
+			) ORDER BY o.id LIMIT $1
+		) RETURNING id, storyId, eventType, payload, createdAt
+	) SELECT id, storyId, eventType, payload, createdAt FROM claimed ORDER BY id`
+	markEventsPublished = `UPDATE outbox SET publishedAt=(now() at time zone 'utc') WHERE id = ANY($1)`
+
+	// outboxClaimLockKey IS AN ARBITRARY CONSTANT, IT ONLY HAS TO DIFFER FROM migrationLockKey
+	outboxClaimLockKey = 7046191232715035
+)
+
+const (
+	insertStories         = `INSERT INTO stories (id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt) VALUES %s`
+	insertStoryDailyStats = `INSERT INTO story_daily_stats (storyId, day, views, upVotes, downVotes) VALUES %s`
+
+	// KEEPS A BATCH WELL UNDER THE BIND PARAMETER LIMIT OF POSTGRES (65535) AND SQLITE (32766)
+	maxStoriesPerStatement = 1000
 )
 
+var metricColumns = map[model.Metric]string{
+	model.MetricViews:     "views",
+	model.MetricUpVotes:   "upVotes",
+	model.MetricDownVotes: "downVotes",
+}
+
 type StoriesStore interface {
 	//TODO: IS THE ID NEEDED IN THE RETURN?
-	AddStory(story *model.Story) (string, error)
+	AddStory(ctx context.Context, story *model.Story) (string, error)
+
+	// AddStories INSERTS ALL THE STORIES OR NONE AND RETURNS THEIR IDS IN ORDER. THE ID AND THE TIMESTAMPS OF
+	// A STORY ARE KEPT WHEN SET SO THE DATA CAN BE BACKDATED, ITS INITIAL COUNTS GO TO THE DAY IT WAS CREATED.
+	// IT IS MEANT FOR BULK IMPORTS LIKE THE SEED DATA SO UNLIKE AddStory IT WRITES NO story.created EVENTS.
+	AddStories(ctx context.Context, stories ...*model.Story) ([]string, error)
 
-	GetStories(storyIDs ...string) ([]model.Story, error)
+	GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error)
 
+	// UpdateStory, PatchStory AND DeleteStory ONLY WRITE A STORY LAST UPDATED AT ifUpdatedAt AND FAIL WITH
+	// A PreconditionFailed ERROR WHEN IT HAS CHANGED SINCE, THE ZERO TIME WRITES WHATEVER THE VERSION.
 	//TODO: IS THE COUNT NEEDED IN THE RETURN?
-	UpdateStory(story *model.Story) (int64, error)
+	UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error)
+
+	// PatchStory ONLY WRITES THE TITLE, THE BODY AND THE UPDATE TIME, THE COUNTERS ARE LEFT AS THEY ARE IN THE STORE
+	PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error)
 
 	//TODO: IS THE COUNT NEEDED IN THE RETURN?
-	DeleteStory(storyID string) (int64, error)
+	DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error)
+
+	GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error)
+	GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error)
 
-	GetMostViewsStories(offset, limit int) ([]model.Story, error)
-	GetTopRatedStories(offset, limit int) ([]model.Story, error)
+	// IncrementCounters ADDS THE DELTAS TO THE STORY COUNTERS AND TO TODAY'S STATS IN A SINGLE BATCH,
+	// DELTAS FOR STORIES THAT NO LONGER EXIST ARE DROPPED
+	IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error
+
+	GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error)
+	GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error)
+
+	// ClaimEvents LEASES THE OLDEST EVENTS NOT PUBLISHED YET TO THE CALLER FOR lease, IN THE ORDER THEY WERE WRITTEN.
+	// A STORY WITH AN EVENT UNDER LEASE, TAKEN BY ANOTHER RELAY OR LEFT BY A FAILED PUBLISH, IS SKIPPED UNTIL THE
+	// LEASE RUNS OUT, SO THE EVENTS OF A STORY ARE NEVER PUBLISHED OUT OF ORDER AND A FAILING STORY DOES NOT HOLD
+	// BACK THE OTHERS. THE CLAIM COMMITS ON RETURN, NO LOCK IS HELD WHILE THE EVENTS ARE PUBLISHED.
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error)
+
+	MarkEventsPublished(ctx context.Context, eventIDs ...int64) error
+
+	// WithTx RUNS fn AGAINST A STORE BOUND TO A SINGLE TRANSACTION, IT COMMITS WHEN fn RETURNS NIL AND
+	// ROLLS BACK OTHERWISE. CALLING WithTx ON THE STORE PASSED TO fn JOINS THE SURROUNDING TRANSACTION.
+	WithTx(ctx context.Context, fn func(StoriesStore) error) error
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
 //TODO: RENAME (REMOVE DEFAULT)
 type defaultStoriesStore struct {
-	db *sql.DB
+	cluster DBCluster
+	txOpts  txOptions
+
+	// tx IS ONLY SET ON THE STORE HANDED TO THE WithTx CALLBACK
+	tx *sql.Tx
 }
 
-func (dss *defaultStoriesStore) AddStory(st *model.Story) (string, error) {
+func (dss *defaultStoriesStore) AddStory(ctx context.Context, st *model.Story) (string, error) {
 	var id string
-	err := dss.db.QueryRow(insertStory, st.GetTitle(), st.GetBody(), st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes()).Scan(&id)
+
+	err := dss.inTx(ctx, "StoriesStore.AddStory", func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, insertStory, st.GetTitle(), st.GetBody(), st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes()).Scan(&id)
+		if err != nil {
+			return translateError("StoriesStore.AddStory.db.QueryRow", err)
+		}
+
+		err = recordDailyStats(ctx, tx, id, st.GetViewCount(), st.GetUpVotes(), st.GetDownVotes())
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.AddStory"), err)
+		}
+
+		created := *st
+		created.ID = id
+
+		err = recordEvents(ctx, tx, insertEvents, model.NewStoryCreatedEvent(created))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.AddStory"), err)
+		}
+
+		return nil
+	})
+
 	if err != nil {
-		return "", liberr.WithArgs(liberr.Operation("StoriesStore.AddStory.db.QueryRow"), liberr.InternalError, liberr.SeverityError, err)
+		return "", err
 	}
 
 	return id, nil
 }
 
-func (dss *defaultStoriesStore) GetStories(storyIDs ...string) ([]model.Story, error) {
+func (dss *defaultStoriesStore) AddStories(ctx context.Context, stories ...*model.Story) ([]string, error) {
+	prepared, err := prepareStories(time.Now(), stories...)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+	}
+
+	if len(prepared) == 0 {
+		return nil, nil
+	}
+
+	err = dss.inTx(ctx, "StoriesStore.AddStories", func(tx *sql.Tx) error {
+		return inBatches(prepared, func(batch []model.Story) error {
+			values, args := buildStoryValues(func(n int) string {
+				return fmt.Sprintf("($%d::uuid, $%d, $%d, $%d::bigint, $%d::bigint, $%d::bigint, $%d::timestamp, $%d::timestamp)", n, n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			}, batch...)
+
+			if _, err := execQuery(ctx, tx, fmt.Sprintf(insertStories, values), args...); err != nil {
+				return liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+			}
+
+			values, args = buildCreatedStatsValues(func(n int) string {
+				return fmt.Sprintf("($%d::uuid, $%d::date, $%d::bigint, $%d::bigint, $%d::bigint)", n, n+1, n+2, n+3, n+4)
+			}, batch...)
+
+			if len(args) != 0 {
+				if _, err := execQuery(ctx, tx, fmt.Sprintf(insertStoryDailyStats, values), args...); err != nil {
+					return liberr.WithArgs(liberr.Operation("StoriesStore.AddStories"), err)
+				}
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storyIDsOf(prepared...), nil
+}
+
+// prepareStories COPIES THE STORIES AND FILLS IN THE ID AND THE TIMESTAMPS THAT ARE NOT SET
+func prepareStories(now time.Time, stories ...*model.Story) ([]model.Story, error) {
+	prepared := make([]model.Story, 0, len(stories))
+
+	for _, st := range stories {
+		p := *st
+
+		if p.ID == "" {
+			id, err := newUUID()
+			if err != nil {
+				return nil, liberr.WithArgs(liberr.Operation("prepareStories.newUUID"), liberr.InternalError, liberr.SeverityError, err)
+			}
+
+			p.ID = id
+		}
+
+		if !isValidUUID(p.ID) {
+			return nil, liberr.WithArgs(liberr.Operation("prepareStories.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", p.ID))
+		}
+
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = now
+		}
+
+		if p.UpdatedAt.IsZero() {
+			p.UpdatedAt = p.CreatedAt
+		}
+
+		p.CreatedAt, p.UpdatedAt = p.CreatedAt.UTC(), p.UpdatedAt.UTC()
+		prepared = append(prepared, p)
+	}
+
+	return prepared, nil
+}
+
+func inBatches(stories []model.Story, fn func(batch []model.Story) error) error {
+	for start := 0; start < len(stories); start += maxStoriesPerStatement {
+		end := start + maxStoriesPerStatement
+		if end > len(stories) {
+			end = len(stories)
+		}
+
+		if err := fn(stories[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildStoryValues RETURNS THE ROWS OF A VALUES LIST WITH ONE
+// (id, title, body, viewCount, upVotes, downVotes, createdAt, updatedAt) TUPLE PER STORY
+func buildStoryValues(row func(n int) string, stories ...model.Story) (string, []interface{}) {
+	rows := make([]string, 0, len(stories))
+	args := make([]interface{}, 0, len(stories)*8)
+
+	for _, st := range stories {
+		rows = append(rows, row(len(args)+1))
+		args = append(args, st.ID, st.Title, st.Body, st.ViewCount, st.UpVotes, st.DownVotes, st.CreatedAt, st.UpdatedAt)
+	}
+
+	return strings.Join(rows, ", "), args
+}
+
+// buildCreatedStatsValues RETURNS THE ROWS OF A VALUES LIST WITH ONE (storyId, day, views, upVotes, downVotes)
+// TUPLE PER STORY THAT STARTS WITH NON ZERO COUNTS, THE DAY IS THE ONE THE STORY WAS CREATED ON
+func buildCreatedStatsValues(row func(n int) string, stories ...model.Story) (string, []interface{}) {
+	var rows []string
+	var args []interface{}
+
+	for _, st := range stories {
+		if st.ViewCount == 0 && st.UpVotes == 0 && st.DownVotes == 0 {
+			continue
+		}
+
+		rows = append(rows, row(len(args)+1))
+		args = append(args, st.ID, model.ToDay(st.CreatedAt).Format(dayLayout), st.ViewCount, st.UpVotes, st.DownVotes)
+	}
+
+	return strings.Join(rows, ", "), args
+}
+
+func storyIDsOf(stories ...model.Story) []string {
+	ids := make([]string, 0, len(stories))
+	for _, st := range stories {
+		ids = append(ids, st.ID)
+	}
+
+	return ids
+}
+
+func (dss *defaultStoriesStore) GetStories(ctx context.Context, storyIDs ...string) ([]model.Story, error) {
 	query, err := buildQuery(getStories, storyIDs...)
 	if err != nil {
 		return nil, err
 	}
 
-	return getRecords(dss.db, query)
+	return getRecords(ctx, dss.reader(ctx), query)
 }
 
 // TO PREVENT SQL INJECTION
@@ -72,7 +314,7 @@ func buildQuery(query string, id ...string) (string, error) {
 		}
 
 		if !isValidUUID(v) {
-			return "", liberr.WithArgs(liberr.Operation("buildQuery.isValidUUID"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid uuid %s", v))
+			return "", liberr.WithArgs(liberr.Operation("buildQuery.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", v))
 		}
 
 		_, err := buf.WriteString(fmt.Sprintf("'%s'", v))
@@ -90,42 +332,396 @@ func buildQuery(query string, id ...string) (string, error) {
 	return buf.String(), nil
 }
 
-func (dss *defaultStoriesStore) UpdateStory(story *model.Story) (int64, error) {
-	return execQueryWithError(dss.db, updateStory, "failed to update story",
-		story.GetTitle(), story.GetBody(),
-		story.GetViewCount(), story.GetUpVotes(),
-		story.GetDownVotes(), story.GetID())
+func (dss *defaultStoriesStore) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(story.GetID()) {
+		return 0, invalidStoryID("StoriesStore.UpdateStory.isValidUUID", story.GetID())
+	}
+
+	var c int64
+
+	err := dss.inTx(ctx, "StoriesStore.UpdateStory", func(tx *sql.Tx) error {
+		var views, upVotes, downVotes int64
+		err := tx.QueryRowContext(ctx, getCounters, story.GetID()).Scan(&views, &upVotes, &downVotes)
+		if err == sql.ErrNoRows {
+			return notFound("StoriesStore.UpdateStory", "failed to update story")
+		}
+
+		if err != nil {
+			return translateError("StoriesStore.UpdateStory.tx.QueryRow", err)
+		}
+
+		c, err = execQuery(ctx, tx, updateStory,
+			story.GetTitle(), story.GetBody(),
+			story.GetViewCount(), story.GetUpVotes(),
+			story.GetDownVotes(), story.GetID(), versionArg(ifUpdatedAt))
+
+		if err != nil {
+			return err
+		}
+
+		// THE ROW IS LOCKED BY THE READ OF ITS COUNTERS SO IT CAN ONLY BE MISSED ON ITS VERSION
+		if c == 0 {
+			return preconditionFailed("StoriesStore.UpdateStory")
+		}
+
+		err = recordDailyStats(ctx, tx, story.GetID(), story.GetViewCount()-views, story.GetUpVotes()-upVotes, story.GetDownVotes()-downVotes)
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.UpdateStory"), err)
+		}
+
+		err = recordEvents(ctx, tx, insertEvents, model.NewStoryUpdatedEvent(*story))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.UpdateStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+func (dss *defaultStoriesStore) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(patch.ID) {
+		return 0, invalidStoryID("StoriesStore.PatchStory.isValidUUID", patch.ID)
+	}
+
+	err := dss.inTx(ctx, "StoriesStore.PatchStory", func(tx *sql.Tx) error {
+		var st model.Story
+		err := tx.QueryRowContext(ctx, patchStory, patch.Title, patch.Body, patch.ID, versionArg(ifUpdatedAt)).
+			Scan(&st.ID, &st.Title, &st.Body, &st.ViewCount, &st.UpVotes, &st.DownVotes, &st.CreatedAt, &st.UpdatedAt)
+
+		if err == sql.ErrNoRows {
+			return missedWrite(ctx, tx, storyExists, "StoriesStore.PatchStory", "failed to update story", patch.ID, ifUpdatedAt)
+		}
+
+		if err != nil {
+			return translateError("StoriesStore.PatchStory.tx.QueryRow", err)
+		}
+
+		err = recordEvents(ctx, tx, insertEvents, model.NewStoryUpdatedEvent(st))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.PatchStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return 1, nil
+}
+
+func (dss *defaultStoriesStore) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	if !isValidUUID(storyID) {
+		return 0, invalidStoryID("StoriesStore.DeleteStory.isValidUUID", storyID)
+	}
+
+	var c int64
+
+	err := dss.inTx(ctx, "StoriesStore.DeleteStory", func(tx *sql.Tx) error {
+		var err error
+
+		c, err = execQuery(ctx, tx, deleteStory, storyID, versionArg(ifUpdatedAt))
+		if err != nil {
+			return err
+		}
+
+		if c == 0 {
+			return missedWrite(ctx, tx, storyExists, "StoriesStore.DeleteStory", "failed to delete story", storyID, ifUpdatedAt)
+		}
+
+		err = recordEvents(ctx, tx, insertEvents, model.NewStoryDeletedEvent(storyID))
+		if err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.DeleteStory"), err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return c, nil
+}
+
+func (dss *defaultStoriesStore) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return getRecords(ctx, dss.reader(ctx), getMostViewed, limit, offset)
 }
 
-func (dss *defaultStoriesStore) DeleteStory(storyID string) (int64, error) {
-	return execQueryWithError(dss.db, deleteStory, "failed to delete story", storyID)
+func (dss *defaultStoriesStore) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return getRecords(ctx, dss.reader(ctx), getTopRated, limit, offset)
 }
 
-func (dss *defaultStoriesStore) GetMostViewsStories(offset, limit int) ([]model.Story, error) {
-	return getRecords(dss.db, getMostViewed, limit, offset)
+func (dss *defaultStoriesStore) IncrementCounters(ctx context.Context, deltas ...model.CounterDelta) error {
+	values, args, err := buildCounterValues(func(n int) string {
+		return fmt.Sprintf("($%d::uuid, $%d::bigint, $%d::bigint, $%d::bigint)", n, n+1, n+2, n+3)
+	}, deltas...)
+
+	if err != nil || len(args) == 0 {
+		return err
+	}
+
+	return dss.inTx(ctx, "StoriesStore.IncrementCounters", func(tx *sql.Tx) error {
+		if _, err := execQuery(ctx, tx, fmt.Sprintf(incrementCounters, values), args...); err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		if _, err := execQuery(ctx, tx, fmt.Sprintf(incrementDailyStats, values), args...); err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		if err := recordEvents(ctx, tx, insertVotedEvents, model.NewStoryVotedEvents(model.MergeDeltas(deltas...)...)...); err != nil {
+			return liberr.WithArgs(liberr.Operation("StoriesStore.IncrementCounters"), err)
+		}
+
+		return nil
+	})
 }
 
-func (dss *defaultStoriesStore) GetTopRatedStories(offset, limit int) ([]model.Story, error) {
-	return getRecords(dss.db, getTopRated, limit, offset)
+// buildCounterValues RETURNS THE ROWS OF A VALUES LIST WITH ONE (id, views, upVotes, downVotes) TUPLE PER STORY,
+// row RECEIVES THE INDEX OF THE FIRST PLACEHOLDER OF THE TUPLE
+func buildCounterValues(row func(n int) string, deltas ...model.CounterDelta) (string, []interface{}, error) {
+	deltas = model.MergeDeltas(deltas...)
+
+	rows := make([]string, 0, len(deltas))
+	args := make([]interface{}, 0, len(deltas)*4)
+
+	for _, d := range deltas {
+		if !isValidUUID(d.StoryID) {
+			return "", nil, liberr.WithArgs(liberr.Operation("buildCounterValues.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", d.StoryID))
+		}
+
+		rows = append(rows, row(len(args)+1))
+		args = append(args, d.StoryID, d.Views, d.UpVotes, d.DownVotes)
+	}
+
+	return strings.Join(rows, ", "), args, nil
 }
 
-func execQueryWithError(db *sql.DB, query string, errMsg string, args ...interface{}) (int64, error) {
-	ra, err := execQuery(db, query, args...)
+func (dss *defaultStoriesStore) GetDailyStats(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	if !isValidUUID(storyID) {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.isValidUUID"), liberr.ValidationError, liberr.CodeInvalidStoryID, liberr.SeverityError, fmt.Errorf("invalid uuid %s", storyID))
+	}
+
+	rows, err := dss.reader(ctx).QueryContext(ctx, getDailyStats, storyID, model.ToDay(from), model.ToDay(to))
 	if err != nil {
-		return 0, err
+		return nil, translateError("StoriesStore.GetDailyStats.db.Query", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var stats []model.DailyStats
+	for rows.Next() {
+		var ds model.DailyStats
+
+		err := rows.Scan(&ds.StoryID, &ds.Day, &ds.Views, &ds.UpVotes, &ds.DownVotes)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.rows.Scan"), liberr.SeverityError, err)
+		}
+
+		ds.Day = model.ToDay(ds.Day)
+		stats = append(stats, ds)
 	}
 
-	if ra == 0 {
-		return 0, liberr.WithArgs(liberr.Operation("execQueryWithError"), liberr.SeverityError, errors.New(errMsg))
+	if err := rows.Err(); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetDailyStats.rows.Err"), liberr.SeverityError, err)
 	}
 
-	return ra, nil
+	return stats, nil
+}
+
+func (dss *defaultStoriesStore) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	column, ok := metricColumns[metric]
+	if !ok {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid metric %s", metric))
+	}
+
+	rows, err := dss.reader(ctx).QueryContext(ctx, fmt.Sprintf(getTopMovers, column),
+		model.ToDay(previousFrom), model.ToDay(previousTo),
+		model.ToDay(currentFrom), model.ToDay(currentTo),
+		limit,
+	)
+
+	if err != nil {
+		return nil, translateError("StoriesStore.GetTopMovers.db.Query", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var movers []model.Mover
+	for rows.Next() {
+		var m model.Mover
+
+		err := rows.Scan(&m.StoryID, &m.Previous, &m.Current)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers.rows.Scan"), liberr.SeverityError, err)
+		}
+
+		movers = append(movers, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.GetTopMovers.rows.Err"), liberr.SeverityError, err)
+	}
+
+	return movers, nil
+}
+
+// ClaimEvents TAKES A TRANSACTION LEVEL ADVISORY LOCK SO CONCURRENT CLAIMS RUN ONE AFTER THE OTHER, READ COMMITTED
+// LETS THE CLAIM SEE WHAT THE PREVIOUS ONE COMMITTED WHILE IT WAITED FOR THE LOCK
+func (dss *defaultStoriesStore) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	var events []model.Event
+
+	claim := func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, lockOutbox, outboxClaimLockKey); err != nil {
+			return translateError("StoriesStore.ClaimEvents.lock", err)
+		}
+
+		rows, err := tx.QueryContext(ctx, claimEvents, limit, lease.Seconds())
+		if err != nil {
+			return translateError("StoriesStore.ClaimEvents.db.Query", err)
+		}
+
+		events, err = scanEvents(rows)
+		return err
+	}
+
+	var err error
+	if dss.tx != nil {
+		err = claim(dss.tx)
+	} else {
+		err = runInTx(ctx, "StoriesStore.ClaimEvents", dss.cluster.Writer(), &sql.TxOptions{Isolation: sql.LevelReadCommitted}, claim)
+	}
+
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoriesStore.ClaimEvents"), err)
+	}
+
+	return events, nil
+}
+
+func (dss *defaultStoriesStore) MarkEventsPublished(ctx context.Context, eventIDs ...int64) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	if _, err := execQuery(ctx, dss.writer(), markEventsPublished, pq.Array(eventIDs)); err != nil {
+		return liberr.WithArgs(liberr.Operation("StoriesStore.MarkEventsPublished"), err)
+	}
+
+	dss.markWrite(ctx)
+	return nil
 }
 
-func execQuery(db *sql.DB, query string, args ...interface{}) (int64, error) {
-	res, err := db.Exec(query, args...)
+// THE EVENTS ARE WRITTEN AFTER THE STORY ROW IS LOCKED BY THE MUTATION, THE EVENTS OF A STORY THEREFORE
+// GET THEIR IDS IN THE ORDER THEIR TRANSACTIONS COMMIT
+func recordEvents(ctx context.Context, ex executor, query string, events ...model.Event) error {
+	values, args := buildEventValues(func(n int) string {
+		return fmt.Sprintf("($%d::uuid, $%d, $%d::jsonb)", n, n+1, n+2)
+	}, events...)
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	if _, err := ex.ExecContext(ctx, fmt.Sprintf(query, values), args...); err != nil {
+		return translateError("recordEvents.Exec", err)
+	}
+
+	return nil
+}
+
+// buildEventValues RETURNS THE ROWS OF A VALUES LIST WITH ONE (storyId, eventType, payload) TUPLE PER EVENT,
+// row RECEIVES THE INDEX OF THE FIRST PLACEHOLDER OF THE TUPLE
+func buildEventValues(row func(n int) string, events ...model.Event) (string, []interface{}) {
+	rows := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*3)
+
+	for _, e := range events {
+		rows = append(rows, row(len(args)+1))
+		args = append(args, e.GetStoryID(), string(e.GetType()), string(e.Payload))
+	}
+
+	return strings.Join(rows, ", "), args
+}
+
+func scanEvents(rows *sql.Rows) ([]model.Event, error) {
+	defer func() { _ = rows.Close() }()
+
+	var events []model.Event
+	for rows.Next() {
+		var e model.Event
+		var payload []byte
+
+		err := rows.Scan(&e.ID, &e.StoryID, &e.Type, &payload, &e.CreatedAt)
+		if err != nil {
+			return nil, liberr.WithArgs(liberr.Operation("scanEvents.rows.Scan"), liberr.SeverityError, err)
+		}
+
+		e.Payload = payload
+		e.CreatedAt = e.CreatedAt.UTC()
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("scanEvents.rows.Err"), liberr.SeverityError, err)
+	}
+
+	return events, nil
+}
+
+// ROLLS THE GIVEN COUNTER DELTAS INTO TODAY'S AGGREGATE FOR THE STORY
+func recordDailyStats(ctx context.Context, ex executor, storyID string, views, upVotes, downVotes int64) error {
+	if views == 0 && upVotes == 0 && downVotes == 0 {
+		return nil
+	}
+
+	_, err := ex.ExecContext(ctx, upsertDailyStats, storyID, views, upVotes, downVotes)
 	if err != nil {
-		return 0, liberr.WithArgs(liberr.Operation("execQuery.db.Exec"), liberr.SeverityError, err)
+		return translateError("recordDailyStats.Exec", err)
+	}
+
+	return nil
+}
+
+// versionArg BINDS THE ZERO TIME AS NULL SO THE VERSION CHECK OF A CONDITIONAL WRITE PASSES
+func versionArg(ifUpdatedAt time.Time) interface{} {
+	if ifUpdatedAt.IsZero() {
+		return nil
+	}
+
+	return ifUpdatedAt.UTC()
+}
+
+// missedWrite TELLS A STORY THAT DOES NOT EXIST FROM ONE THAT HAS CHANGED SINCE THE VERSION THE WRITE EXPECTED
+func missedWrite(ctx context.Context, tx *sql.Tx, query, op, msg, storyID string, ifUpdatedAt time.Time) error {
+	if ifUpdatedAt.IsZero() {
+		return notFound(op, msg)
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, storyID).Scan(&exists); err != nil {
+		return translateError(op+".tx.QueryRow", err)
+	}
+
+	if !exists {
+		return notFound(op, msg)
+	}
+
+	return preconditionFailed(op)
+}
+
+func execQuery(ctx context.Context, db executor, query string, args ...interface{}) (int64, error) {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, translateError("execQuery.db.Exec", err)
 	}
 
 	ra, err := res.RowsAffected()
@@ -136,11 +732,11 @@ func execQuery(db *sql.DB, query string, args ...interface{}) (int64, error) {
 	return ra, nil
 }
 
-func getRecords(db *sql.DB, query string, args ...interface{}) ([]model.Story, error) {
+func getRecords(ctx context.Context, db querier, query string, args ...interface{}) ([]model.Story, error) {
 	var stories []model.Story
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, liberr.WithArgs(liberr.Operation("getRecords.db.Query"), liberr.SeverityError, err)
+		return nil, translateError("getRecords.db.Query", err)
 	}
 
 	defer func() { _ = rows.Close() }()
@@ -158,18 +754,80 @@ func getRecords(db *sql.DB, query string, args ...interface{}) ([]model.Story, e
 	}
 
 	if len(stories) == 0 {
-		return nil, liberr.WithArgs(liberr.Operation("getRecords"), liberr.SeverityError, fmt.Errorf("no records found"))
+		return nil, notFound("getRecords", "no records found")
 	}
 
 	return stories, nil
 }
 
+func (dss *defaultStoriesStore) WithTx(ctx context.Context, fn func(StoriesStore) error) error {
+	if dss.tx != nil {
+		return fn(dss)
+	}
+
+	opts := &sql.TxOptions{Isolation: dss.txOpts.isolation}
+
+	err := retryTx(ctx, dss.txOpts.maxRetries, func() error {
+		return runInTx(ctx, "StoriesStore.WithTx", dss.cluster.Writer(), opts, func(tx *sql.Tx) error {
+			return fn(&defaultStoriesStore{cluster: dss.cluster, txOpts: dss.txOpts, tx: tx})
+		})
+	})
+
+	if err != nil {
+		return liberr.WithArgs(liberr.Operation("StoriesStore.WithTx"), err)
+	}
+
+	dss.cluster.MarkWrite(ctx)
+	return nil
+}
+
+// inTx JOINS THE SURROUNDING TRANSACTION WHEN THERE IS ONE AND STARTS ITS OWN OTHERWISE
+func (dss *defaultStoriesStore) inTx(ctx context.Context, op string, fn func(tx *sql.Tx) error) error {
+	if dss.tx != nil {
+		return fn(dss.tx)
+	}
+
+	if err := runInTx(ctx, op, dss.cluster.Writer(), nil, fn); err != nil {
+		return err
+	}
+
+	dss.cluster.MarkWrite(ctx)
+	return nil
+}
+
+func (dss *defaultStoriesStore) writer() querier {
+	if dss.tx != nil {
+		return dss.tx
+	}
+
+	return dss.cluster.Writer()
+}
+
+// INSIDE A TRANSACTION READS STAY ON IT TO SEE ITS OWN WRITES
+func (dss *defaultStoriesStore) reader(ctx context.Context) querier {
+	if dss.tx != nil {
+		return dss.tx
+	}
+
+	return dss.cluster.Reader(ctx)
+}
+
+// INSIDE A TRANSACTION THE WRITE IS MARKED BY WithTx ONCE IT COMMITS
+func (dss *defaultStoriesStore) markWrite(ctx context.Context) {
+	if dss.tx == nil {
+		dss.cluster.MarkWrite(ctx)
+	}
+}
+
 func NewStoriesStore(db *sql.DB) StoriesStore {
-	return &defaultStoriesStore{db: db}
+	return &defaultStoriesStore{cluster: newSingleDBCluster(db), txOpts: txOptions{isolation: sql.LevelDefault, maxRetries: defaultTxMaxRetries}}
 }
 
-// TODO: MOVE TO UTIL
-func isValidUUID(uuid string) bool {
-	r := regexp.MustCompile("^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$")
-	return r.MatchString(uuid)
+func NewReplicatedStoriesStore(cluster DBCluster, cfg config.DatabaseConfig) (StoriesStore, error) {
+	txOpts, err := newTxOptions(cfg.TxIsolationLevel(), cfg.TxMaxRetries())
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("NewReplicatedStoriesStore"), err)
+	}
+
+	return &defaultStoriesStore{cluster: cluster, txOpts: txOpts}, nil
 }
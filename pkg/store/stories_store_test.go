@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/nsnikhil/stories/pkg/config"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestStoriesStoreAddStory(t *testing.T) {
@@ -41,7 +43,7 @@ func TestStoriesStoreAddStory(t *testing.T) {
 			},
 			expectedError: liberr.WithArgs(
 				liberr.Operation("StoriesStore.AddStory.db.QueryRow"),
-				liberr.InternalError,
+				liberr.ConstraintViolation,
 				liberr.SeverityError,
 				errors.New("pq: new row for relation \"stories\" violates check constraint \"stories_title_check\""),
 			),
@@ -57,7 +59,7 @@ func TestStoriesStoreAddStory(t *testing.T) {
 			},
 			expectedError: liberr.WithArgs(
 				liberr.Operation("StoriesStore.AddStory.db.QueryRow"),
-				liberr.InternalError,
+				liberr.ConstraintViolation,
 				liberr.SeverityError,
 				errors.New("pq: new row for relation \"stories\" violates check constraint \"stories_body_check\""),
 			),
@@ -72,7 +74,7 @@ func TestStoriesStoreAddStory(t *testing.T) {
 }
 
 func testAddStory(t *testing.T, expectedError error, st *model.Story, db *sql.DB, str store.StoriesStore) {
-	id, err := str.AddStory(st)
+	id, err := str.AddStory(context.Background(), st)
 	truncate(t, db)
 
 	if expectedError == nil {
@@ -85,6 +87,7 @@ func testAddStory(t *testing.T, expectedError error, st *model.Story, db *sql.DB
 	assert.True(t, ok)
 
 	assert.Equal(t, expectedError.Error(), nt.Error())
+	assert.Equal(t, expectedError.(*liberr.Error).Kind(), nt.Kind())
 	assert.Equal(t, "", id)
 }
 
@@ -108,10 +111,10 @@ func TestGetStories(t *testing.T) {
 
 				require.NoError(t, err)
 
-				id, err := str.AddStory(st)
+				id, err := str.AddStory(context.Background(), st)
 				require.NoError(t, err)
 
-				stories, err := str.GetStories(id)
+				stories, err := str.GetStories(context.Background(), id)
 
 				truncate(t, db)
 				return stories, err
@@ -137,7 +140,7 @@ func TestGetStories(t *testing.T) {
 
 				require.NoError(t, err)
 
-				idOne, err := str.AddStory(st)
+				idOne, err := str.AddStory(context.Background(), st)
 				require.NoError(t, err)
 
 				st, err = model.NewStoryBuilder().
@@ -147,10 +150,10 @@ func TestGetStories(t *testing.T) {
 
 				require.NoError(t, err)
 
-				idTwo, err := str.AddStory(st)
+				idTwo, err := str.AddStory(context.Background(), st)
 				require.NoError(t, err)
 
-				stories, err := str.GetStories(idOne, idTwo)
+				stories, err := str.GetStories(context.Background(), idOne, idTwo)
 
 				truncate(t, db)
 				return stories, err
@@ -176,7 +179,7 @@ func TestGetStories(t *testing.T) {
 		{
 			name: "test return error when no record found against given ids",
 			actualResult: func() ([]model.Story, error) {
-				return str.GetStories("ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "adbca278-7e5c-4831-bf90-15fadfda0dd1")
+				return str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "adbca278-7e5c-4831-bf90-15fadfda0dd1")
 			},
 			expectedResult: func() []model.Story {
 				return []model.Story{}
@@ -186,7 +189,7 @@ func TestGetStories(t *testing.T) {
 		{
 			name: "test return error when id is not valid uuid",
 			actualResult: func() ([]model.Story, error) {
-				return str.GetStories("ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "abc")
+				return str.GetStories(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", "abc")
 			},
 			expectedResult: func() []model.Story {
 				return []model.Story{}
@@ -240,10 +243,10 @@ func TestStoriesStoreUpdateStory(t *testing.T) {
 
 				require.NoError(t, err)
 
-				id, err := str.AddStory(st)
+				id, err := str.AddStory(context.Background(), st)
 				require.NoError(t, err)
 
-				res, err := str.GetStories(id)
+				res, err := str.GetStories(context.Background(), id)
 				require.NoError(t, err)
 
 				st = &res[0]
@@ -256,7 +259,7 @@ func TestStoriesStoreUpdateStory(t *testing.T) {
 					st.UpVote()
 				}
 
-				c, err := str.UpdateStory(st)
+				c, err := str.UpdateStory(context.Background(), st, time.Time{})
 
 				truncate(t, db)
 
@@ -294,7 +297,7 @@ func TestStoriesStoreUpdateStory(t *testing.T) {
 
 				st.ID = "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a"
 
-				c, err := str.UpdateStory(st)
+				c, err := str.UpdateStory(context.Background(), st, time.Time{})
 
 				return st, c, err
 			},
@@ -350,10 +353,10 @@ func TestStoriesStoreDeleteStory(t *testing.T) {
 
 				require.NoError(t, err)
 
-				id, err := str.AddStory(st)
+				id, err := str.AddStory(context.Background(), st)
 				require.NoError(t, err)
 
-				c, err := str.DeleteStory(id)
+				c, err := str.DeleteStory(context.Background(), id, time.Time{})
 
 				truncate(t, db)
 
@@ -364,7 +367,7 @@ func TestStoriesStoreDeleteStory(t *testing.T) {
 		{
 			name: "test delete story return error when story is not present",
 			actualResult: func() (int64, error) {
-				return str.DeleteStory("ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a")
+				return str.DeleteStory(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", time.Time{})
 			},
 			expectedCount: 0,
 			expectedError: errors.New("failed to delete story"),
@@ -407,7 +410,7 @@ func TestStoriesStoreGetMostViewsStories(t *testing.T) {
 			addViews(st, vc)
 		}
 
-		_, err = str.AddStory(st)
+		_, err = str.AddStory(context.Background(), st)
 		require.NoError(t, err)
 	}
 
@@ -425,7 +428,7 @@ func TestStoriesStoreGetMostViewsStories(t *testing.T) {
 				createAndAddStory("three", "this is story three", 12, t, str)
 				createAndAddStory("four", "this is story four", 0, t, str)
 
-				stories, err := str.GetMostViewsStories(0, 2)
+				stories, err := str.GetMostViewsStories(context.Background(), 0, 2)
 
 				truncate(t, db)
 
@@ -461,11 +464,11 @@ func TestStoriesStoreGetMostViewsStories(t *testing.T) {
 
 				res := make([]model.Story, 0)
 
-				stories, err := str.GetMostViewsStories(0, 2)
+				stories, err := str.GetMostViewsStories(context.Background(), 0, 2)
 				require.NoError(t, err)
 				res = append(res, stories...)
 
-				stories, err = str.GetMostViewsStories(2, 2)
+				stories, err = str.GetMostViewsStories(context.Background(), 2, 2)
 				require.NoError(t, err)
 				res = append(res, stories...)
 
@@ -510,7 +513,7 @@ func TestStoriesStoreGetMostViewsStories(t *testing.T) {
 		{
 			name: "test return error when no records are present",
 			actualResult: func() ([]model.Story, error) {
-				return str.GetMostViewsStories(0, 2)
+				return str.GetMostViewsStories(context.Background(), 0, 2)
 			},
 			expectedResult: func() []model.Story {
 				return []model.Story{}
@@ -566,7 +569,7 @@ func TestStoriesStoreGetTopRatedStories(t *testing.T) {
 			addUpVotes(st, uc)
 		}
 
-		_, err = str.AddStory(st)
+		_, err = str.AddStory(context.Background(), st)
 		require.NoError(t, err)
 	}
 
@@ -584,7 +587,7 @@ func TestStoriesStoreGetTopRatedStories(t *testing.T) {
 				createAndAddStory("three", "this is story three", 12, t, str)
 				createAndAddStory("four", "this is story four", 0, t, str)
 
-				stories, err := str.GetTopRatedStories(0, 2)
+				stories, err := str.GetTopRatedStories(context.Background(), 0, 2)
 
 				truncate(t, db)
 				return stories, err
@@ -618,11 +621,11 @@ func TestStoriesStoreGetTopRatedStories(t *testing.T) {
 
 				res := make([]model.Story, 0)
 
-				stories, err := str.GetTopRatedStories(0, 2)
+				stories, err := str.GetTopRatedStories(context.Background(), 0, 2)
 				require.NoError(t, err)
 				res = append(res, stories...)
 
-				stories, err = str.GetTopRatedStories(2, 2)
+				stories, err = str.GetTopRatedStories(context.Background(), 2, 2)
 				require.NoError(t, err)
 				res = append(res, stories...)
 
@@ -666,7 +669,7 @@ func TestStoriesStoreGetTopRatedStories(t *testing.T) {
 		{
 			name: "test return error when no records are present",
 			actualResult: func() ([]model.Story, error) {
-				return str.GetTopRatedStories(0, 2)
+				return str.GetTopRatedStories(context.Background(), 0, 2)
 			},
 			expectedResult: func() []model.Story {
 				return []model.Story{}
@@ -702,13 +705,202 @@ func TestStoriesStoreGetTopRatedStories(t *testing.T) {
 	}
 }
 
+func TestStoriesStoreGetDailyStats(t *testing.T) {
+	db := getDB(t)
+	str := store.NewStoriesStore(db)
+
+	today := model.ToDay(time.Now())
+
+	testCases := []struct {
+		name           string
+		actualResult   func() ([]model.DailyStats, error)
+		expectedResult func() []model.DailyStats
+		expectedError  error
+	}{
+		{
+			name: "test daily stats record counter changes from add and update",
+			actualResult: func() ([]model.DailyStats, error) {
+				st, err := model.NewStoryBuilder().
+					SetTitle(100, "one").
+					SetBody(100, "this is a story one").
+					SetViewCount(5).
+					Build()
+
+				require.NoError(t, err)
+
+				id, err := str.AddStory(context.Background(), st)
+				require.NoError(t, err)
+
+				res, err := str.GetStories(context.Background(), id)
+				require.NoError(t, err)
+
+				st = &res[0]
+
+				for i := 0; i < 10; i++ {
+					st.AddView()
+				}
+
+				st.UpVote()
+				st.DownVote()
+
+				_, err = str.UpdateStory(context.Background(), st, time.Time{})
+				require.NoError(t, err)
+
+				stats, err := str.GetDailyStats(context.Background(), id, today.AddDate(0, 0, -1), today)
+
+				truncate(t, db)
+
+				return stats, err
+			},
+			expectedResult: func() []model.DailyStats {
+				return []model.DailyStats{{Day: today, Views: 15, UpVotes: 1, DownVotes: 1}}
+			},
+		},
+		{
+			name: "test daily stats return empty when there is no activity",
+			actualResult: func() ([]model.DailyStats, error) {
+				return str.GetDailyStats(context.Background(), "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", today, today)
+			},
+			expectedResult: func() []model.DailyStats {
+				return nil
+			},
+		},
+		{
+			name: "test daily stats return error when id is not valid uuid",
+			actualResult: func() ([]model.DailyStats, error) {
+				return str.GetDailyStats(context.Background(), "abc", today, today)
+			},
+			expectedResult: func() []model.DailyStats {
+				return nil
+			},
+			expectedError: errors.New("invalid uuid abc"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			res, err := testCase.actualResult()
+			expRes := testCase.expectedResult()
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, len(expRes), len(res))
+
+			for i := range res {
+				assert.True(t, isValidUUID(res[i].GetStoryID()))
+				assert.Equal(t, expRes[i].GetDay(), res[i].GetDay())
+				assert.Equal(t, expRes[i].GetViews(), res[i].GetViews())
+				assert.Equal(t, expRes[i].GetUpVotes(), res[i].GetUpVotes())
+				assert.Equal(t, expRes[i].GetDownVotes(), res[i].GetDownVotes())
+			}
+		})
+	}
+}
+
+func TestStoriesStoreGetTopMovers(t *testing.T) {
+	db := getDB(t)
+	str := store.NewStoriesStore(db)
+
+	today := model.ToDay(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+
+	addStory := func(title string, views int64) string {
+		st, err := model.NewStoryBuilder().
+			SetTitle(100, title).
+			SetBody(100, "this is a body").
+			Build()
+
+		require.NoError(t, err)
+
+		id, err := str.AddStory(context.Background(), st)
+		require.NoError(t, err)
+
+		_, err = db.Exec(`INSERT INTO story_daily_stats (storyId, day, views) VALUES ($1, $2, $3)`, id, yesterday, views)
+		require.NoError(t, err)
+
+		return id
+	}
+
+	addViews := func(id string, views int) {
+		res, err := str.GetStories(context.Background(), id)
+		require.NoError(t, err)
+
+		for i := 0; i < views; i++ {
+			res[0].AddView()
+		}
+
+		_, err = str.UpdateStory(context.Background(), &res[0], time.Time{})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name           string
+		actualResult   func() ([]string, []model.Mover, error)
+		expectedResult []model.Mover
+		expectedError  error
+	}{
+		{
+			name: "test get top movers orders by delta",
+			actualResult: func() ([]string, []model.Mover, error) {
+				one := addStory("one", 10)
+				two := addStory("two", 2)
+
+				addViews(one, 5)
+				addViews(two, 20)
+
+				movers, err := str.GetTopMovers(context.Background(), model.MetricViews, yesterday, yesterday, today, today, 10)
+
+				truncate(t, db)
+
+				return []string{two, one}, movers, err
+			},
+			expectedResult: []model.Mover{
+				{Previous: 2, Current: 20},
+				{Previous: 10, Current: 5},
+			},
+		},
+		{
+			name: "test get top movers return error when metric is invalid",
+			actualResult: func() ([]string, []model.Mover, error) {
+				movers, err := str.GetTopMovers(context.Background(), model.Metric("likes"), yesterday, yesterday, today, today, 10)
+				return nil, movers, err
+			},
+			expectedError: errors.New("invalid metric likes"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ids, res, err := testCase.actualResult()
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, len(testCase.expectedResult), len(res))
+
+			for i := range res {
+				assert.Equal(t, ids[i], res[i].GetStoryID())
+				assert.Equal(t, testCase.expectedResult[i].GetPrevious(), res[i].GetPrevious())
+				assert.Equal(t, testCase.expectedResult[i].GetCurrent(), res[i].GetCurrent())
+			}
+		})
+	}
+}
+
 func isValidUUID(uuid string) bool {
 	r := regexp.MustCompile("^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$")
 	return r.MatchString(uuid)
 }
 
 func truncate(t *testing.T, db *sql.DB) {
-	_, err := db.Exec(`TRUNCATE stories`)
+	_, err := db.Exec(`TRUNCATE stories CASCADE`)
 	require.NoError(t, err)
 }
 
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"time"
+)
+
+const (
+	txRetryBackoff      = 10 * time.Millisecond
+	defaultTxMaxRetries = 3
+)
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                 sql.LevelDefault,
+	"read_committed":   sql.LevelReadCommitted,
+	"repeatable_read":  sql.LevelRepeatableRead,
+	"serializable":     sql.LevelSerializable,
+	"read_uncommitted": sql.LevelReadUncommitted,
+}
+
+type querier interface {
+	executor
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txOptions struct {
+	isolation  sql.IsolationLevel
+	maxRetries int
+}
+
+// newTxOptions FAILS ON AN UNKNOWN ISOLATION LEVEL RATHER THAN QUIETLY RUNNING WITH THE DATABASE DEFAULT
+func newTxOptions(isolationLevel string, maxRetries int) (txOptions, error) {
+	isolation, ok := isolationLevels[isolationLevel]
+	if !ok {
+		return txOptions{}, liberr.WithArgs(liberr.Operation("newTxOptions"), liberr.ValidationError, liberr.SeverityError, fmt.Errorf("invalid transaction isolation level %s", isolationLevel))
+	}
+
+	return txOptions{isolation: isolation, maxRetries: maxRetries}, nil
+}
+
+// runInTx COMMITS WHEN fn SUCCEEDS AND ROLLS BACK OTHERWISE, THE ERROR FROM fn IS RETURNED AS IS
+func runInTx(ctx context.Context, op string, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return translateError(op+".db.Begin", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return translateError(op+".tx.Commit", err)
+	}
+
+	return nil
+}
+
+// retryTx RERUNS THE WHOLE TRANSACTION WHEN THE DATABASE ABORTED IT TO KEEP IT SERIALIZABLE,
+// THE CALLBACK PASSED TO WithTx MUST THEREFORE BE SAFE TO RUN MORE THAN ONCE
+func retryTx(ctx context.Context, maxRetries int, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * txRetryBackoff):
+		}
+	}
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRetryTx(t *testing.T) {
+	testCases := map[string]struct {
+		err           error
+		maxRetries    int
+		expectedCalls int
+	}{
+		"test serialization failure is retried until max retries": {
+			err:           liberr.WithArgs(liberr.Operation("StoriesStore.WithTx.tx.Commit"), &pq.Error{Code: "40001"}),
+			maxRetries:    2,
+			expectedCalls: 3,
+		},
+		"test deadlock is retried": {
+			err:           &pq.Error{Code: "40P01"},
+			maxRetries:    1,
+			expectedCalls: 2,
+		},
+		"test busy sqlite database is retried": {
+			err:           sqlite3.Error{Code: sqlite3.ErrBusy},
+			maxRetries:    1,
+			expectedCalls: 2,
+		},
+		"test other errors are not retried": {
+			err:           &pq.Error{Code: "23505"},
+			maxRetries:    3,
+			expectedCalls: 1,
+		},
+		"test success is not retried": {
+			maxRetries:    3,
+			expectedCalls: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+
+			err := retryTx(context.Background(), testCase.maxRetries, func() error {
+				calls++
+				return testCase.err
+			})
+
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, testCase.expectedCalls, calls)
+		})
+	}
+}
+
+func TestRetryTxStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := retryTx(ctx, 3, func() error {
+		calls++
+		return &pq.Error{Code: "40001"}
+	})
+
+	assert.True(t, errors.As(err, new(*pq.Error)))
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewTxOptions(t *testing.T) {
+	testCases := map[string]struct {
+		isolationLevel    string
+		expectedIsolation sql.IsolationLevel
+		expectedError     bool
+	}{
+		"test known isolation level": {
+			isolationLevel:    "serializable",
+			expectedIsolation: sql.LevelSerializable,
+		},
+		"test empty isolation level uses the database default": {
+			isolationLevel:    "",
+			expectedIsolation: sql.LevelDefault,
+		},
+		"test unknown isolation level is rejected": {
+			isolationLevel: "serialisable",
+			expectedError:  true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			opts, err := newTxOptions(testCase.isolationLevel, 3)
+
+			if testCase.expectedError {
+				assert.Error(t, err)
+				assert.Equal(t, liberr.ValidationError, err.(*liberr.Error).Kind())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, txOptions{isolation: testCase.expectedIsolation, maxRetries: 3}, opts)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+// CounterDelta IS AN INCREMENT TO THE COUNTERS OF A STORY, AS OPPOSED TO THE ABSOLUTE VALUES SET BY AN UPDATE
+type CounterDelta struct {
+	StoryID   string
+	Views     int64
+	UpVotes   int64
+	DownVotes int64
+}
+
+func (cd CounterDelta) IsZero() bool {
+	return cd.Views == 0 && cd.UpVotes == 0 && cd.DownVotes == 0
+}
+
+func (cd *CounterDelta) Merge(other CounterDelta) {
+	cd.Views += other.Views
+	cd.UpVotes += other.UpVotes
+	cd.DownVotes += other.DownVotes
+}
+
+// MergeDeltas SUMS THE DELTAS OF THE SAME STORY AND DROPS THE ONES THAT CANCEL OUT, THE ORDER OF
+// THE FIRST OCCURRENCE OF EVERY STORY IS KEPT
+func MergeDeltas(deltas ...CounterDelta) []CounterDelta {
+	index := make(map[string]int, len(deltas))
+
+	var merged []CounterDelta
+	for _, d := range deltas {
+		i, ok := index[d.StoryID]
+		if !ok {
+			index[d.StoryID] = len(merged)
+			merged = append(merged, d)
+			continue
+		}
+
+		merged[i].Merge(d)
+	}
+
+	res := merged[:0]
+	for _, d := range merged {
+		if !d.IsZero() {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
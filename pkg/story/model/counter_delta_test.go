@@ -0,0 +1,51 @@
+package model_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergeDeltas(t *testing.T) {
+	testCases := map[string]struct {
+		deltas         []model.CounterDelta
+		expectedResult []model.CounterDelta
+	}{
+		"test merge deltas of the same story": {
+			deltas: []model.CounterDelta{
+				{StoryID: "one", Views: 1},
+				{StoryID: "two", UpVotes: 1},
+				{StoryID: "one", Views: 2, DownVotes: 1},
+			},
+			expectedResult: []model.CounterDelta{
+				{StoryID: "one", Views: 3, DownVotes: 1},
+				{StoryID: "two", UpVotes: 1},
+			},
+		},
+		"test drop deltas that cancel out": {
+			deltas: []model.CounterDelta{
+				{StoryID: "one", UpVotes: 1},
+				{StoryID: "two", Views: 1},
+				{StoryID: "one", UpVotes: -1},
+			},
+			expectedResult: []model.CounterDelta{
+				{StoryID: "two", Views: 1},
+			},
+		},
+		"test no deltas": {
+			expectedResult: []model.CounterDelta{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			res := model.MergeDeltas(testCase.deltas...)
+			if len(testCase.expectedResult) == 0 {
+				assert.Empty(t, res)
+				return
+			}
+
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+)
+
+type Metric string
+
+const (
+	MetricViews     Metric = "views"
+	MetricUpVotes   Metric = "up_votes"
+	MetricDownVotes Metric = "down_votes"
+)
+
+func (m Metric) IsValid() bool {
+	switch m {
+	case MetricViews, MetricUpVotes, MetricDownVotes:
+		return true
+	default:
+		return false
+	}
+}
+
+//TODO: FIELDS ARE EXPORTED FOR DATABASE OPERATIONS, FIND A WAY TO NOT EXPORT THEM
+type DailyStats struct {
+	StoryID   string
+	Day       time.Time
+	Views     int64
+	UpVotes   int64
+	DownVotes int64
+}
+
+func (ds *DailyStats) GetStoryID() string {
+	return ds.StoryID
+}
+
+func (ds *DailyStats) GetDay() time.Time {
+	return ds.Day
+}
+
+func (ds *DailyStats) GetViews() int64 {
+	return ds.Views
+}
+
+func (ds *DailyStats) GetUpVotes() int64 {
+	return ds.UpVotes
+}
+
+func (ds *DailyStats) GetDownVotes() int64 {
+	return ds.DownVotes
+}
+
+type Mover struct {
+	StoryID  string
+	Previous int64
+	Current  int64
+}
+
+func (m *Mover) GetStoryID() string {
+	return m.StoryID
+}
+
+func (m *Mover) GetPrevious() int64 {
+	return m.Previous
+}
+
+func (m *Mover) GetCurrent() int64 {
+	return m.Current
+}
+
+func (m *Mover) GetDelta() int64 {
+	return m.Current - m.Previous
+}
+
+// ToDay TRUNCATES THE GIVEN TIME TO THE START OF ITS UTC DAY
+func ToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
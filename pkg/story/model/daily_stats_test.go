@@ -0,0 +1,60 @@
+package model_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetricIsValid(t *testing.T) {
+	testCases := map[string]struct {
+		metric         model.Metric
+		expectedResult bool
+	}{
+		"test views is valid":      {metric: model.MetricViews, expectedResult: true},
+		"test up votes is valid":   {metric: model.MetricUpVotes, expectedResult: true},
+		"test down votes is valid": {metric: model.MetricDownVotes, expectedResult: true},
+		"test unknown is invalid":  {metric: model.Metric("likes"), expectedResult: false},
+		"test empty is invalid":    {metric: model.Metric(""), expectedResult: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedResult, testCase.metric.IsValid())
+		})
+	}
+}
+
+func TestMoverGetDelta(t *testing.T) {
+	m := model.Mover{StoryID: "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", Previous: 25, Current: 10}
+
+	assert.Equal(t, "ced5aa3b-b39a-4da4-b8bf-d03e3c8daa7a", m.GetStoryID())
+	assert.Equal(t, int64(25), m.GetPrevious())
+	assert.Equal(t, int64(10), m.GetCurrent())
+	assert.Equal(t, int64(-15), m.GetDelta())
+}
+
+func TestToDay(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	testCases := map[string]struct {
+		input          time.Time
+		expectedResult time.Time
+	}{
+		"test to day truncates time": {
+			input:          time.Date(2020, 07, 29, 16, 30, 10, 5, time.UTC),
+			expectedResult: time.Date(2020, 07, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"test to day converts to utc before truncating": {
+			input:          time.Date(2020, 07, 29, 2, 0, 0, 0, ist),
+			expectedResult: time.Date(2020, 07, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedResult, model.ToDay(testCase.input))
+		})
+	}
+}
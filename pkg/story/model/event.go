@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventStoryCreated EventType = "story.created"
+	EventStoryUpdated EventType = "story.updated"
+	EventStoryDeleted EventType = "story.deleted"
+	EventStoryVoted   EventType = "story.voted"
+)
+
+// Event IS A CHANGE TO A STORY AS WRITTEN TO THE OUTBOX, ID IS ASSIGNED BY THE STORE AND GROWS WITH EVERY
+// EVENT SO IT GIVES THE ORDER IN WHICH THE EVENTS OF A STORY HAPPENED
+type Event struct {
+	ID        int64           `json:"id"`
+	StoryID   string          `json:"storyId"`
+	Type      EventType       `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type storyPayload struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	ViewCount int64  `json:"viewCount"`
+	UpVotes   int64  `json:"upVotes"`
+	DownVotes int64  `json:"downVotes"`
+}
+
+type deletedPayload struct {
+	ID string `json:"id"`
+}
+
+// THE VOTES ARE THE INCREMENTS, NOT THE NEW TOTALS
+type votedPayload struct {
+	ID        string `json:"id"`
+	UpVotes   int64  `json:"upVotes"`
+	DownVotes int64  `json:"downVotes"`
+}
+
+func (e Event) GetID() int64 {
+	return e.ID
+}
+
+func (e Event) GetStoryID() string {
+	return e.StoryID
+}
+
+func (e Event) GetType() EventType {
+	return e.Type
+}
+
+func NewStoryCreatedEvent(st Story) Event {
+	return newEvent(st.GetID(), EventStoryCreated, newStoryPayload(st))
+}
+
+func NewStoryUpdatedEvent(st Story) Event {
+	return newEvent(st.GetID(), EventStoryUpdated, newStoryPayload(st))
+}
+
+func NewStoryDeletedEvent(storyID string) Event {
+	return newEvent(storyID, EventStoryDeleted, deletedPayload{ID: storyID})
+}
+
+func NewStoryVotedEvent(delta CounterDelta) Event {
+	return newEvent(delta.StoryID, EventStoryVoted, votedPayload{ID: delta.StoryID, UpVotes: delta.UpVotes, DownVotes: delta.DownVotes})
+}
+
+// NewStoryVotedEvents SKIPS THE DELTAS THAT ONLY CHANGE THE VIEWS
+func NewStoryVotedEvents(deltas ...CounterDelta) []Event {
+	var events []Event
+
+	for _, d := range deltas {
+		if d.UpVotes != 0 || d.DownVotes != 0 {
+			events = append(events, NewStoryVotedEvent(d))
+		}
+	}
+
+	return events
+}
+
+func newStoryPayload(st Story) storyPayload {
+	return storyPayload{
+		ID:        st.GetID(),
+		Title:     st.GetTitle(),
+		Body:      st.GetBody(),
+		ViewCount: st.GetViewCount(),
+		UpVotes:   st.GetUpVotes(),
+		DownVotes: st.GetDownVotes(),
+	}
+}
+
+// THE PAYLOADS ARE PLAIN STRUCTS OF STRINGS AND NUMBERS, MARSHALLING THEM CANNOT FAIL
+func newEvent(storyID string, eventType EventType, payload interface{}) Event {
+	data, _ := json.Marshal(payload)
+
+	return Event{
+		StoryID: storyID,
+		Type:    eventType,
+		Payload: data,
+	}
+}
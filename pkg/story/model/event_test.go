@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewEvents(t *testing.T) {
+	st := model.Story{ID: "one", Title: "title", Body: "body", ViewCount: 1, UpVotes: 2, DownVotes: 3}
+
+	testCases := map[string]struct {
+		event           model.Event
+		expectedType    model.EventType
+		expectedPayload string
+	}{
+		"test story created event": {
+			event:           model.NewStoryCreatedEvent(st),
+			expectedType:    model.EventStoryCreated,
+			expectedPayload: `{"id":"one","title":"title","body":"body","viewCount":1,"upVotes":2,"downVotes":3}`,
+		},
+		"test story updated event": {
+			event:           model.NewStoryUpdatedEvent(st),
+			expectedType:    model.EventStoryUpdated,
+			expectedPayload: `{"id":"one","title":"title","body":"body","viewCount":1,"upVotes":2,"downVotes":3}`,
+		},
+		"test story deleted event": {
+			event:           model.NewStoryDeletedEvent("one"),
+			expectedType:    model.EventStoryDeleted,
+			expectedPayload: `{"id":"one"}`,
+		},
+		"test story voted event": {
+			event:           model.NewStoryVotedEvent(model.CounterDelta{StoryID: "one", Views: 5, UpVotes: 1, DownVotes: -1}),
+			expectedType:    model.EventStoryVoted,
+			expectedPayload: `{"id":"one","upVotes":1,"downVotes":-1}`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "one", testCase.event.GetStoryID())
+			assert.Equal(t, testCase.expectedType, testCase.event.GetType())
+			assert.JSONEq(t, testCase.expectedPayload, string(testCase.event.Payload))
+		})
+	}
+}
+
+func TestNewStoryVotedEventsSkipsViews(t *testing.T) {
+	events := model.NewStoryVotedEvents(
+		model.CounterDelta{StoryID: "one", Views: 1},
+		model.CounterDelta{StoryID: "two", UpVotes: 1},
+		model.CounterDelta{StoryID: "three", Views: 2, DownVotes: 1},
+	)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, "two", events[0].GetStoryID())
+	assert.Equal(t, "three", events[1].GetStoryID())
+}
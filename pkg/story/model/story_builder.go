@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -22,17 +23,12 @@ type StoryBuilder struct {
 	createdAt time.Time
 	updatedAt time.Time
 
-	err error
+	violations []liberr.FieldViolation
 }
 
 func (b *StoryBuilder) SetID(id string) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	if !isValidUUID(id) {
-		b.err = fmt.Errorf("invalid id: %s", id)
-		return b
+		return b.violate("id", liberr.CodeInvalidStoryID, fmt.Sprintf("invalid id: %s", id))
 	}
 
 	b.id = id
@@ -41,20 +37,14 @@ func (b *StoryBuilder) SetID(id string) *StoryBuilder {
 }
 
 func (b *StoryBuilder) SetTitle(maxLength int, title string) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	sz := len(title)
 
 	if sz == 0 {
-		b.err = errors.New("title cannot be empty")
-		return b
+		return b.violate("title", liberr.CodeTitleRequired, "title cannot be empty")
 	}
 
 	if sz > maxLength {
-		b.err = errors.New("title max length exceeded")
-		return b
+		return b.violate("title", liberr.CodeTitleTooLong, "title max length exceeded")
 	}
 
 	b.title = title
@@ -62,20 +52,14 @@ func (b *StoryBuilder) SetTitle(maxLength int, title string) *StoryBuilder {
 }
 
 func (b *StoryBuilder) SetBody(maxLength int, body string) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	sz := len(body)
 
 	if sz == 0 {
-		b.err = errors.New("body cannot be empty")
-		return b
+		return b.violate("body", liberr.CodeBodyRequired, "body cannot be empty")
 	}
 
 	if sz > maxLength {
-		b.err = errors.New("body max length exceeded")
-		return b
+		return b.violate("body", liberr.CodeBodyTooLong, "body max length exceeded")
 	}
 
 	b.body = body
@@ -83,53 +67,47 @@ func (b *StoryBuilder) SetBody(maxLength int, body string) *StoryBuilder {
 }
 
 func (b *StoryBuilder) SetViewCount(viewCount int64) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	b.viewCount = viewCount
 	return b
 }
 
 func (b *StoryBuilder) SetUpVotes(upVotes int64) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	b.upVotes = upVotes
 	return b
 }
 
 func (b *StoryBuilder) SetDownVotes(downVotes int64) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	b.downVotes = downVotes
 	return b
 }
 
 func (b *StoryBuilder) SetCreatedAt(createdAt time.Time) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	b.createdAt = createdAt
 	return b
 }
 
 func (b *StoryBuilder) SetUpdatedAt(updatedAt time.Time) *StoryBuilder {
-	if b.err != nil {
-		return b
-	}
-
 	b.updatedAt = updatedAt
 	return b
 }
 
+// Build REPORTS EVERY FIELD THAT FAILED VALIDATION, THE MESSAGE OF THE ERROR JOINS THEIR MESSAGES
+// AND ITS CODE IS THE CODE OF THE FIRST VIOLATION
 func (b *StoryBuilder) Build() (*Story, error) {
-	if b.err != nil {
-		return nil, liberr.WithArgs(liberr.SeverityError, liberr.ValidationError, liberr.Operation("StoryBuilder.Build"), b.err)
+	if len(b.violations) != 0 {
+		messages := make([]string, len(b.violations))
+		for i, v := range b.violations {
+			messages[i] = v.Message
+		}
+
+		return nil, liberr.WithArgs(
+			liberr.SeverityError,
+			liberr.ValidationError,
+			liberr.Operation("StoryBuilder.Build"),
+			b.violations[0].Code,
+			b.violations,
+			errors.New(strings.Join(messages, "; ")),
+		)
 	}
 
 	// TODO: FIX WHEN BUILD IS CALLED WITHOUT INVOKING SET TITLE AND SET BODY
@@ -145,6 +123,11 @@ func (b *StoryBuilder) Build() (*Story, error) {
 	}, nil
 }
 
+func (b *StoryBuilder) violate(field string, code liberr.Code, message string) *StoryBuilder {
+	b.violations = append(b.violations, liberr.NewFieldViolation(field, code, message))
+	return b
+}
+
 func isValidUUID(uuid string) bool {
 	return regexp.MustCompile(uuidRegex).MatchString(uuid)
 }
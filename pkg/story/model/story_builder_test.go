@@ -2,8 +2,10 @@ package model_test
 
 import (
 	"errors"
+	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -109,3 +111,24 @@ func TestCreateNewStory(t *testing.T) {
 		})
 	}
 }
+
+func TestStoryBuilderReportsEveryViolation(t *testing.T) {
+	_, err := model.NewStoryBuilder().
+		SetID("invalid").
+		SetTitle(10, "this is a very long title").
+		SetBody(10000, "").
+		Build()
+
+	require.Error(t, err)
+	assert.Equal(t, "invalid id: invalid; title max length exceeded; body cannot be empty", err.Error())
+
+	var libErr *liberr.Error
+	require.True(t, errors.As(err, &libErr))
+
+	assert.Equal(t, liberr.ValidationError, libErr.Kind())
+	assert.Equal(t, []liberr.FieldViolation{
+		liberr.NewFieldViolation("id", liberr.CodeInvalidStoryID, "invalid id: invalid"),
+		liberr.NewFieldViolation("title", liberr.CodeTitleTooLong, "title max length exceeded"),
+		liberr.NewFieldViolation("body", liberr.CodeBodyRequired, "body cannot be empty"),
+	}, libErr.FieldViolations())
+}
@@ -0,0 +1,22 @@
+package model
+
+// StoryPatch CHANGES THE TITLE AND THE BODY OF A STORY, A NIL FIELD KEEPS ITS CURRENT VALUE. UNLIKE AN UPDATE
+// IT NEVER TOUCHES THE COUNTERS SO THE VIEWS AND VOTES RECORDED WHILE IT WAS BEING MADE ARE KEPT
+type StoryPatch struct {
+	ID    string
+	Title *string
+	Body  *string
+}
+
+// Apply RETURNS THE STORY WITH THE FIELDS OF THE PATCH THAT ARE SET
+func (sp StoryPatch) Apply(st Story) Story {
+	if sp.Title != nil {
+		st.Title = *sp.Title
+	}
+
+	if sp.Body != nil {
+		st.Body = *sp.Body
+	}
+
+	return st
+}
@@ -0,0 +1,37 @@
+package model_test
+
+import (
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStoryPatchApply(t *testing.T) {
+	title, body := "new title", "new body"
+
+	story := model.Story{ID: "one", Title: "title", Body: "body", ViewCount: 4, UpVotes: 2, DownVotes: 1}
+
+	testCases := map[string]struct {
+		patch          model.StoryPatch
+		expectedResult model.Story
+	}{
+		"test apply changes the title only": {
+			patch:          model.StoryPatch{ID: "one", Title: &title},
+			expectedResult: model.Story{ID: "one", Title: title, Body: "body", ViewCount: 4, UpVotes: 2, DownVotes: 1},
+		},
+		"test apply changes the body only": {
+			patch:          model.StoryPatch{ID: "one", Body: &body},
+			expectedResult: model.Story{ID: "one", Title: "title", Body: body, ViewCount: 4, UpVotes: 2, DownVotes: 1},
+		},
+		"test apply changes the title and the body": {
+			patch:          model.StoryPatch{ID: "one", Title: &title, Body: &body},
+			expectedResult: model.Story{ID: "one", Title: title, Body: body, ViewCount: 4, UpVotes: 2, DownVotes: 1},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedResult, testCase.patch.Apply(story))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/cache"
+	"github.com/nsnikhil/stories/pkg/config"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"time"
+)
+
+const (
+	storyCache      = "story"
+	mostViewedCache = "most_viewed"
+	topRatedCache   = "top_rated"
+)
+
+// cachedStoriesService IS A READ THROUGH CACHE OVER A StoryService, STORIES ARE CACHED BY ID AND THE
+// RANKED LISTS BY PAGE. A WRITE EVICTS THE STORY AND DROPS EVERY PAGE SINCE THE RANKING MAY HAVE CHANGED.
+type cachedStoriesService struct {
+	StoryService
+
+	cfg        config.CacheConfig
+	prometheus reporters.Prometheus
+
+	stories cache.Cache
+	pages   cache.Cache
+}
+
+func (css *cachedStoriesService) AddStory(ctx context.Context, story *model.Story) error {
+	if err := css.StoryService.AddStory(ctx, story); err != nil {
+		return err
+	}
+
+	css.pages.Purge()
+	return nil
+}
+
+func (css *cachedStoriesService) GetStory(ctx context.Context, storyID string) (*model.Story, error) {
+	if v, ok := css.stories.Get(storyID); ok {
+		css.prometheus.ReportCacheHit(storyCache)
+
+		st := v.(model.Story)
+		return &st, nil
+	}
+
+	css.prometheus.ReportCacheMiss(storyCache)
+
+	st, err := css.StoryService.GetStory(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	css.stories.Set(storyID, *st, css.cfg.StoryTTL())
+	return st, nil
+}
+
+func (css *cachedStoriesService) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	c, err := css.StoryService.UpdateStory(ctx, story, ifUpdatedAt)
+
+	css.invalidate(story.GetID())
+
+	return c, err
+}
+
+func (css *cachedStoriesService) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	c, err := css.StoryService.PatchStory(ctx, patch, ifUpdatedAt)
+
+	css.invalidate(patch.ID)
+
+	return c, err
+}
+
+func (css *cachedStoriesService) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	c, err := css.StoryService.DeleteStory(ctx, storyID, ifUpdatedAt)
+
+	css.invalidate(storyID)
+
+	return c, err
+}
+
+func (css *cachedStoriesService) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return css.getPage(ctx, mostViewedCache, offset, limit, css.cfg.MostViewedTTL(), css.StoryService.GetMostViewsStories)
+}
+
+func (css *cachedStoriesService) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	return css.getPage(ctx, topRatedCache, offset, limit, css.cfg.TopRatedTTL(), css.StoryService.GetTopRatedStories)
+}
+
+func (css *cachedStoriesService) getPage(ctx context.Context, name string, offset, limit int, ttl time.Duration, load func(ctx context.Context, offset, limit int) ([]model.Story, error)) ([]model.Story, error) {
+	key := fmt.Sprintf("%s:%d:%d", name, offset, limit)
+
+	if v, ok := css.pages.Get(key); ok {
+		css.prometheus.ReportCacheHit(name)
+		return copyStories(v.([]model.Story)), nil
+	}
+
+	css.prometheus.ReportCacheMiss(name)
+
+	stories, err := load(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	css.pages.Set(key, copyStories(stories), ttl)
+	return stories, nil
+}
+
+// EVICT EVEN WHEN THE WRITE FAILS, THE CALLER MAY HAVE HIT A PARTIAL FAILURE
+func (css *cachedStoriesService) invalidate(storyID string) {
+	css.stories.Delete(storyID)
+	css.pages.Purge()
+}
+
+// CALLERS ARE FREE TO MUTATE WHAT THEY GET BACK, NEVER HAND OUT THE CACHED SLICE
+func copyStories(stories []model.Story) []model.Story {
+	res := make([]model.Story, len(stories))
+	copy(res, stories)
+	return res
+}
+
+func NewCachedStoriesService(svc StoryService, cfg config.CacheConfig, prometheus reporters.Prometheus) StoryService {
+	return &cachedStoriesService{
+		StoryService: svc,
+		cfg:          cfg,
+		prometheus:   prometheus,
+		stories:      cache.NewLRUCache(cfg.Size()),
+		pages:        cache.NewLRUCache(cfg.Size()),
+	}
+}
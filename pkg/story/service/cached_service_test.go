@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	reporters "github.com/nsnikhil/stories/pkg/reporting"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const cachedStoryID = "a45c9dac-56dc-4771-a3f4-f10ad30a20a5"
+
+func newCachedService(svc service.StoryService, pr reporters.Prometheus) service.StoryService {
+	cfg := config.NewConfig("../../../local.env").CacheConfig()
+	return service.NewCachedStoriesService(svc, cfg, pr)
+}
+
+func TestCachedStoryServiceGetStory(t *testing.T) {
+	st := &model.Story{ID: cachedStoryID, Title: "title", Body: "body"}
+
+	mss := &service.MockStoriesService{}
+	mss.On("GetStory", mock.Anything, cachedStoryID).Return(st, nil).Once()
+
+	mpr := &reporters.MockPrometheus{}
+	mpr.On("ReportCacheMiss", "story").Once()
+	mpr.On("ReportCacheHit", "story").Twice()
+
+	svc := newCachedService(mss, mpr)
+
+	for i := 0; i < 3; i++ {
+		res, err := svc.GetStory(context.Background(), cachedStoryID)
+		require.NoError(t, err)
+		assert.Equal(t, model.Story{ID: cachedStoryID, Title: "title", Body: "body"}, *res)
+
+		res.AddView()
+	}
+
+	mss.AssertExpectations(t)
+	mpr.AssertExpectations(t)
+}
+
+func TestCachedStoryServiceGetStoryFailureIsNotCached(t *testing.T) {
+	mss := &service.MockStoriesService{}
+	mss.On("GetStory", mock.Anything, cachedStoryID).Return(&model.Story{}, liberr.WithArgs(errors.New("no records found"))).Twice()
+
+	mpr := &reporters.MockPrometheus{}
+	mpr.On("ReportCacheMiss", "story").Twice()
+
+	svc := newCachedService(mss, mpr)
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetStory(context.Background(), cachedStoryID)
+		assert.Equal(t, "no records found", err.Error())
+	}
+
+	mss.AssertExpectations(t)
+	mpr.AssertExpectations(t)
+}
+
+func TestCachedStoryServiceGetPages(t *testing.T) {
+	newStories := func() []model.Story {
+		return []model.Story{{ID: cachedStoryID, Title: "title", Body: "body"}}
+	}
+
+	testCases := map[string]struct {
+		method string
+		cache  string
+		call   func(svc service.StoryService, offset, limit int) ([]model.Story, error)
+	}{
+		"test most viewed stories are cached per page": {
+			method: "GetMostViewsStories",
+			cache:  "most_viewed",
+			call: func(svc service.StoryService, offset, limit int) ([]model.Story, error) {
+				return svc.GetMostViewsStories(context.Background(), offset, limit)
+			},
+		},
+		"test top rated stories are cached per page": {
+			method: "GetTopRatedStories",
+			cache:  "top_rated",
+			call: func(svc service.StoryService, offset, limit int) ([]model.Story, error) {
+				return svc.GetTopRatedStories(context.Background(), offset, limit)
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mss := &service.MockStoriesService{}
+			mss.On(testCase.method, mock.Anything, 0, 10).Return(newStories(), nil).Once()
+			mss.On(testCase.method, mock.Anything, 10, 10).Return(newStories(), nil).Once()
+
+			mpr := &reporters.MockPrometheus{}
+			mpr.On("ReportCacheMiss", testCase.cache).Twice()
+			mpr.On("ReportCacheHit", testCase.cache).Once()
+
+			svc := newCachedService(mss, mpr)
+
+			for _, offset := range []int{0, 0, 10} {
+				res, err := testCase.call(svc, offset, 10)
+				require.NoError(t, err)
+				assert.Equal(t, newStories(), res)
+
+				res[0].Title = "changed"
+			}
+
+			mss.AssertExpectations(t)
+			mpr.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCachedStoryServiceInvalidation(t *testing.T) {
+	st := &model.Story{ID: cachedStoryID, Title: "title", Body: "body"}
+	stories := []model.Story{*st}
+
+	testCases := map[string]struct {
+		write func(svc service.StoryService)
+		mock  func(mss *service.MockStoriesService)
+	}{
+		"test update story invalidates the cache": {
+			write: func(svc service.StoryService) { _, _ = svc.UpdateStory(context.Background(), st, time.Time{}) },
+			mock: func(mss *service.MockStoriesService) {
+				mss.On("UpdateStory", mock.Anything, st, time.Time{}).Return(int64(1), nil)
+			},
+		},
+		"test failed update story still invalidates the cache": {
+			write: func(svc service.StoryService) { _, _ = svc.UpdateStory(context.Background(), st, time.Time{}) },
+			mock: func(mss *service.MockStoriesService) {
+				mss.On("UpdateStory", mock.Anything, st, time.Time{}).Return(int64(0), errors.New("failed to update story"))
+			},
+		},
+		"test patch story invalidates the cache": {
+			write: func(svc service.StoryService) {
+				_, _ = svc.PatchStory(context.Background(), model.StoryPatch{ID: cachedStoryID, Title: &st.Title}, time.Time{})
+			},
+			mock: func(mss *service.MockStoriesService) {
+				mss.On("PatchStory", mock.Anything, model.StoryPatch{ID: cachedStoryID, Title: &st.Title}, time.Time{}).Return(int64(1), nil)
+			},
+		},
+		"test delete story invalidates the cache": {
+			write: func(svc service.StoryService) {
+				_, _ = svc.DeleteStory(context.Background(), cachedStoryID, time.Time{})
+			},
+			mock: func(mss *service.MockStoriesService) {
+				mss.On("DeleteStory", mock.Anything, cachedStoryID, time.Time{}).Return(int64(1), nil)
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mss := &service.MockStoriesService{}
+			mss.On("GetStory", mock.Anything, cachedStoryID).Return(st, nil).Twice()
+			mss.On("GetMostViewsStories", mock.Anything, 0, 10).Return(stories, nil).Twice()
+			testCase.mock(mss)
+
+			mpr := &reporters.MockPrometheus{}
+			mpr.On("ReportCacheMiss", mock.Anything)
+			mpr.On("ReportCacheHit", mock.Anything)
+
+			svc := newCachedService(mss, mpr)
+
+			read := func() {
+				_, err := svc.GetStory(context.Background(), cachedStoryID)
+				require.NoError(t, err)
+
+				_, err = svc.GetMostViewsStories(context.Background(), 0, 10)
+				require.NoError(t, err)
+			}
+
+			read()
+			read()
+
+			testCase.write(svc)
+
+			read()
+
+			mss.AssertExpectations(t)
+			mpr.AssertNumberOfCalls(t, "ReportCacheMiss", 4)
+			mpr.AssertNumberOfCalls(t, "ReportCacheHit", 2)
+		})
+	}
+}
+
+func TestCachedStoryServiceAddStoryDropsPages(t *testing.T) {
+	st := &model.Story{Title: "title", Body: "body"}
+	stories := []model.Story{{ID: cachedStoryID, Title: "title", Body: "body"}}
+
+	mss := &service.MockStoriesService{}
+	mss.On("GetTopRatedStories", mock.Anything, 0, 10).Return(stories, nil).Twice()
+	mss.On("AddStory", mock.Anything, st).Return(nil)
+
+	mpr := &reporters.MockPrometheus{}
+	mpr.On("ReportCacheMiss", "top_rated").Twice()
+
+	svc := newCachedService(mss, mpr)
+
+	_, err := svc.GetTopRatedStories(context.Background(), 0, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.AddStory(context.Background(), st))
+
+	_, err = svc.GetTopRatedStories(context.Background(), 0, 10)
+	require.NoError(t, err)
+
+	mss.AssertExpectations(t)
+	mpr.AssertExpectations(t)
+}
@@ -1,45 +1,77 @@
 package service
 
 import (
+	"context"
 	"github.com/nsnikhil/stories/pkg/story/model"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type MockStoriesService struct {
 	mock.Mock
 }
 
-func (mock *MockStoriesService) AddStory(story *model.Story) error {
-	args := mock.Called(story)
+func (mock *MockStoriesService) AddStory(ctx context.Context, story *model.Story) error {
+	args := mock.Called(ctx, story)
 	return args.Error(0)
 }
 
-func (mock *MockStoriesService) GetStory(storyID string) (*model.Story, error) {
-	args := mock.Called(storyID)
+func (mock *MockStoriesService) GetStory(ctx context.Context, storyID string) (*model.Story, error) {
+	args := mock.Called(ctx, storyID)
 	return args.Get(0).(*model.Story), args.Error(1)
 }
 
-func (mock *MockStoriesService) UpdateStory(story *model.Story) (int64, error) {
-	args := mock.Called(story)
+func (mock *MockStoriesService) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, story, ifUpdatedAt)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (mock *MockStoriesService) DeleteStory(storyID string) (int64, error) {
-	args := mock.Called(storyID)
+func (mock *MockStoriesService) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, patch, ifUpdatedAt)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (mock *MockStoriesService) SearchStories(query string) ([]model.Story, error) {
-	args := mock.Called(query)
+func (mock *MockStoriesService) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	args := mock.Called(ctx, storyID, ifUpdatedAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockStoriesService) SearchStories(ctx context.Context, query string) ([]model.Story, error) {
+	args := mock.Called(ctx, query)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
 
-func (mock *MockStoriesService) GetMostViewsStories(offset, limit int) ([]model.Story, error) {
-	args := mock.Called(offset, limit)
+func (mock *MockStoriesService) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	args := mock.Called(ctx, offset, limit)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
 
-func (mock *MockStoriesService) GetTopRatedStories(offset, limit int) ([]model.Story, error) {
-	args := mock.Called(offset, limit)
+func (mock *MockStoriesService) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	args := mock.Called(ctx, offset, limit)
 	return args.Get(0).([]model.Story), args.Error(1)
 }
+
+func (mock *MockStoriesService) ViewStory(ctx context.Context, storyID string) error {
+	args := mock.Called(ctx, storyID)
+	return args.Error(0)
+}
+
+func (mock *MockStoriesService) UpVoteStory(ctx context.Context, storyID string) error {
+	args := mock.Called(ctx, storyID)
+	return args.Error(0)
+}
+
+func (mock *MockStoriesService) DownVoteStory(ctx context.Context, storyID string) error {
+	args := mock.Called(ctx, storyID)
+	return args.Error(0)
+}
+
+func (mock *MockStoriesService) GetStoryTimeSeries(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	args := mock.Called(ctx, storyID, from, to)
+	return args.Get(0).([]model.DailyStats), args.Error(1)
+}
+
+func (mock *MockStoriesService) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	args := mock.Called(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+	return args.Get(0).([]model.Mover), args.Error(1)
+}
@@ -1,23 +1,60 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/store"
 	"github.com/nsnikhil/stories/pkg/story/model"
+	"strings"
+	"time"
+)
+
+const (
+	dayLayout          = "2006-01-02"
+	maxTimeSeriesDays  = 366
+	defaultMoverMetric = model.MetricViews
+
+	// maxTopMovers KEEPS A SINGLE AGGREGATE QUERY FROM RANKING THE WHOLE TABLE
+	maxTopMovers = 100
+)
+
+// THE FIELDS OF THE ANALYTICS REQUESTS THE VIOLATIONS NAME
+const (
+	metricField       = "metric"
+	limitField        = "limit"
+	fromField         = "from"
+	toField           = "to"
+	previousFromField = "previous_from"
+	currentFromField  = "current_from"
 )
 
 type StoryService interface {
-	AddStory(story *model.Story) error
-	GetStory(storyID string) (*model.Story, error)
+	// AddStory SETS THE ID OF THE STORY ONCE IT IS STORED
+	AddStory(ctx context.Context, story *model.Story) error
+	GetStory(ctx context.Context, storyID string) (*model.Story, error)
 
-	UpdateStory(story *model.Story) (int64, error)
-	DeleteStory(storyID string) (int64, error)
+	// A NON ZERO ifUpdatedAt ONLY WRITES THE STORY WHEN IT WAS LAST UPDATED THEN, SEE store.StoriesStore
+	UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error)
 
-	SearchStories(query string) ([]model.Story, error)
+	// PatchStory CHANGES THE TITLE AND THE BODY WITHOUT WRITING BACK THE COUNTERS, UNLIKE UpdateStory
+	PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error)
 
-	GetMostViewsStories(offset, limit int) ([]model.Story, error)
-	GetTopRatedStories(offset, limit int) ([]model.Story, error)
+	DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error)
+
+	SearchStories(ctx context.Context, query string) ([]model.Story, error)
+
+	GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error)
+	GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error)
+
+	// THE COUNTERS ARE WRITTEN BEHIND, A VIEW OR VOTE MAY TAKE UP TO A FLUSH INTERVAL TO SHOW UP IN READS
+	ViewStory(ctx context.Context, storyID string) error
+	UpVoteStory(ctx context.Context, storyID string) error
+	DownVoteStory(ctx context.Context, storyID string) error
+
+	GetStoryTimeSeries(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error)
+	GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error)
 }
 
 //TODO: RENAME (REMOVE DEFAULT)
@@ -26,17 +63,18 @@ type defaultStoriesService struct {
 }
 
 //TODO: REMOVE ERROR NIL CHECK JUST TO INJECT OPERATIONS IN THIS AND ALL THE METHODS BELOW
-func (dss *defaultStoriesService) AddStory(story *model.Story) error {
-	_, err := dss.store.AddStory(story)
+func (dss *defaultStoriesService) AddStory(ctx context.Context, story *model.Story) error {
+	id, err := dss.store.AddStory(ctx, story)
 	if err != nil {
 		return liberr.WithArgs(liberr.Operation("StoryService.AddStory"), err)
 	}
 
+	story.ID = id
 	return nil
 }
 
-func (dss *defaultStoriesService) GetStory(storyID string) (*model.Story, error) {
-	stories, err := dss.store.GetStories(storyID)
+func (dss *defaultStoriesService) GetStory(ctx context.Context, storyID string) (*model.Story, error) {
+	stories, err := dss.store.GetStories(ctx, storyID)
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetStory"), err)
 	}
@@ -44,8 +82,8 @@ func (dss *defaultStoriesService) GetStory(storyID string) (*model.Story, error)
 	return &stories[0], nil
 }
 
-func (dss *defaultStoriesService) UpdateStory(story *model.Story) (int64, error) {
-	c, err := dss.store.UpdateStory(story)
+func (dss *defaultStoriesService) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	c, err := dss.store.UpdateStory(ctx, story, ifUpdatedAt)
 	if err != nil {
 		return 0, liberr.WithArgs(liberr.Operation("StoryService.UpdateStory"), err)
 	}
@@ -53,8 +91,17 @@ func (dss *defaultStoriesService) UpdateStory(story *model.Story) (int64, error)
 	return c, err
 }
 
-func (dss *defaultStoriesService) DeleteStory(storyID string) (int64, error) {
-	c, err := dss.store.DeleteStory(storyID)
+func (dss *defaultStoriesService) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	c, err := dss.store.PatchStory(ctx, patch, ifUpdatedAt)
+	if err != nil {
+		return 0, liberr.WithArgs(liberr.Operation("StoryService.PatchStory"), err)
+	}
+
+	return c, err
+}
+
+func (dss *defaultStoriesService) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	c, err := dss.store.DeleteStory(ctx, storyID, ifUpdatedAt)
 	if err != nil {
 		return 0, liberr.WithArgs(liberr.Operation("StoryService.DeleteStory"), err)
 	}
@@ -63,12 +110,12 @@ func (dss *defaultStoriesService) DeleteStory(storyID string) (int64, error) {
 }
 
 //TODO: FINISH THE IMPLEMENTATION
-func (dss *defaultStoriesService) SearchStories(query string) ([]model.Story, error) {
+func (dss *defaultStoriesService) SearchStories(ctx context.Context, query string) ([]model.Story, error) {
 	return nil, errors.New("UNIMPLEMENTED")
 }
 
-func (dss *defaultStoriesService) GetMostViewsStories(offset, limit int) ([]model.Story, error) {
-	res, err := dss.store.GetMostViewsStories(offset, limit)
+func (dss *defaultStoriesService) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	res, err := dss.store.GetMostViewsStories(ctx, offset, limit)
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetMostViewsStories"), err)
 	}
@@ -76,8 +123,8 @@ func (dss *defaultStoriesService) GetMostViewsStories(offset, limit int) ([]mode
 	return res, nil
 }
 
-func (dss *defaultStoriesService) GetTopRatedStories(offset, limit int) ([]model.Story, error) {
-	res, err := dss.store.GetTopRatedStories(offset, limit)
+func (dss *defaultStoriesService) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	res, err := dss.store.GetTopRatedStories(ctx, offset, limit)
 	if err != nil {
 		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetTopRatedStories"), err)
 	}
@@ -85,6 +132,130 @@ func (dss *defaultStoriesService) GetTopRatedStories(offset, limit int) ([]model
 	return res, nil
 }
 
+func (dss *defaultStoriesService) ViewStory(ctx context.Context, storyID string) error {
+	return dss.incrementCounters(ctx, "StoryService.ViewStory", model.CounterDelta{StoryID: storyID, Views: 1})
+}
+
+func (dss *defaultStoriesService) UpVoteStory(ctx context.Context, storyID string) error {
+	return dss.incrementCounters(ctx, "StoryService.UpVoteStory", model.CounterDelta{StoryID: storyID, UpVotes: 1})
+}
+
+func (dss *defaultStoriesService) DownVoteStory(ctx context.Context, storyID string) error {
+	return dss.incrementCounters(ctx, "StoryService.DownVoteStory", model.CounterDelta{StoryID: storyID, DownVotes: 1})
+}
+
+func (dss *defaultStoriesService) incrementCounters(ctx context.Context, op string, delta model.CounterDelta) error {
+	if err := dss.store.IncrementCounters(ctx, delta); err != nil {
+		return liberr.WithArgs(liberr.Operation(op), err)
+	}
+
+	return nil
+}
+
+func (dss *defaultStoriesService) GetStoryTimeSeries(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	from, to = model.ToDay(from), model.ToDay(to)
+
+	if err := validateRange(fromField, from, to); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetStoryTimeSeries.validateRange"), err)
+	}
+
+	if to.Sub(from) >= maxTimeSeriesDays*24*time.Hour {
+		return nil, liberr.WithArgs(
+			liberr.Operation("StoryService.GetStoryTimeSeries"),
+			invalidParameters(liberr.NewFieldViolation(toField, liberr.CodeInvalidParameter, fmt.Sprintf("range cannot exceed %d days", maxTimeSeriesDays))),
+		)
+	}
+
+	if _, err := dss.store.GetStories(ctx, storyID); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetStoryTimeSeries"), err)
+	}
+
+	stats, err := dss.store.GetDailyStats(ctx, storyID, from, to)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetStoryTimeSeries"), err)
+	}
+
+	return zeroFill(storyID, from, to, stats), nil
+}
+
+func (dss *defaultStoriesService) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	if len(metric) == 0 {
+		metric = defaultMoverMetric
+	}
+
+	var violations []liberr.FieldViolation
+	if !metric.IsValid() {
+		violations = append(violations, liberr.NewFieldViolation(metricField, liberr.CodeInvalidParameter, fmt.Sprintf("invalid metric %s", metric)))
+	}
+
+	if limit <= 0 {
+		violations = append(violations, liberr.NewFieldViolation(limitField, liberr.CodeInvalidParameter, "limit must be positive"))
+	} else if limit > maxTopMovers {
+		violations = append(violations, liberr.NewFieldViolation(limitField, liberr.CodeInvalidParameter, fmt.Sprintf("limit cannot exceed %d", maxTopMovers)))
+	}
+
+	if len(violations) != 0 {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetTopMovers"), invalidParameters(violations...))
+	}
+
+	previousFrom, previousTo = model.ToDay(previousFrom), model.ToDay(previousTo)
+	currentFrom, currentTo = model.ToDay(currentFrom), model.ToDay(currentTo)
+
+	if err := validateRange(previousFromField, previousFrom, previousTo); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetTopMovers.validateRange"), err)
+	}
+
+	if err := validateRange(currentFromField, currentFrom, currentTo); err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetTopMovers.validateRange"), err)
+	}
+
+	res, err := dss.store.GetTopMovers(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+	if err != nil {
+		return nil, liberr.WithArgs(liberr.Operation("StoryService.GetTopMovers"), err)
+	}
+
+	return res, nil
+}
+
+// validateRange NAMES field, THE START OF THE RANGE, WHEN IT IS AFTER THE END
+func validateRange(field string, from, to time.Time) error {
+	if from.After(to) {
+		return invalidParameters(liberr.NewFieldViolation(field, liberr.CodeInvalidParameter, fmt.Sprintf("from %s is after to %s", from.Format(dayLayout), to.Format(dayLayout))))
+	}
+
+	return nil
+}
+
+func invalidParameters(violations ...liberr.FieldViolation) error {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Message
+	}
+
+	return liberr.WithArgs(liberr.ValidationError, liberr.SeverityError, liberr.CodeInvalidParameter, violations, errors.New(strings.Join(messages, "; ")))
+}
+
+// RETURNS ONE ENTRY PER DAY IN [FROM, TO], DAYS WITHOUT ACTIVITY ARE REPORTED AS ZERO
+func zeroFill(storyID string, from, to time.Time, stats []model.DailyStats) []model.DailyStats {
+	byDay := make(map[string]model.DailyStats, len(stats))
+	for _, ds := range stats {
+		byDay[ds.GetDay().Format(dayLayout)] = ds
+	}
+
+	var res []model.DailyStats
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		ds, ok := byDay[day.Format(dayLayout)]
+		if !ok {
+			ds = model.DailyStats{StoryID: storyID}
+		}
+
+		ds.Day = day
+		res = append(res, ds)
+	}
+
+	return res
+}
+
 func NewStoriesService(store store.StoriesStore) StoryService {
 	return &defaultStoriesService{
 		store: store,
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"github.com/nsnikhil/stories/pkg/liberr"
 	"github.com/nsnikhil/stories/pkg/store"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestStoryServiceAddStory(t *testing.T) {
@@ -20,7 +22,7 @@ func TestStoryServiceAddStory(t *testing.T) {
 		"test add story success": {
 			store: func() store.StoriesStore {
 				mst := &store.MockStoriesStore{}
-				mst.On("AddStory", mock.AnythingOfType("*model.Story")).Return("a45c9dac-56dc-4771-a3f4-f10ad30a20a5", nil)
+				mst.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return("a45c9dac-56dc-4771-a3f4-f10ad30a20a5", nil)
 
 				return mst
 			},
@@ -29,7 +31,7 @@ func TestStoryServiceAddStory(t *testing.T) {
 		"test add story failure when dependency fails": {
 			store: func() store.StoriesStore {
 				mst := &store.MockStoriesStore{}
-				mst.On("AddStory", mock.AnythingOfType("*model.Story")).Return("", liberr.WithArgs(liberr.SeverityError, errors.New("failed to insert story")))
+				mst.On("AddStory", mock.Anything, mock.AnythingOfType("*model.Story")).Return("", liberr.WithArgs(liberr.SeverityError, errors.New("failed to insert story")))
 
 				return mst
 			},
@@ -48,12 +50,13 @@ func TestStoryServiceAddStory(t *testing.T) {
 
 			require.NoError(t, err)
 
-			err = svc.AddStory(str)
+			err = svc.AddStory(context.Background(), str)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
 			} else {
 				assert.Nil(t, err)
+				assert.Equal(t, "a45c9dac-56dc-4771-a3f4-f10ad30a20a5", str.GetID())
 			}
 		})
 	}
@@ -78,7 +81,7 @@ func TestStoryServiceGetStory(t *testing.T) {
 				str.ID = id
 
 				mst := &store.MockStoriesStore{}
-				mst.On("GetStories", []string{id}).Return([]model.Story{*str}, nil)
+				mst.On("GetStories", mock.Anything, []string{id}).Return([]model.Story{*str}, nil)
 
 				return mst, id
 			},
@@ -99,7 +102,7 @@ func TestStoryServiceGetStory(t *testing.T) {
 				id := "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
 
 				mst := &store.MockStoriesStore{}
-				mst.On("GetStories", []string{id}).Return([]model.Story{}, liberr.WithArgs(errors.New("failed to get story")))
+				mst.On("GetStories", mock.Anything, []string{id}).Return([]model.Story{}, liberr.WithArgs(errors.New("failed to get story")))
 
 				return mst, id
 			},
@@ -116,7 +119,7 @@ func TestStoryServiceGetStory(t *testing.T) {
 
 			svc := service.NewStoriesService(st)
 
-			res, err := svc.GetStory(id)
+			res, err := svc.GetStory(context.Background(), id)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
@@ -146,7 +149,7 @@ func TestStoryServiceUpdateStory(t *testing.T) {
 				str.ID = "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
 
 				mst := &store.MockStoriesStore{}
-				mst.On("UpdateStory", str).Return(int64(1), nil)
+				mst.On("UpdateStory", mock.Anything, str, time.Time{}).Return(int64(1), nil)
 
 				return str, mst
 			},
@@ -163,7 +166,7 @@ func TestStoryServiceUpdateStory(t *testing.T) {
 				str.ID = "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
 
 				mst := &store.MockStoriesStore{}
-				mst.On("UpdateStory", str).Return(int64(0), liberr.WithArgs(errors.New("failed to update story")))
+				mst.On("UpdateStory", mock.Anything, str, time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to update story")))
 
 				return str, mst
 			},
@@ -178,7 +181,54 @@ func TestStoryServiceUpdateStory(t *testing.T) {
 
 			svc := service.NewStoriesService(str)
 
-			res, err := svc.UpdateStory(st)
+			res, err := svc.UpdateStory(context.Background(), st, time.Time{})
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, testCase.expectedCount, res)
+		})
+	}
+}
+
+func TestStoryServicePatchStory(t *testing.T) {
+	title := "new title"
+	patch := model.StoryPatch{ID: "2eaa0697-2572-47f9-bcff-0bdf0c7c6432", Title: &title}
+
+	testCases := map[string]struct {
+		input         func() store.StoriesStore
+		expectedCount int64
+		expectedError error
+	}{
+		"test patch story success": {
+			input: func() store.StoriesStore {
+				mst := &store.MockStoriesStore{}
+				mst.On("PatchStory", mock.Anything, patch, time.Time{}).Return(int64(1), nil)
+
+				return mst
+			},
+			expectedCount: 1,
+		},
+		"test patch story failure": {
+			input: func() store.StoriesStore {
+				mst := &store.MockStoriesStore{}
+				mst.On("PatchStory", mock.Anything, patch, time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to update story")))
+
+				return mst
+			},
+			expectedCount: 0,
+			expectedError: errors.New("failed to update story"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewStoriesService(testCase.input())
+
+			res, err := svc.PatchStory(context.Background(), patch, time.Time{})
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
@@ -209,7 +259,7 @@ func TestStoryServiceDeleteStory(t *testing.T) {
 				str.ID = id
 
 				mst := &store.MockStoriesStore{}
-				mst.On("DeleteStory", str.GetID()).Return(int64(1), nil)
+				mst.On("DeleteStory", mock.Anything, str.GetID(), time.Time{}).Return(int64(1), nil)
 
 				return id, mst
 			},
@@ -228,7 +278,7 @@ func TestStoryServiceDeleteStory(t *testing.T) {
 				str.ID = id
 
 				mst := &store.MockStoriesStore{}
-				mst.On("DeleteStory", str.GetID()).Return(int64(0), liberr.WithArgs(errors.New("failed to delete story")))
+				mst.On("DeleteStory", mock.Anything, str.GetID(), time.Time{}).Return(int64(0), liberr.WithArgs(errors.New("failed to delete story")))
 
 				return id, mst
 			},
@@ -243,7 +293,7 @@ func TestStoryServiceDeleteStory(t *testing.T) {
 
 			svc := service.NewStoriesService(str)
 
-			res, err := svc.DeleteStory(id)
+			res, err := svc.DeleteStory(context.Background(), id, time.Time{})
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
@@ -277,7 +327,7 @@ func TestStoryServiceGetMostViewsStories(t *testing.T) {
 				str.ID = "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
 
 				mst := &store.MockStoriesStore{}
-				mst.On("GetMostViewsStories", 0, 1).Return([]model.Story{*str}, nil)
+				mst.On("GetMostViewsStories", mock.Anything, 0, 1).Return([]model.Story{*str}, nil)
 
 				return 0, 1, mst
 			},
@@ -296,7 +346,7 @@ func TestStoryServiceGetMostViewsStories(t *testing.T) {
 		"test get most viewed story failure": {
 			input: func() (int, int, store.StoriesStore) {
 				mst := &store.MockStoriesStore{}
-				mst.On("GetMostViewsStories", 0, 1).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get most viewed story")))
+				mst.On("GetMostViewsStories", mock.Anything, 0, 1).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get most viewed story")))
 
 				return 0, 1, mst
 			},
@@ -313,7 +363,7 @@ func TestStoryServiceGetMostViewsStories(t *testing.T) {
 
 			svc := service.NewStoriesService(st)
 
-			res, err := svc.GetMostViewsStories(o, l)
+			res, err := svc.GetMostViewsStories(context.Background(), o, l)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
@@ -343,7 +393,7 @@ func TestStoryServiceGetTopRatedStories(t *testing.T) {
 				str.ID = "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
 
 				mst := &store.MockStoriesStore{}
-				mst.On("GetTopRatedStories", 0, 1).Return([]model.Story{*str}, nil)
+				mst.On("GetTopRatedStories", mock.Anything, 0, 1).Return([]model.Story{*str}, nil)
 
 				return 0, 1, mst
 			},
@@ -362,7 +412,7 @@ func TestStoryServiceGetTopRatedStories(t *testing.T) {
 		"test get top rated story failure": {
 			input: func() (int, int, store.StoriesStore) {
 				mst := &store.MockStoriesStore{}
-				mst.On("GetTopRatedStories", 0, 1).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get top rated story")))
+				mst.On("GetTopRatedStories", mock.Anything, 0, 1).Return([]model.Story{}, liberr.WithArgs(liberr.SeverityError, errors.New("failed to get top rated story")))
 
 				return 0, 1, mst
 			},
@@ -379,7 +429,7 @@ func TestStoryServiceGetTopRatedStories(t *testing.T) {
 
 			svc := service.NewStoriesService(st)
 
-			res, err := svc.GetTopRatedStories(o, l)
+			res, err := svc.GetTopRatedStories(context.Background(), o, l)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError.Error(), err.Error())
@@ -391,3 +441,239 @@ func TestStoryServiceGetTopRatedStories(t *testing.T) {
 		})
 	}
 }
+
+func TestStoryServiceIncrementCounters(t *testing.T) {
+	storyID := "a45c9dac-56dc-4771-a3f4-f10ad30a20a5"
+
+	testCases := map[string]struct {
+		delta         model.CounterDelta
+		call          func(svc service.StoryService) error
+		err           error
+		expectedError error
+	}{
+		"test view story": {
+			delta: model.CounterDelta{StoryID: storyID, Views: 1},
+			call:  func(svc service.StoryService) error { return svc.ViewStory(context.Background(), storyID) },
+		},
+		"test up vote story": {
+			delta: model.CounterDelta{StoryID: storyID, UpVotes: 1},
+			call:  func(svc service.StoryService) error { return svc.UpVoteStory(context.Background(), storyID) },
+		},
+		"test down vote story": {
+			delta: model.CounterDelta{StoryID: storyID, DownVotes: 1},
+			call:  func(svc service.StoryService) error { return svc.DownVoteStory(context.Background(), storyID) },
+		},
+		"test view story failure when dependency fails": {
+			delta:         model.CounterDelta{StoryID: storyID, Views: 1},
+			call:          func(svc service.StoryService) error { return svc.ViewStory(context.Background(), storyID) },
+			err:           liberr.WithArgs(liberr.SeverityError, errors.New("circuit breaker is open")),
+			expectedError: errors.New("circuit breaker is open"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mst := &store.MockStoriesStore{}
+			mst.On("IncrementCounters", mock.Anything, []model.CounterDelta{testCase.delta}).Return(testCase.err)
+
+			err := testCase.call(service.NewStoriesService(mst))
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			mst.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStoryServiceGetStoryTimeSeries(t *testing.T) {
+	id := "2eaa0697-2572-47f9-bcff-0bdf0c7c6432"
+
+	day := func(d int) time.Time {
+		return time.Date(2020, 9, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := map[string]struct {
+		input              func() (store.StoriesStore, time.Time, time.Time)
+		expectedResult     []model.DailyStats
+		expectedError      error
+		expectedViolations []liberr.FieldViolation
+	}{
+		"test get story time series zero fills missing days": {
+			input: func() (store.StoriesStore, time.Time, time.Time) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetStories", mock.Anything, []string{id}).Return([]model.Story{{ID: id}}, nil)
+				mst.On("GetDailyStats", mock.Anything, id, day(1), day(4)).Return([]model.DailyStats{
+					{StoryID: id, Day: day(2), Views: 10, UpVotes: 2},
+					{StoryID: id, Day: day(4), Views: 5, DownVotes: 1},
+				}, nil)
+
+				return mst, day(1).Add(time.Hour), day(4).Add(time.Hour)
+			},
+			expectedResult: []model.DailyStats{
+				{StoryID: id, Day: day(1)},
+				{StoryID: id, Day: day(2), Views: 10, UpVotes: 2},
+				{StoryID: id, Day: day(3)},
+				{StoryID: id, Day: day(4), Views: 5, DownVotes: 1},
+			},
+		},
+		"test get story time series failure when from is after to": {
+			input: func() (store.StoriesStore, time.Time, time.Time) {
+				return &store.MockStoriesStore{}, day(4), day(1)
+			},
+			expectedError:      errors.New("from 2020-09-04 is after to 2020-09-01"),
+			expectedViolations: []liberr.FieldViolation{liberr.NewFieldViolation("from", liberr.CodeInvalidParameter, "from 2020-09-04 is after to 2020-09-01")},
+		},
+		"test get story time series failure when range is too long": {
+			input: func() (store.StoriesStore, time.Time, time.Time) {
+				return &store.MockStoriesStore{}, day(1), day(1).AddDate(2, 0, 0)
+			},
+			expectedError:      errors.New("range cannot exceed 366 days"),
+			expectedViolations: []liberr.FieldViolation{liberr.NewFieldViolation("to", liberr.CodeInvalidParameter, "range cannot exceed 366 days")},
+		},
+		"test get story time series failure when story does not exist": {
+			input: func() (store.StoriesStore, time.Time, time.Time) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetStories", mock.Anything, []string{id}).Return([]model.Story{}, liberr.WithArgs(errors.New("no records found")))
+
+				return mst, day(1), day(4)
+			},
+			expectedError: errors.New("no records found"),
+		},
+		"test get story time series failure when store fails": {
+			input: func() (store.StoriesStore, time.Time, time.Time) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetStories", mock.Anything, []string{id}).Return([]model.Story{{ID: id}}, nil)
+				mst.On("GetDailyStats", mock.Anything, id, day(1), day(4)).Return([]model.DailyStats{}, liberr.WithArgs(errors.New("failed to get daily stats")))
+
+				return mst, day(1), day(4)
+			},
+			expectedError: errors.New("failed to get daily stats"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			st, from, to := testCase.input()
+
+			svc := service.NewStoriesService(st)
+
+			res, err := svc.GetStoryTimeSeries(context.Background(), id, from, to)
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			if testCase.expectedViolations != nil {
+				assert.Equal(t, liberr.CodeInvalidParameter, err.(*liberr.Error).Code())
+				assert.Equal(t, testCase.expectedViolations, err.(*liberr.Error).FieldViolations())
+			}
+
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
+
+func TestStoryServiceGetTopMovers(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, 9, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	movers := []model.Mover{
+		{StoryID: "2eaa0697-2572-47f9-bcff-0bdf0c7c6432", Previous: 2, Current: 20},
+	}
+
+	testCases := map[string]struct {
+		input              func() (store.StoriesStore, model.Metric, int)
+		expectedResult     []model.Mover
+		expectedError      error
+		expectedViolations []liberr.FieldViolation
+	}{
+		"test get top movers success": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetTopMovers", mock.Anything, model.MetricUpVotes, day(1), day(7), day(8), day(14), 10).Return(movers, nil)
+
+				return mst, model.MetricUpVotes, 10
+			},
+			expectedResult: movers,
+		},
+		"test get top movers defaults to views": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetTopMovers", mock.Anything, model.MetricViews, day(1), day(7), day(8), day(14), 10).Return(movers, nil)
+
+				return mst, "", 10
+			},
+			expectedResult: movers,
+		},
+		"test get top movers failure when metric is invalid": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				return &store.MockStoriesStore{}, "likes", 10
+			},
+			expectedError:      errors.New("invalid metric likes"),
+			expectedViolations: []liberr.FieldViolation{liberr.NewFieldViolation("metric", liberr.CodeInvalidParameter, "invalid metric likes")},
+		},
+		"test get top movers failure when limit is not positive": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				return &store.MockStoriesStore{}, model.MetricViews, 0
+			},
+			expectedError:      errors.New("limit must be positive"),
+			expectedViolations: []liberr.FieldViolation{liberr.NewFieldViolation("limit", liberr.CodeInvalidParameter, "limit must be positive")},
+		},
+		"test get top movers failure when limit is too large": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				return &store.MockStoriesStore{}, model.MetricViews, 101
+			},
+			expectedError:      errors.New("limit cannot exceed 100"),
+			expectedViolations: []liberr.FieldViolation{liberr.NewFieldViolation("limit", liberr.CodeInvalidParameter, "limit cannot exceed 100")},
+		},
+		"test get top movers reports every invalid parameter": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				return &store.MockStoriesStore{}, "likes", 0
+			},
+			expectedError: errors.New("invalid metric likes; limit must be positive"),
+			expectedViolations: []liberr.FieldViolation{
+				liberr.NewFieldViolation("metric", liberr.CodeInvalidParameter, "invalid metric likes"),
+				liberr.NewFieldViolation("limit", liberr.CodeInvalidParameter, "limit must be positive"),
+			},
+		},
+		"test get top movers failure when store fails": {
+			input: func() (store.StoriesStore, model.Metric, int) {
+				mst := &store.MockStoriesStore{}
+				mst.On("GetTopMovers", mock.Anything, model.MetricViews, day(1), day(7), day(8), day(14), 10).Return([]model.Mover{}, liberr.WithArgs(errors.New("failed to get top movers")))
+
+				return mst, model.MetricViews, 10
+			},
+			expectedError: errors.New("failed to get top movers"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			st, metric, limit := testCase.input()
+
+			svc := service.NewStoriesService(st)
+
+			res, err := svc.GetTopMovers(context.Background(), metric, day(1), day(7), day(8), day(14), limit)
+
+			if testCase.expectedError != nil {
+				assert.Equal(t, testCase.expectedError.Error(), err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+
+			if testCase.expectedViolations != nil {
+				assert.Equal(t, liberr.CodeInvalidParameter, err.(*liberr.Error).Code())
+				assert.Equal(t, testCase.expectedViolations, err.(*liberr.Error).FieldViolations())
+			}
+
+			assert.Equal(t, testCase.expectedResult, res)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/liberr"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"time"
+)
+
+// timeoutStoriesService BOUNDS EVERY CALL WITH THE TIMEOUT CONFIGURED FOR ITS OPERATION, A DEADLINE
+// ALREADY SET BY THE CALLER IS KEPT WHEN IT IS THE EARLIER ONE.
+type timeoutStoriesService struct {
+	svc StoryService
+	cfg config.TimeoutConfig
+}
+
+func (tss *timeoutStoriesService) AddStory(ctx context.Context, story *model.Story) error {
+	ctx, cancel := withTimeout(ctx, tss.cfg.AddStory())
+	defer cancel()
+
+	return contextError(ctx, "StoryService.AddStory", tss.svc.AddStory(ctx, story))
+}
+
+func (tss *timeoutStoriesService) GetStory(ctx context.Context, storyID string) (*model.Story, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.GetStory())
+	defer cancel()
+
+	st, err := tss.svc.GetStory(ctx, storyID)
+	return st, contextError(ctx, "StoryService.GetStory", err)
+}
+
+func (tss *timeoutStoriesService) UpdateStory(ctx context.Context, story *model.Story, ifUpdatedAt time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.UpdateStory())
+	defer cancel()
+
+	c, err := tss.svc.UpdateStory(ctx, story, ifUpdatedAt)
+	return c, contextError(ctx, "StoryService.UpdateStory", err)
+}
+
+func (tss *timeoutStoriesService) PatchStory(ctx context.Context, patch model.StoryPatch, ifUpdatedAt time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.UpdateStory())
+	defer cancel()
+
+	c, err := tss.svc.PatchStory(ctx, patch, ifUpdatedAt)
+	return c, contextError(ctx, "StoryService.PatchStory", err)
+}
+
+func (tss *timeoutStoriesService) DeleteStory(ctx context.Context, storyID string, ifUpdatedAt time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.DeleteStory())
+	defer cancel()
+
+	c, err := tss.svc.DeleteStory(ctx, storyID, ifUpdatedAt)
+	return c, contextError(ctx, "StoryService.DeleteStory", err)
+}
+
+func (tss *timeoutStoriesService) SearchStories(ctx context.Context, query string) ([]model.Story, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.SearchStories())
+	defer cancel()
+
+	res, err := tss.svc.SearchStories(ctx, query)
+	return res, contextError(ctx, "StoryService.SearchStories", err)
+}
+
+func (tss *timeoutStoriesService) GetMostViewsStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.ListStories())
+	defer cancel()
+
+	res, err := tss.svc.GetMostViewsStories(ctx, offset, limit)
+	return res, contextError(ctx, "StoryService.GetMostViewsStories", err)
+}
+
+func (tss *timeoutStoriesService) GetTopRatedStories(ctx context.Context, offset, limit int) ([]model.Story, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.ListStories())
+	defer cancel()
+
+	res, err := tss.svc.GetTopRatedStories(ctx, offset, limit)
+	return res, contextError(ctx, "StoryService.GetTopRatedStories", err)
+}
+
+func (tss *timeoutStoriesService) ViewStory(ctx context.Context, storyID string) error {
+	ctx, cancel := withTimeout(ctx, tss.cfg.UpdateStory())
+	defer cancel()
+
+	return contextError(ctx, "StoryService.ViewStory", tss.svc.ViewStory(ctx, storyID))
+}
+
+func (tss *timeoutStoriesService) UpVoteStory(ctx context.Context, storyID string) error {
+	ctx, cancel := withTimeout(ctx, tss.cfg.UpdateStory())
+	defer cancel()
+
+	return contextError(ctx, "StoryService.UpVoteStory", tss.svc.UpVoteStory(ctx, storyID))
+}
+
+func (tss *timeoutStoriesService) DownVoteStory(ctx context.Context, storyID string) error {
+	ctx, cancel := withTimeout(ctx, tss.cfg.UpdateStory())
+	defer cancel()
+
+	return contextError(ctx, "StoryService.DownVoteStory", tss.svc.DownVoteStory(ctx, storyID))
+}
+
+func (tss *timeoutStoriesService) GetStoryTimeSeries(ctx context.Context, storyID string, from, to time.Time) ([]model.DailyStats, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.Analytics())
+	defer cancel()
+
+	res, err := tss.svc.GetStoryTimeSeries(ctx, storyID, from, to)
+	return res, contextError(ctx, "StoryService.GetStoryTimeSeries", err)
+}
+
+func (tss *timeoutStoriesService) GetTopMovers(ctx context.Context, metric model.Metric, previousFrom, previousTo, currentFrom, currentTo time.Time, limit int) ([]model.Mover, error) {
+	ctx, cancel := withTimeout(ctx, tss.cfg.Analytics())
+	defer cancel()
+
+	res, err := tss.svc.GetTopMovers(ctx, metric, previousFrom, previousTo, currentFrom, currentTo, limit)
+	return res, contextError(ctx, "StoryService.GetTopMovers", err)
+}
+
+// A ZERO TIMEOUT LEAVES THE CONTEXT AS IT IS
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// THE DRIVER MAY REPORT A CANCELED QUERY WITH ITS OWN ERROR, MAKE SURE THE CONTEXT ERROR IS IN THE CHAIN
+// SO THE TRANSPORTS CAN MAP IT TO THE RIGHT STATUS
+func contextError(ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	ctxErr := ctx.Err()
+	if ctxErr == nil || errors.Is(err, ctxErr) {
+		return err
+	}
+
+	return liberr.WithArgs(liberr.Operation(op), liberr.SeverityError, fmt.Errorf("%w: %s", ctxErr, err.Error()))
+}
+
+func NewTimeoutStoriesService(svc StoryService, cfg config.TimeoutConfig) StoryService {
+	return &timeoutStoriesService{
+		svc: svc,
+		cfg: cfg,
+	}
+}
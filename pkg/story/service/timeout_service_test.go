@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/nsnikhil/stories/pkg/config"
+	"github.com/nsnikhil/stories/pkg/story/model"
+	"github.com/nsnikhil/stories/pkg/story/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTimeoutService(svc service.StoryService) service.StoryService {
+	cfg := config.NewConfig("../../../local.env").TimeoutConfig()
+	return service.NewTimeoutStoriesService(svc, cfg)
+}
+
+func TestTimeoutStoryServiceSetsDeadline(t *testing.T) {
+	cfg := config.NewConfig("../../../local.env").TimeoutConfig()
+
+	mss := &service.MockStoriesService{}
+	mss.On("GetStory", mock.Anything, cachedStoryID).Run(func(args mock.Arguments) {
+		deadline, ok := args.Get(0).(context.Context).Deadline()
+		require.True(t, ok)
+		assert.True(t, time.Until(deadline) <= cfg.GetStory())
+	}).Return(&model.Story{ID: cachedStoryID}, nil)
+
+	res, err := newTimeoutService(mss).GetStory(context.Background(), cachedStoryID)
+	require.NoError(t, err)
+	assert.Equal(t, cachedStoryID, res.GetID())
+
+	mss.AssertExpectations(t)
+}
+
+func TestTimeoutStoryServiceKeepsEarlierDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	expected, _ := ctx.Deadline()
+
+	mss := &service.MockStoriesService{}
+	mss.On("GetMostViewsStories", mock.Anything, 0, 10).Run(func(args mock.Arguments) {
+		deadline, ok := args.Get(0).(context.Context).Deadline()
+		require.True(t, ok)
+		assert.Equal(t, expected, deadline)
+	}).Return([]model.Story{}, nil)
+
+	_, err := newTimeoutService(mss).GetMostViewsStories(ctx, 0, 10)
+	require.NoError(t, err)
+
+	mss.AssertExpectations(t)
+}
+
+func TestTimeoutStoryServiceWrapsContextError(t *testing.T) {
+	testCases := map[string]struct {
+		ctx           func() (context.Context, context.CancelFunc)
+		err           error
+		expectedError error
+	}{
+		"test driver error on canceled context carries the cancellation": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			err:           errors.New("driver: bad connection"),
+			expectedError: context.Canceled,
+		},
+		"test driver error on expired context carries the deadline": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			err:           errors.New("driver: bad connection"),
+			expectedError: context.DeadlineExceeded,
+		},
+		"test context error is returned as is": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			err:           context.Canceled,
+			expectedError: context.Canceled,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := testCase.ctx()
+			defer cancel()
+
+			mss := &service.MockStoriesService{}
+			mss.On("DeleteStory", mock.Anything, cachedStoryID, time.Time{}).Return(int64(0), testCase.err)
+
+			_, err := newTimeoutService(mss).DeleteStory(ctx, cachedStoryID, time.Time{})
+			assert.True(t, errors.Is(err, testCase.expectedError))
+		})
+	}
+}
+
+func TestTimeoutStoryServiceLeavesOtherErrors(t *testing.T) {
+	mss := &service.MockStoriesService{}
+	mss.On("AddStory", mock.Anything, mock.Anything).Return(errors.New("some error"))
+
+	err := newTimeoutService(mss).AddStory(context.Background(), &model.Story{})
+	assert.Equal(t, "some error", err.Error())
+}